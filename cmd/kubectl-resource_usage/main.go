@@ -1,7 +1,11 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cmd"
 	"github.com/spf13/pflag"
@@ -18,8 +22,19 @@ func main() {
 		ErrOut: os.Stderr,
 	}
 
+	// Cancel on the first interrupt so watch, serve and the alert actions can
+	// finish cleanly; a second interrupt kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+
 	root := cmd.NewCmdResourceUsage(streams)
-	if err := root.Execute(); err != nil {
-		os.Exit(1)
+	err := root.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		var exitErr *cmd.ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(cmd.ExitError)
 	}
 }
@@ -0,0 +1,223 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxBannerMessages is how many recent alert messages a Banner keeps
+const maxBannerMessages = 5
+
+// CommandAction runs a shell command for each event. The event is passed as
+// JSON on stdin and as ALERT_* environment variables.
+type CommandAction struct {
+	Command string
+}
+
+// Fire runs the command and waits for it to finish
+func (a *CommandAction) Fire(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert event: %w", err)
+	}
+
+	value := ""
+	if event.Value != nil {
+		value = strconv.FormatFloat(*event.Value, 'f', -1, 64)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", a.Command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"ALERT_RULE="+event.Rule,
+		"ALERT_STATE="+string(event.State),
+		"ALERT_CLUSTER="+event.Cluster,
+		"ALERT_NAMESPACE="+event.Namespace,
+		"ALERT_POD="+event.Pod,
+		"ALERT_NODE="+event.Node,
+		"ALERT_VALUE="+value,
+		"ALERT_THRESHOLD="+strconv.FormatFloat(event.Threshold, 'f', -1, 64),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("alert command failed: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// AsyncAction fires events through another action in the background, one at
+// a time and in order, each with its own timeout, so a slow command or
+// webhook cannot stall the evaluation that produced the events
+type AsyncAction struct {
+	action  Action
+	timeout time.Duration
+	queue   chan Event
+	done    chan struct{}
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// asyncQueueSize is how many events an AsyncAction buffers before dropping new ones
+const asyncQueueSize = 100
+
+// NewAsyncAction starts delivering events to action in the background
+func NewAsyncAction(action Action, timeout time.Duration) *AsyncAction {
+	a := &AsyncAction{
+		action:  action,
+		timeout: timeout,
+		queue:   make(chan Event, asyncQueueSize),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Fire queues the event and returns at once; delivery failures are reported by Errors
+func (a *AsyncAction) Fire(_ context.Context, event Event) error {
+	select {
+	case a.queue <- event:
+		return nil
+	default:
+		return fmt.Errorf("alert queue full, dropped %s event of %s/%s", event.State, event.Namespace, event.Pod)
+	}
+}
+
+// Errors returns the delivery failures since the last call
+func (a *AsyncAction) Errors() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	err := errors.Join(a.errs...)
+	a.errs = nil
+	return err
+}
+
+// Close stops accepting events and waits for the queued ones to be delivered
+func (a *AsyncAction) Close() {
+	close(a.queue)
+	<-a.done
+}
+
+// run delivers queued events until the queue is closed
+func (a *AsyncAction) run() {
+	defer close(a.done)
+	for event := range a.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+		err := a.action.Fire(ctx, event)
+		cancel()
+		if err != nil {
+			a.mu.Lock()
+			a.errs = append(a.errs, err)
+			a.mu.Unlock()
+		}
+	}
+}
+
+// WebhookAction POSTs each event as JSON to a URL
+type WebhookAction struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookAction creates a WebhookAction with a bounded request timeout
+func NewWebhookAction(url string) *WebhookAction {
+	return &WebhookAction{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Fire posts the event and fails on non-2xx responses
+func (a *WebhookAction) Fire(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alert webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Banner shows events as attention-grabbing messages. If Out is set each
+// message is written there with a terminal bell; the most recent messages
+// are also kept for display in the watch header.
+type Banner struct {
+	Out io.Writer
+
+	mu       sync.Mutex
+	messages []string
+}
+
+// Fire records the event and writes it to Out
+func (b *Banner) Fire(_ context.Context, event Event) error {
+	msg := BannerMessage(event)
+
+	b.mu.Lock()
+	b.messages = append(b.messages, msg)
+	if len(b.messages) > maxBannerMessages {
+		b.messages = b.messages[len(b.messages)-maxBannerMessages:]
+	}
+	b.mu.Unlock()
+
+	if b.Out == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(b.Out, "\a*** %s ***\n", msg)
+	return err
+}
+
+// Messages returns the most recent banner messages, oldest first
+func (b *Banner) Messages() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.messages...)
+}
+
+// BannerMessage formats an event as a one-line message
+func BannerMessage(event Event) string {
+	label := "ALERT"
+	if event.State == StateResolved {
+		label = "RESOLVED"
+	}
+	pod := event.Namespace + "/" + event.Pod
+	if event.Cluster != "" {
+		pod = event.Cluster + "/" + pod
+	}
+	return fmt.Sprintf("%s %s %s: %s (value %s)",
+		event.Timestamp.Format("15:04:05"), label, pod, event.Rule, formatValue(event.Value))
+}
+
+// formatValue formats an event value as a percentage, or N/A if it is missing
+func formatValue(p *float64) string {
+	if p == nil {
+		return "N/A"
+	}
+	return FormatPercent(*p)
+}
+
+// FormatPercent formats a percentage for messages, rounded to two decimals
+func FormatPercent(p float64) string {
+	return strconv.FormatFloat(math.Round(p*100)/100, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,180 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// State is the alert state reported in an Event
+type State string
+
+const (
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// Event describes a pod entering or leaving the alert state of a rule
+type Event struct {
+	Rule      string    `json:"rule"`
+	State     State     `json:"state"`
+	Cluster   string    `json:"cluster,omitempty"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Node      string    `json:"node"`
+	Value     *float64  `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Since     time.Time `json:"since"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Action is notified of every alert event
+type Action interface {
+	Fire(ctx context.Context, event Event) error
+}
+
+// podState tracks one pod's progress through one rule across evaluations
+type podState struct {
+	rule         Rule
+	pendingSince time.Time // when the condition started holding; zero if it does not hold
+	clearSince   time.Time // when the condition stopped holding while firing; zero otherwise
+	firing       bool
+	firingSince  time.Time
+	missingSince time.Time // when the pod was first missing from the samples; zero if it is present
+	missing      int       // consecutive samples the pod was missing from
+	last         calculator.PodUsage
+}
+
+// missingSamples is how many consecutive samples a pod must be missing from
+// before its state is dropped, so a single metrics-server gap doesn't resolve
+// a firing alert only to fire it again on the next sample
+const missingSamples = 2
+
+// Manager evaluates rules against successive samples, debounces them and
+// fires actions when a pod enters or leaves the alert state
+type Manager struct {
+	rules    []Rule
+	actions  []Action
+	cooldown time.Duration
+	states   map[string]*podState
+}
+
+// NewManager creates a Manager. A firing alert resolves only after its
+// condition has been false for cooldown, so flapping pods do not spam actions.
+func NewManager(rules []Rule, actions []Action, cooldown time.Duration) *Manager {
+	return &Manager{
+		rules:    rules,
+		actions:  actions,
+		cooldown: cooldown,
+		states:   map[string]*podState{},
+	}
+}
+
+// Evaluate checks every rule against the sample taken at now and fires
+// actions for state changes. Firing alerts of pods missing from the samples
+// for the cooldown and at least missingSamples samples resolve, except for
+// pods of the unavailable clusters, which could not be sampled and keep
+// their state until their cluster answers again.
+func (m *Manager) Evaluate(ctx context.Context, podUsages []calculator.PodUsage, now time.Time, unavailable ...string) error {
+	var events []Event
+	seen := map[string]bool{}
+	skipped := map[string]bool{}
+	for _, cluster := range unavailable {
+		skipped[cluster] = true
+	}
+
+	for _, rule := range m.rules {
+		for _, pu := range podUsages {
+			key := rule.String() + "/" + pu.Key()
+			seen[key] = true
+
+			st, ok := m.states[key]
+			if !ok {
+				st = &podState{rule: rule}
+				m.states[key] = st
+			}
+			st.last = pu
+			st.missingSince, st.missing = time.Time{}, 0
+
+			if event, changed := m.step(rule, st, rule.Matches(pu), now); changed {
+				events = append(events, event)
+			}
+		}
+	}
+
+	// Pods that disappeared resolve once they stayed away like a cleared condition
+	for key, st := range m.states {
+		if seen[key] || skipped[st.last.Cluster] {
+			continue
+		}
+		if st.missingSince.IsZero() {
+			st.missingSince = now
+		}
+		st.missing++
+		if st.missing < missingSamples || now.Sub(st.missingSince) < m.cooldown {
+			continue
+		}
+		if st.firing {
+			events = append(events, newEvent(st.rule, StateResolved, st.last, st.firingSince, now))
+		}
+		delete(m.states, key)
+	}
+
+	var errs []error
+	for _, event := range events {
+		for _, action := range m.actions {
+			if err := action.Fire(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// step advances a pod's state for one rule and returns the event to fire, if any
+func (m *Manager) step(rule Rule, st *podState, matches bool, now time.Time) (Event, bool) {
+	if matches {
+		st.clearSince = time.Time{}
+		if st.pendingSince.IsZero() {
+			st.pendingSince = now
+		}
+		if !st.firing && now.Sub(st.pendingSince) >= rule.For {
+			st.firing = true
+			st.firingSince = now
+			return newEvent(rule, StateFiring, st.last, st.pendingSince, now), true
+		}
+		return Event{}, false
+	}
+
+	st.pendingSince = time.Time{}
+	if !st.firing {
+		return Event{}, false
+	}
+	if st.clearSince.IsZero() {
+		st.clearSince = now
+	}
+	if now.Sub(st.clearSince) >= m.cooldown {
+		st.firing = false
+		st.clearSince = time.Time{}
+		return newEvent(rule, StateResolved, st.last, st.firingSince, now), true
+	}
+	return Event{}, false
+}
+
+// newEvent builds the event for a pod's state change
+func newEvent(rule Rule, state State, pu calculator.PodUsage, since, now time.Time) Event {
+	return Event{
+		Rule:      rule.String(),
+		State:     state,
+		Cluster:   pu.Cluster,
+		Namespace: pu.Namespace,
+		Pod:       pu.Name,
+		Node:      pu.Node,
+		Value:     rule.Value(pu),
+		Threshold: rule.Threshold,
+		Since:     since,
+		Timestamp: now,
+	}
+}
@@ -0,0 +1,312 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Rule
+		wantErr string
+	}{
+		{
+			input: "memory.limitPercent >= 90 for 2m",
+			want:  Rule{Resource: "memory", Metric: "limitPercent", Operator: ">=", Threshold: 90, For: 2 * time.Minute},
+		},
+		{
+			input: "cpu.requestPercent < 10%",
+			want:  Rule{Resource: "cpu", Metric: "requestPercent", Operator: "<", Threshold: 10},
+		},
+		{
+			input: "ephemeral-storage.limitPercent > 80",
+			want:  Rule{Resource: "ephemeral-storage", Metric: "limitPercent", Operator: ">", Threshold: 80},
+		},
+		{
+			input: "cpu.requestPercent < 0.5",
+			want:  Rule{Resource: "cpu", Metric: "requestPercent", Operator: "<", Threshold: 0.5},
+		},
+		{input: "disk.limitPercent > 10", wantErr: "invalid rule field"},
+		{input: "cpu.usage > 10", wantErr: "invalid rule field: cpu.usage (metric must be requestPercent or limitPercent)"},
+		{input: "cpu.limitPercent => 10", wantErr: "invalid rule operator"},
+		{input: "cpu.limitPercent > high", wantErr: "invalid rule threshold"},
+		{input: "cpu.limitPercent > NaN", wantErr: "invalid rule threshold"},
+		{input: "cpu.limitPercent > 10 during 2m", wantErr: "expected 'for <duration>'"},
+		{input: "cpu.limitPercent > 10 for soon", wantErr: "invalid rule duration"},
+		{input: "cpu.limitPercent", wantErr: "invalid rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRule(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	rule, err := ParseRule("memory.limitPercent >= 90 for 2m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := rule.String(), "memory.limitPercent >= 90 for 2m0s"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// recordingAction collects the events it is fired with
+type recordingAction struct {
+	events []Event
+}
+
+func (a *recordingAction) Fire(_ context.Context, event Event) error {
+	a.events = append(a.events, event)
+	return nil
+}
+
+func TestManagerDebounce(t *testing.T) {
+	rule, _ := ParseRule("memory.limitPercent >= 90 for 2m")
+	action := &recordingAction{}
+	m := NewManager([]Rule{rule}, []Action{action}, time.Minute)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		offset  time.Duration
+		percent float64
+		want    []State
+	}{
+		{0, 95, nil},           // condition starts holding
+		{time.Minute, 95, nil}, // still pending
+		{2 * time.Minute, 95, []State{StateFiring}},   // held for 2m: fires
+		{3 * time.Minute, 50, nil},                    // clearing, cooldown not elapsed
+		{3*time.Minute + 30*time.Second, 95, nil},     // back above: stays firing, no new event
+		{4 * time.Minute, 50, nil},                    // clearing again
+		{5 * time.Minute, 50, []State{StateResolved}}, // clear for the cooldown: resolves
+		{6 * time.Minute, 50, nil},
+	}
+
+	for _, step := range steps {
+		before := len(action.events)
+		if err := m.Evaluate(context.Background(), []calculator.PodUsage{testPod(step.percent)}, start.Add(step.offset)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var got []State
+		for _, e := range action.events[before:] {
+			got = append(got, e.State)
+		}
+		if len(got) != len(step.want) || (len(got) > 0 && got[0] != step.want[0]) {
+			t.Errorf("at +%s: expected events %v, got %v", step.offset, step.want, got)
+		}
+	}
+}
+
+func TestManagerResolvesDisappearedPods(t *testing.T) {
+	rule, _ := ParseRule("memory.limitPercent > 80")
+	action := &recordingAction{}
+	m := NewManager([]Rule{rule}, []Action{action}, time.Minute)
+
+	now := time.Now()
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{testPod(90)}, now)
+	_ = m.Evaluate(context.Background(), nil, now.Add(time.Second))
+	if len(action.events) != 1 {
+		t.Fatalf("expected api to keep firing while missing for less than the cooldown, got %+v", action.events)
+	}
+	_ = m.Evaluate(context.Background(), nil, now.Add(time.Minute+time.Second))
+
+	if len(action.events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(action.events))
+	}
+	if action.events[1].State != StateResolved || action.events[1].Pod != "api" {
+		t.Errorf("expected api to resolve, got %+v", action.events[1])
+	}
+}
+
+func TestManagerIgnoresSingleMissingSample(t *testing.T) {
+	rule, _ := ParseRule("memory.limitPercent > 80")
+	action := &recordingAction{}
+	m := NewManager([]Rule{rule}, []Action{action}, 0)
+
+	now := time.Now()
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{testPod(90)}, now)
+	// One gap in the metrics, then the pod is back
+	_ = m.Evaluate(context.Background(), nil, now.Add(time.Second))
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{testPod(90)}, now.Add(2*time.Second))
+
+	if len(action.events) != 1 || action.events[0].State != StateFiring {
+		t.Errorf("expected a single firing event across the gap, got %+v", action.events)
+	}
+}
+
+func TestManagerKeepsUnavailableClusters(t *testing.T) {
+	rule, _ := ParseRule("memory.limitPercent > 80")
+	action := &recordingAction{}
+	m := NewManager([]Rule{rule}, []Action{action}, time.Minute)
+
+	prod, staging := testPod(90), testPod(90)
+	prod.Cluster, staging.Cluster = "prod", "staging"
+
+	now := time.Now()
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{prod, staging}, now)
+	// staging fails to answer: its pod is not in the sample but must not resolve
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{prod}, now.Add(time.Second), "staging")
+	_ = m.Evaluate(context.Background(), []calculator.PodUsage{prod, staging}, now.Add(2*time.Second))
+
+	if len(action.events) != 2 {
+		t.Fatalf("expected only the 2 firing events, got %+v", action.events)
+	}
+	for _, e := range action.events {
+		if e.State != StateFiring {
+			t.Errorf("expected no resolve while staging was unavailable, got %+v", e)
+		}
+	}
+}
+
+// blockingAction blocks each Fire until release is closed or ctx ends
+type blockingAction struct {
+	release chan struct{}
+	fired   int
+}
+
+func (a *blockingAction) Fire(ctx context.Context, _ Event) error {
+	a.fired++
+	select {
+	case <-a.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestAsyncAction(t *testing.T) {
+	// Delivery blocks until release, so Fire returning at all shows it does not wait
+	slow := &blockingAction{release: make(chan struct{})}
+	a := NewAsyncAction(slow, time.Hour)
+	for i := 0; i < 2; i++ {
+		if err := a.Fire(context.Background(), Event{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	close(slow.release)
+	a.Close()
+	if slow.fired != 2 {
+		t.Errorf("expected both events delivered, got %d", slow.fired)
+	}
+	if err := a.Errors(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAsyncActionTimeout(t *testing.T) {
+	a := NewAsyncAction(&blockingAction{release: make(chan struct{})}, 10*time.Millisecond)
+	_ = a.Fire(context.Background(), Event{})
+	a.Close()
+
+	if err := a.Errors(); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("expected timed out delivery, got %v", err)
+	}
+	if err := a.Errors(); err != nil {
+		t.Errorf("expected errors to be cleared once reported, got %v", err)
+	}
+}
+
+func TestWebhookAction(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	value := 93.0
+	event := Event{Rule: "memory.limitPercent >= 90", State: StateFiring, Namespace: "default", Pod: "api", Value: &value, Threshold: 90}
+	if err := NewWebhookAction(server.URL).Fire(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.Pod != "api" || received.State != StateFiring || received.Value == nil || *received.Value != 93 {
+		t.Errorf("unexpected payload: %+v", received)
+	}
+}
+
+func TestWebhookActionErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookAction(server.URL).Fire(context.Background(), Event{})
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected 500 error, got %v", err)
+	}
+}
+
+func TestCommandAction(t *testing.T) {
+	value := 93.0
+	event := Event{Rule: "memory.limitPercent >= 90", State: StateFiring, Namespace: "default", Pod: "api", Value: &value}
+
+	ok := &CommandAction{Command: `test "$ALERT_POD" = api && test "$ALERT_VALUE" = 93 && grep -q '"state":"firing"'`}
+	if err := ok.Fire(context.Background(), event); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	failing := &CommandAction{Command: "echo boom; exit 3"}
+	if err := failing.Fire(context.Background(), event); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected command failure with output, got %v", err)
+	}
+}
+
+func TestBannerMessage(t *testing.T) {
+	value := 100.0 / 3
+	event := Event{Rule: "cpu.requestPercent < 40", State: StateFiring, Namespace: "default", Pod: "api", Value: &value, Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	if got, want := BannerMessage(event), "12:00:00 ALERT default/api: cpu.requestPercent < 40 (value 33.33%)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestBanner(t *testing.T) {
+	var out strings.Builder
+	banner := &Banner{Out: &out}
+
+	for i := 0; i < maxBannerMessages+2; i++ {
+		_ = banner.Fire(context.Background(), Event{Rule: "cpu.limitPercent > 80", State: StateResolved, Namespace: "default", Pod: "api"})
+	}
+
+	if got := len(banner.Messages()); got != maxBannerMessages {
+		t.Errorf("expected %d messages, got %d", maxBannerMessages, got)
+	}
+	if !strings.Contains(out.String(), "\a*** ") || !strings.Contains(out.String(), "RESOLVED default/api") {
+		t.Errorf("unexpected banner output: %q", out.String())
+	}
+}
+
+func testPod(memLimitPercent float64) calculator.PodUsage {
+	return calculator.PodUsage{
+		Namespace: "default",
+		Name:      "api",
+		Resources: calculator.ResourceUsages{corev1.ResourceMemory: {LimitPercent: &memLimitPercent}},
+	}
+}
@@ -0,0 +1,115 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// operators are the supported comparison operators, longest first so ">=" is matched before ">"
+var operators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Rule is a threshold condition on a pod percentage, e.g. "memory.limitPercent >= 90 for 2m"
+type Rule struct {
+	Resource  string        // a registered resource, e.g. "cpu" or "memory"
+	Metric    string        // "requestPercent" or "limitPercent"
+	Operator  string        // one of >=, >, <=, <, ==, !=
+	Threshold float64       // percentage compared against
+	For       time.Duration // how long the condition must hold before the alert fires
+}
+
+// ParseRule parses a rule of the form "<resource>.<metric> <op> <threshold> [for <duration>]"
+func ParseRule(s string) (Rule, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 && len(fields) != 5 {
+		return Rule{}, fmt.Errorf("invalid rule: %q (must be '<resource>.<metric> <op> <threshold> [for <duration>]')", s)
+	}
+
+	field, err := output.ParseField(fields[0])
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid rule field: %w", err)
+	}
+	rule := Rule{Resource: string(field.Resource), Metric: field.Metric}
+
+	if !isOperator(fields[1]) {
+		return Rule{}, fmt.Errorf("invalid rule operator: %s (must be one of %s)", fields[1], strings.Join(operators, ", "))
+	}
+	rule.Operator = fields[1]
+
+	threshold, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "%"), 64)
+	if err != nil || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+		return Rule{}, fmt.Errorf("invalid rule threshold: %s (must be a percentage like 90 or 0.5)", fields[2])
+	}
+	rule.Threshold = threshold
+
+	if len(fields) == 5 {
+		if fields[3] != "for" {
+			return Rule{}, fmt.Errorf("invalid rule: %q (expected 'for <duration>' after the threshold)", s)
+		}
+		d, err := time.ParseDuration(fields[4])
+		if err != nil || d < 0 {
+			return Rule{}, fmt.Errorf("invalid rule duration: %s (must be a non-negative duration like 2m)", fields[4])
+		}
+		rule.For = d
+	}
+
+	return rule, nil
+}
+
+// String returns the rule in the syntax accepted by ParseRule
+func (r Rule) String() string {
+	s := fmt.Sprintf("%s.%s %s %s", r.Resource, r.Metric, r.Operator, strconv.FormatFloat(r.Threshold, 'f', -1, 64))
+	if r.For > 0 {
+		s += " for " + r.For.String()
+	}
+	return s
+}
+
+// Value returns the percentage the rule looks at, or nil if it is not available
+func (r Rule) Value(pu calculator.PodUsage) *float64 {
+	usage := pu.Resources[corev1.ResourceName(r.Resource)]
+	if r.Metric == "requestPercent" {
+		return usage.RequestPercent
+	}
+	return usage.LimitPercent
+}
+
+// Matches reports whether the pod currently meets the rule's condition.
+// Pods without the percentage never match.
+func (r Rule) Matches(pu calculator.PodUsage) bool {
+	p := r.Value(pu)
+	if p == nil {
+		return false
+	}
+
+	switch r.Operator {
+	case ">=":
+		return *p >= r.Threshold
+	case ">":
+		return *p > r.Threshold
+	case "<=":
+		return *p <= r.Threshold
+	case "<":
+		return *p < r.Threshold
+	case "==":
+		return *p == r.Threshold
+	default:
+		return *p != r.Threshold
+	}
+}
+
+// isOperator checks if op is a supported comparison operator
+func isOperator(op string) bool {
+	for _, valid := range operators {
+		if op == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Severity is how urgently a finding should be fixed
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// severityRank orders severities from most to least urgent
+var severityRank = map[Severity]int{SeverityError: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// Rule describes one configuration check
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+	Remediation string
+}
+
+// Audit rules checked by Audit
+var (
+	RuleMissingRequests = Rule{
+		ID:          "RU001",
+		Severity:    SeverityWarning,
+		Description: "Container has no resource requests",
+		Remediation: "Set resources.requests so the scheduler can place the pod and usage percentages can be calculated.",
+	}
+	RuleMissingLimits = Rule{
+		ID:          "RU002",
+		Severity:    SeverityWarning,
+		Description: "Container has no resource limits",
+		Remediation: "Set resources.limits (at least for memory) so a runaway container cannot starve its node.",
+	}
+	RuleLimitBelowRequest = Rule{
+		ID:          "RU003",
+		Severity:    SeverityError,
+		Description: "Container limit is lower than its request",
+		Remediation: "Raise the limit to at least the request, or lower the request.",
+	}
+	RuleMemoryLimitRatio = Rule{
+		ID:          "RU004",
+		Severity:    SeverityWarning,
+		Description: "Memory limit is far above the memory request",
+		Remediation: "Bring the memory request closer to the limit; overcommitted memory is reclaimed by OOM kills, not throttling.",
+	}
+	RuleCPULimitLatencySensitive = Rule{
+		ID:          "RU005",
+		Severity:    SeverityWarning,
+		Description: "CPU limit set in a latency-sensitive namespace",
+		Remediation: "Remove the CPU limit and rely on CPU requests; CFS throttling adds tail latency.",
+	}
+	RuleBestEffortProduction = Rule{
+		ID:          "RU006",
+		Severity:    SeverityError,
+		Description: "BestEffort pod in a production namespace",
+		Remediation: "Set requests (and memory limits) so the pod is Burstable or Guaranteed and is not evicted first.",
+	}
+	RuleRequestExceedsAllocatable = Rule{
+		ID:          "RU007",
+		Severity:    SeverityError,
+		Description: "Pod requests more than any node can allocate",
+		Remediation: "Lower the pod's requests or add a node pool large enough to schedule it.",
+	}
+)
+
+// Rules returns all audit rules
+func Rules() []Rule {
+	return []Rule{
+		RuleMissingRequests,
+		RuleMissingLimits,
+		RuleLimitBelowRequest,
+		RuleMemoryLimitRatio,
+		RuleCPULimitLatencySensitive,
+		RuleBestEffortProduction,
+		RuleRequestExceedsAllocatable,
+	}
+}
+
+// Options configures the audit
+type Options struct {
+	MaxMemoryLimitRatio        float64  // memory limit/request ratio above which RU004 fires; 0 disables
+	LatencySensitiveNamespaces []string // namespaces where CPU limits are reported (RU005)
+	ProductionNamespaces       []string // namespaces where BestEffort pods are reported (RU006)
+}
+
+// Finding is a rule violated by a pod or container
+type Finding struct {
+	RuleID      string   `json:"ruleID" yaml:"ruleID"`
+	Severity    Severity `json:"severity" yaml:"severity"`
+	Namespace   string   `json:"namespace" yaml:"namespace"`
+	Pod         string   `json:"pod" yaml:"pod"`
+	Container   string   `json:"container,omitempty" yaml:"container,omitempty"`
+	Message     string   `json:"message" yaml:"message"`
+	Remediation string   `json:"remediation" yaml:"remediation"`
+}
+
+// Audit checks every pod and container against the audit rules. Nodes are
+// only used for RU007 and may be nil if they could not be listed.
+func Audit(pods []corev1.Pod, nodes []corev1.Node, opts Options) []Finding {
+	latencySensitive := toSet(opts.LatencySensitiveNamespaces)
+	production := toSet(opts.ProductionNamespaces)
+	largest := largestAllocatable(nodes)
+
+	var findings []Finding
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			add := func(rule Rule, format string, args ...interface{}) {
+				findings = append(findings, newFinding(rule, pod, c.Name, fmt.Sprintf(format, args...)))
+			}
+
+			if missing := missingResources(c.Resources.Requests); len(missing) > 0 {
+				add(RuleMissingRequests, "no %s requests", joinResources(missing))
+			}
+			if missing := missingResources(c.Resources.Limits); len(missing) > 0 {
+				add(RuleMissingLimits, "no %s limits", joinResources(missing))
+			}
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				req, hasReq := c.Resources.Requests[name]
+				lim, hasLim := c.Resources.Limits[name]
+				if hasReq && hasLim && lim.Cmp(req) < 0 {
+					add(RuleLimitBelowRequest, "%s limit %s is lower than request %s", name, lim.String(), req.String())
+				}
+			}
+			if opts.MaxMemoryLimitRatio > 0 {
+				req, hasReq := c.Resources.Requests[corev1.ResourceMemory]
+				lim, hasLim := c.Resources.Limits[corev1.ResourceMemory]
+				if hasReq && hasLim && !req.IsZero() {
+					ratio := float64(lim.Value()) / float64(req.Value())
+					if ratio > opts.MaxMemoryLimitRatio {
+						add(RuleMemoryLimitRatio, "memory limit/request ratio is %.1f (max %.1f)", ratio, opts.MaxMemoryLimitRatio)
+					}
+				}
+			}
+			if lim, ok := c.Resources.Limits[corev1.ResourceCPU]; ok && latencySensitive[pod.Namespace] {
+				add(RuleCPULimitLatencySensitive, "cpu limit %s in latency-sensitive namespace %s", lim.String(), pod.Namespace)
+			}
+		}
+
+		if production[pod.Namespace] && calculator.QOSClass(pod) == corev1.PodQOSBestEffort {
+			findings = append(findings, newFinding(RuleBestEffortProduction, pod, "",
+				fmt.Sprintf("BestEffort QoS in production namespace %s", pod.Namespace)))
+		}
+
+		if largest != nil {
+			requests := calculator.EffectivePodRequests(pod)
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				req, hasReq := requests[name]
+				max, hasMax := largest[name]
+				if hasReq && hasMax && req.Cmp(max) > 0 {
+					findings = append(findings, newFinding(RuleRequestExceedsAllocatable, pod, "",
+						fmt.Sprintf("%s requests %s exceed the largest node allocatable %s", name, req.String(), max.String())))
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+	return findings
+}
+
+// newFinding builds a finding for a rule
+func newFinding(rule Rule, pod corev1.Pod, container, message string) Finding {
+	return Finding{
+		RuleID:      rule.ID,
+		Severity:    rule.Severity,
+		Namespace:   pod.Namespace,
+		Pod:         pod.Name,
+		Container:   container,
+		Message:     message,
+		Remediation: rule.Remediation,
+	}
+}
+
+// missingResources returns which of cpu and memory are not set in list
+func missingResources(list corev1.ResourceList) []corev1.ResourceName {
+	var missing []corev1.ResourceName
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if _, ok := list[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// joinResources formats resource names as "cpu" or "cpu/memory"
+func joinResources(names []corev1.ResourceName) string {
+	s := ""
+	for i, name := range names {
+		if i > 0 {
+			s += "/"
+		}
+		s += string(name)
+	}
+	return s
+}
+
+// largestAllocatable returns the largest allocatable cpu and memory of any node, or nil without nodes
+func largestAllocatable(nodes []corev1.Node) map[corev1.ResourceName]resource.Quantity {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	largest := map[corev1.ResourceName]resource.Quantity{}
+	for _, node := range nodes {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			q, ok := node.Status.Allocatable[name]
+			if !ok {
+				continue
+			}
+			if max, seen := largest[name]; !seen || q.Cmp(max) > 0 {
+				largest[name] = q
+			}
+		}
+	}
+	return largest
+}
+
+// toSet converts a list of names into a lookup set
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
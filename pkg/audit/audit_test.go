@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name      string
+		pod       corev1.Pod
+		opts      Options
+		wantRules []string
+	}{
+		{
+			name:      "fully configured container",
+			pod:       testPod("default", resources("100m", "128Mi", "200m", "256Mi")),
+			opts:      Options{MaxMemoryLimitRatio: 2},
+			wantRules: nil,
+		},
+		{
+			name:      "missing requests and limits",
+			pod:       testPod("default", corev1.ResourceRequirements{}),
+			wantRules: []string{"RU001", "RU002"},
+		},
+		{
+			name:      "limit below request",
+			pod:       testPod("default", resources("500m", "128Mi", "200m", "256Mi")),
+			wantRules: []string{"RU003"},
+		},
+		{
+			name:      "memory ratio above maximum",
+			pod:       testPod("default", resources("100m", "128Mi", "200m", "1Gi")),
+			opts:      Options{MaxMemoryLimitRatio: 2},
+			wantRules: []string{"RU004"},
+		},
+		{
+			name:      "cpu limit in latency-sensitive namespace",
+			pod:       testPod("checkout", resources("100m", "128Mi", "200m", "256Mi")),
+			opts:      Options{LatencySensitiveNamespaces: []string{"checkout"}},
+			wantRules: []string{"RU005"},
+		},
+		{
+			name:      "besteffort in production",
+			pod:       testPod("prod", corev1.ResourceRequirements{}),
+			opts:      Options{ProductionNamespaces: []string{"prod"}},
+			wantRules: []string{"RU006", "RU001", "RU002"},
+		},
+		{
+			name:      "requests exceed every node",
+			pod:       testPod("default", resources("8", "128Mi", "8", "256Mi")),
+			wantRules: []string{"RU007"},
+		},
+	}
+
+	nodes := []corev1.Node{testNode("2", "8Gi"), testNode("4", "16Gi")}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Audit([]corev1.Pod{tt.pod}, nodes, tt.opts)
+
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.RuleID)
+				if f.Remediation == "" {
+					t.Errorf("expected remediation for %s", f.RuleID)
+				}
+			}
+			if len(got) != len(tt.wantRules) {
+				t.Fatalf("expected rules %v, got %v", tt.wantRules, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantRules[i] {
+					t.Errorf("expected rules %v, got %v", tt.wantRules, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestAuditWithoutNodes(t *testing.T) {
+	pod := testPod("default", resources("64", "128Mi", "64", "256Mi"))
+	if findings := Audit([]corev1.Pod{pod}, nil, Options{}); len(findings) != 0 {
+		t.Errorf("expected no findings without nodes, got %v", findings)
+	}
+}
+
+func TestRulesHaveUniqueIDs(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rule := range Rules() {
+		if seen[rule.ID] {
+			t.Errorf("duplicate rule ID %s", rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+}
+
+func testPod(namespace string, res corev1.ResourceRequirements) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: namespace},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Resources: res}},
+		},
+	}
+}
+
+func resources(cpuReq, memReq, cpuLim, memLim string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuReq),
+			corev1.ResourceMemory: resource.MustParse(memReq),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuLim),
+			corev1.ResourceMemory: resource.MustParse(memLim),
+		},
+	}
+}
+
+func testNode(cpu, memory string) corev1.Node {
+	return corev1.Node{
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package calculator
+
+import (
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HoursPerMonth is the number of hours hourly rates are projected over (365 * 24 / 12)
+const HoursPerMonth = 730
+
+// bytesPerGiB converts memory quantities to the unit memory is priced in
+const bytesPerGiB = 1 << 30
+
+// Sort fields accepted by SortPodUsages for pods with an estimated cost
+const (
+	SortByCost      = "cost"       // monthly cost of the requests
+	SortByUsageCost = "usage-cost" // monthly cost of the actual usage
+	SortByIdleCost  = "idle-cost"  // monthly cost of requests left unused
+)
+
+// CostSortFields returns the sort fields that order by estimated cost
+func CostSortFields() []string {
+	return []string{SortByCost, SortByUsageCost, SortByIdleCost}
+}
+
+// Rates are the hourly prices of a CPU core and a GiB of memory
+type Rates struct {
+	CPUCoreHour   float64
+	MemoryGiBHour float64
+}
+
+// Cost is the estimated monthly cost of a pod or a group of pods
+type Cost struct {
+	Requests float64 // cost of the requested cpu and memory
+	Usage    float64 // cost of the cpu and memory actually used
+	Idle     float64 // cost of the requested cpu and memory left unused
+}
+
+// Add accumulates another cost
+func (c *Cost) Add(other Cost) {
+	c.Requests += other.Requests
+	c.Usage += other.Usage
+	c.Idle += other.Idle
+}
+
+// EstimateCost estimates the monthly cost of a pod's cpu and memory at the
+// given rates. Idle cost is counted per resource, so a pod using more CPU
+// than it requests does not offset the memory it leaves unused. Usage that
+// was not reported counts as zero.
+func EstimateCost(pu PodUsage, rates Rates) Cost {
+	var cost Cost
+	for _, r := range []struct {
+		name corev1.ResourceName
+		rate float64
+		unit float64
+	}{
+		{corev1.ResourceCPU, rates.CPUCoreHour, 1},
+		{corev1.ResourceMemory, rates.MemoryGiBHour, bytesPerGiB},
+	} {
+		ru := pu.Resources[r.name]
+		perMonth := r.rate * HoursPerMonth / r.unit
+
+		var requests, usage float64
+		if ru.Requests != nil {
+			requests = ru.Requests.AsApproximateFloat64() * perMonth
+		}
+		if !ru.UsageUnavailable {
+			usage = ru.Usage.AsApproximateFloat64() * perMonth
+		}
+		cost.Add(Cost{Requests: requests, Usage: usage, Idle: math.Max(requests-usage, 0)})
+	}
+	return cost
+}
+
+// costKey converts a pod's cost into a sort key in hundredths, false if it has none
+func costKey(pod PodUsage, field string) (int64, bool) {
+	if pod.Cost == nil {
+		return 0, false
+	}
+	value := pod.Cost.Requests
+	switch field {
+	case SortByUsageCost:
+		value = pod.Cost.Usage
+	case SortByIdleCost:
+		value = pod.Cost.Idle
+	}
+	return int64(math.Round(value * 100)), true
+}
+
+// SortGroupsByCost sorts groups by one of CostSortFields, descending unless
+// ascending is set. Groups without a cost go to the end; ties keep name order.
+func SortGroupsByCost(groups []GroupUsage, field string, ascending bool) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		vi, oki := costKey(PodUsage{Cost: groups[i].Cost}, field)
+		vj, okj := costKey(PodUsage{Cost: groups[j].Cost}, field)
+		if !oki || !okj {
+			return oki && !okj
+		}
+		if ascending {
+			return vi < vj
+		}
+		return vi > vj
+	})
+}
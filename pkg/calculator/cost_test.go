@@ -0,0 +1,91 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestEstimateCost(t *testing.T) {
+	rates := Rates{CPUCoreHour: 0.04, MemoryGiBHour: 0.005}
+	pod := PodUsage{Resources: ResourceUsages{
+		// 2 cores requested, 500m used: 1.5 cores idle
+		corev1.ResourceCPU: {Usage: resource.MustParse("500m"), Requests: resourcePtr(resource.MustParse("2"))},
+		// 1Gi requested, 2Gi used: over-use doesn't offset the idle CPU
+		corev1.ResourceMemory: {Usage: resource.MustParse("2Gi"), Requests: resourcePtr(resource.MustParse("1Gi"))},
+	}}
+
+	got := EstimateCost(pod, rates)
+	want := Cost{
+		Requests: (2*0.04 + 1*0.005) * HoursPerMonth,
+		Usage:    (0.5*0.04 + 2*0.005) * HoursPerMonth,
+		Idle:     1.5 * 0.04 * HoursPerMonth,
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"requests", got.Requests, want.Requests},
+		{"usage", got.Usage, want.Usage},
+		{"idle", got.Idle, want.Idle},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s cost = %.4f, want %.4f", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestEstimateCostUnavailableUsage(t *testing.T) {
+	pod := PodUsage{Resources: ResourceUsages{
+		corev1.ResourceCPU: {Requests: resourcePtr(resource.MustParse("1")), UsageUnavailable: true},
+	}}
+
+	got := EstimateCost(pod, Rates{CPUCoreHour: 0.1})
+	if got.Usage != 0 || math.Abs(got.Idle-got.Requests) > 1e-9 || math.Abs(got.Requests-73) > 1e-9 {
+		t.Errorf("expected the whole request to be idle, got %+v", got)
+	}
+}
+
+func TestSortPodUsagesByCost(t *testing.T) {
+	pods := []PodUsage{
+		{Name: "cheap", Cost: &Cost{Requests: 10, Idle: 1}},
+		{Name: "unpriced"},
+		{Name: "wasteful", Cost: &Cost{Requests: 5, Idle: 4}},
+	}
+
+	SortPodUsages(pods, SortByIdleCost, false)
+	if pods[0].Name != "wasteful" || pods[1].Name != "cheap" || pods[2].Name != "unpriced" {
+		t.Errorf("idle cost sort failed: %v", []string{pods[0].Name, pods[1].Name, pods[2].Name})
+	}
+
+	SortPodUsages(pods, SortByCost, false)
+	if pods[0].Name != "cheap" || pods[2].Name != "unpriced" {
+		t.Errorf("cost sort failed: %v", []string{pods[0].Name, pods[1].Name, pods[2].Name})
+	}
+}
+
+func TestRollupCost(t *testing.T) {
+	pods := []PodUsage{
+		{Namespace: "a", Workload: "Deployment/api", Cost: &Cost{Requests: 10, Usage: 4, Idle: 6}},
+		{Namespace: "a", Workload: "Deployment/api", Cost: &Cost{Requests: 10, Usage: 8, Idle: 2}},
+		{Namespace: "b", Workload: "Deployment/api"},
+	}
+
+	groups := RollupByWorkload(pods)
+	if len(groups) != 2 || groups[0].Name != "a/Deployment/api" || groups[1].Name != "b/Deployment/api" {
+		t.Fatalf("expected workloads per namespace, got %+v", groups)
+	}
+	if c := groups[0].Cost; c == nil || c.Requests != 20 || c.Usage != 12 || c.Idle != 8 {
+		t.Errorf("expected summed cost, got %+v", c)
+	}
+	if groups[1].Cost != nil {
+		t.Errorf("expected no cost for unpriced pods, got %+v", groups[1].Cost)
+	}
+
+	SortGroupsByCost(groups, SortByIdleCost, true)
+	if groups[0].Name != "a/Deployment/api" {
+		t.Errorf("expected groups without a cost last, got %s first", groups[0].Name)
+	}
+}
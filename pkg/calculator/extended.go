@@ -0,0 +1,51 @@
+package calculator
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsExtendedResource reports whether a resource is reported as an extended
+// resource: anything but cpu, memory and ephemeral storage, such as
+// nvidia.com/gpu, hugepages-2Mi or other device plugin resources
+func IsExtendedResource(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage,
+		corev1.ResourceStorage, corev1.ResourcePods:
+		return false
+	}
+	return true
+}
+
+// IsHugePagesResource reports whether a resource is a hugepages size
+func IsHugePagesResource(name corev1.ResourceName) bool {
+	return strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
+
+// SortedResourceNames returns the keys of a resource map in name order
+func SortedResourceNames[T any](resources map[corev1.ResourceName]T) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// extendedResourceNames returns the extended resources requested or limited
+// by the containers. No usage source covers them, so their usage is unavailable.
+func extendedResourceNames(containers ...corev1.Container) []corev1.ResourceName {
+	seen := make(map[corev1.ResourceName]bool)
+	for _, c := range containers {
+		for _, list := range []corev1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+			for name := range list {
+				if IsExtendedResource(name) {
+					seen[name] = true
+				}
+			}
+		}
+	}
+	return SortedResourceNames(seen)
+}
@@ -0,0 +1,71 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+const resourceGPU corev1.ResourceName = "nvidia.com/gpu"
+
+func TestIsExtendedResource(t *testing.T) {
+	tests := []struct {
+		name corev1.ResourceName
+		want bool
+	}{
+		{corev1.ResourceCPU, false},
+		{corev1.ResourceMemory, false},
+		{corev1.ResourceEphemeralStorage, false},
+		{corev1.ResourcePods, false},
+		{resourceGPU, true},
+		{"hugepages-2Mi", true},
+		{"example.com/fpga", true},
+	}
+	for _, tt := range tests {
+		if got := IsExtendedResource(tt.name); got != tt.want {
+			t.Errorf("IsExtendedResource(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCalculatePodUsageExtended(t *testing.T) {
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "trainer", Namespace: "ml"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "train", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+		},
+	}
+	gpus := corev1.ResourceList{
+		resourceGPU:     resource.MustParse("2"),
+		"hugepages-2Mi": resource.MustParse("1Gi"),
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "trainer", Namespace: "ml"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "train", Resources: corev1.ResourceRequirements{Requests: gpus, Limits: gpus}},
+				{Name: "logger"},
+			},
+		},
+	}
+
+	pu := CalculatePodUsage(podMetric, pod)
+	if want := len(Resources()) + 2; len(pu.Resources) != want {
+		t.Fatalf("expected %d resources, got %d", want, len(pu.Resources))
+	}
+	gpu := pu.Resources[resourceGPU]
+	if gpu.Requests == nil || gpu.Requests.Value() != 2 || gpu.Limits == nil || gpu.Limits.Value() != 2 {
+		t.Errorf("expected 2 GPUs requested and limited, got %+v", gpu)
+	}
+	if !gpu.UsageUnavailable || gpu.RequestPercent != nil {
+		t.Errorf("expected GPU usage to be unavailable, got %+v", gpu)
+	}
+
+	plain := CalculatePodUsage(podMetric, corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "train"}}}})
+	if _, ok := plain.Resources[resourceGPU]; ok || len(plain.Resources) != len(Resources()) {
+		t.Errorf("expected only registered resources, got %v", plain.Resources)
+	}
+}
@@ -1,11 +1,20 @@
 package calculator
 
+import "strings"
+
 // FilterOptions contains options for filtering pod usages
 type FilterOptions struct {
 	Above    int    // Filter pods with usage >= Above%, -1 means not set
 	Below    int    // Filter pods with usage <= Below%, -1 means not set
 	NoLimits bool   // Filter pods without limits set
-	Field    string // Field to filter by: "cpu" or "memory"
+	Field    string // Registered resource or SortByThrottled to filter by, e.g. "cpu"; memory if unknown
+
+	QOSClass    string // Filter pods in this QoS class, empty means any
+	Phase       string // Filter pods in this phase, empty means any
+	MinRestarts int32  // Filter pods restarted at least this many times
+	OOMKilled   bool   // Filter pods with a container whose last termination was an OOM kill
+
+	ExcludeNamespaces []string // Drop pods in these namespaces
 }
 
 // NewFilterOptions creates a FilterOptions with default values
@@ -19,7 +28,7 @@ func NewFilterOptions() FilterOptions {
 
 // FilterPodUsages filters pod usages based on the provided options
 func FilterPodUsages(pods []PodUsage, opts FilterOptions) []PodUsage {
-	if opts.Above == -1 && opts.Below == -1 && !opts.NoLimits {
+	if opts.Above == -1 && opts.Below == -1 && !opts.NoLimits && !opts.hasStatusFilter() {
 		return pods
 	}
 
@@ -32,22 +41,51 @@ func FilterPodUsages(pods []PodUsage, opts FilterOptions) []PodUsage {
 	return result
 }
 
+// hasStatusFilter reports whether any pod status or namespace filter is set
+func (opts FilterOptions) hasStatusFilter() bool {
+	return opts.QOSClass != "" || opts.Phase != "" || opts.MinRestarts > 0 || opts.OOMKilled || len(opts.ExcludeNamespaces) > 0
+}
+
+// matchesStatus checks if a pod matches the pod status and namespace filter criteria
+func matchesStatus(pod PodUsage, opts FilterOptions) bool {
+	for _, ns := range opts.ExcludeNamespaces {
+		if pod.Namespace == ns {
+			return false
+		}
+	}
+	if opts.QOSClass != "" && !strings.EqualFold(string(pod.Status.QOSClass), opts.QOSClass) {
+		return false
+	}
+	if opts.Phase != "" && !strings.EqualFold(string(pod.Status.Phase), opts.Phase) {
+		return false
+	}
+	if pod.Status.Restarts < opts.MinRestarts {
+		return false
+	}
+	if opts.OOMKilled && pod.Status.LastOOMKill == nil {
+		return false
+	}
+	return true
+}
+
 // matchesFilter checks if a pod matches the filter criteria
 func matchesFilter(pod PodUsage, opts FilterOptions) bool {
+	if !matchesStatus(pod, opts) {
+		return false
+	}
+	if opts.Above == -1 && opts.Below == -1 && !opts.NoLimits {
+		return true
+	}
+
 	// Handle --no-limits filter
 	if opts.NoLimits {
-		if pod.CPU.Limits == nil || pod.Memory.Limits == nil {
-			return true
-		}
-		return false
+		return missingLimits(pod)
 	}
 
 	// Get the percentage based on the field
-	var percent *int
-	if opts.Field == "cpu" {
-		percent = pod.CPU.LimitPercent
-	} else {
-		percent = pod.Memory.LimitPercent
+	percent := pod.Resources[fieldResource(opts.Field)].LimitPercent
+	if opts.Field == SortByThrottled {
+		percent = pod.throttledPercent()
 	}
 
 	// If no limit percentage is available, exclude from filter results
@@ -56,14 +94,24 @@ func matchesFilter(pod PodUsage, opts FilterOptions) bool {
 	}
 
 	// Check above threshold
-	if opts.Above != -1 && *percent < opts.Above {
+	if opts.Above != -1 && *percent < float64(opts.Above) {
 		return false
 	}
 
 	// Check below threshold
-	if opts.Below != -1 && *percent > opts.Below {
+	if opts.Below != -1 && *percent > float64(opts.Below) {
 		return false
 	}
 
 	return true
 }
+
+// missingLimits reports whether a pod has no limit for a resource that requires one
+func missingLimits(pod PodUsage) bool {
+	for _, def := range registry {
+		if def.RequireLimits && pod.Resources[def.Name].Limits == nil {
+			return true
+		}
+	}
+	return false
+}
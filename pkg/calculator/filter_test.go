@@ -3,6 +3,7 @@ package calculator
 import (
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -13,10 +14,10 @@ func quantityPtr(s string) *resource.Quantity {
 
 func TestFilterPodUsages_Above(t *testing.T) {
 	pods := []PodUsage{
-		{Name: "pod1", Memory: ResourceUsage{LimitPercent: intPtr(90)}},
-		{Name: "pod2", Memory: ResourceUsage{LimitPercent: intPtr(50)}},
-		{Name: "pod3", Memory: ResourceUsage{LimitPercent: intPtr(80)}},
-		{Name: "pod4", Memory: ResourceUsage{LimitPercent: nil}},
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(50)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(80)}}},
+		{Name: "pod4", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: nil}}},
 	}
 
 	opts := FilterOptions{Above: 80, Below: -1, Field: "memory"}
@@ -36,9 +37,9 @@ func TestFilterPodUsages_Above(t *testing.T) {
 
 func TestFilterPodUsages_Below(t *testing.T) {
 	pods := []PodUsage{
-		{Name: "pod1", Memory: ResourceUsage{LimitPercent: intPtr(90)}},
-		{Name: "pod2", Memory: ResourceUsage{LimitPercent: intPtr(30)}},
-		{Name: "pod3", Memory: ResourceUsage{LimitPercent: intPtr(50)}},
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(30)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(50)}}},
 	}
 
 	opts := FilterOptions{Above: -1, Below: 50, Field: "memory"}
@@ -60,23 +61,31 @@ func TestFilterPodUsages_NoLimits(t *testing.T) {
 	pods := []PodUsage{
 		{
 			Name: "pod1",
-			CPU:  ResourceUsage{Limits: quantityPtr("100m")},
-			Memory: ResourceUsage{Limits: quantityPtr("128Mi")},
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Limits: quantityPtr("100m")},
+				corev1.ResourceMemory: {Limits: quantityPtr("128Mi")},
+			},
 		},
 		{
 			Name: "pod2",
-			CPU:  ResourceUsage{Limits: nil},
-			Memory: ResourceUsage{Limits: quantityPtr("128Mi")},
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Limits: nil},
+				corev1.ResourceMemory: {Limits: quantityPtr("128Mi")},
+			},
 		},
 		{
 			Name: "pod3",
-			CPU:  ResourceUsage{Limits: quantityPtr("100m")},
-			Memory: ResourceUsage{Limits: nil},
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Limits: quantityPtr("100m")},
+				corev1.ResourceMemory: {Limits: nil},
+			},
 		},
 		{
 			Name: "pod4",
-			CPU:  ResourceUsage{Limits: nil},
-			Memory: ResourceUsage{Limits: nil},
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Limits: nil},
+				corev1.ResourceMemory: {Limits: nil},
+			},
 		},
 	}
 
@@ -97,9 +106,9 @@ func TestFilterPodUsages_NoLimits(t *testing.T) {
 
 func TestFilterPodUsages_CPUField(t *testing.T) {
 	pods := []PodUsage{
-		{Name: "pod1", CPU: ResourceUsage{LimitPercent: intPtr(90)}, Memory: ResourceUsage{LimitPercent: intPtr(30)}},
-		{Name: "pod2", CPU: ResourceUsage{LimitPercent: intPtr(50)}, Memory: ResourceUsage{LimitPercent: intPtr(90)}},
-		{Name: "pod3", CPU: ResourceUsage{LimitPercent: intPtr(80)}, Memory: ResourceUsage{LimitPercent: intPtr(10)}},
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(90)}, corev1.ResourceMemory: {LimitPercent: floatPtr(30)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(50)}, corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(80)}, corev1.ResourceMemory: {LimitPercent: floatPtr(10)}}},
 	}
 
 	opts := FilterOptions{Above: 80, Below: -1, Field: "cpu"}
@@ -119,10 +128,10 @@ func TestFilterPodUsages_CPUField(t *testing.T) {
 
 func TestFilterPodUsages_AboveAndBelow(t *testing.T) {
 	pods := []PodUsage{
-		{Name: "pod1", Memory: ResourceUsage{LimitPercent: intPtr(90)}},
-		{Name: "pod2", Memory: ResourceUsage{LimitPercent: intPtr(30)}},
-		{Name: "pod3", Memory: ResourceUsage{LimitPercent: intPtr(60)}},
-		{Name: "pod4", Memory: ResourceUsage{LimitPercent: intPtr(70)}},
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(30)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(60)}}},
+		{Name: "pod4", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(70)}}},
 	}
 
 	opts := FilterOptions{Above: 50, Below: 80, Field: "memory"}
@@ -154,6 +163,27 @@ func TestFilterPodUsages_NoFilter(t *testing.T) {
 	}
 }
 
+func TestFilterPodUsages_ExcludeNamespaces(t *testing.T) {
+	pods := []PodUsage{
+		{Namespace: "payment", Name: "api", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
+		{Namespace: "kube-system", Name: "coredns", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(95)}}},
+		{Namespace: "monitoring", Name: "prometheus", Resources: ResourceUsages{corev1.ResourceMemory: {LimitPercent: floatPtr(20)}}},
+	}
+
+	opts := FilterOptions{Above: -1, Below: -1, Field: "memory", ExcludeNamespaces: []string{"kube-system", "monitoring"}}
+	result := FilterPodUsages(pods, opts)
+	if len(result) != 1 || result[0].Name != "api" {
+		t.Errorf("expected only api, got %+v", result)
+	}
+
+	opts.Above = 50
+	opts.ExcludeNamespaces = []string{"payment"}
+	result = FilterPodUsages(pods, opts)
+	if len(result) != 1 || result[0].Name != "coredns" {
+		t.Errorf("expected only coredns, got %+v", result)
+	}
+}
+
 func TestNewFilterOptions(t *testing.T) {
 	opts := NewFilterOptions()
 
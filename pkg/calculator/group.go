@@ -0,0 +1,111 @@
+package calculator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnsetGroup names the group of pods that have no value for the label or
+// annotation they are grouped by
+const UnsetGroup = "<none>"
+
+// Fields pods can be grouped by. Label and annotation groupings take a key,
+// as in "label:team".
+const (
+	GroupByNamespace  = "namespace"
+	GroupByWorkload   = "workload"
+	GroupByPod        = "pod"
+	GroupByLabel      = "label"
+	GroupByAnnotation = "annotation"
+)
+
+// GroupBy selects how pods are grouped in a rollup
+type GroupBy struct {
+	Field string // one of the GroupBy constants
+	Key   string // label or annotation key, empty for the other fields
+}
+
+// NamespaceMetadata holds the labels and annotations of a namespace, which
+// label and annotation groupings fall back to when a pod has no value
+type NamespaceMetadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// GroupByFields returns the accepted group-by values
+func GroupByFields() []string {
+	return []string{GroupByNamespace, GroupByWorkload, GroupByPod, GroupByLabel + ":<key>", GroupByAnnotation + ":<key>"}
+}
+
+// ParseGroupBy parses a group-by value such as "workload" or "label:team"
+func ParseGroupBy(s string) (GroupBy, error) {
+	field, key, hasKey := strings.Cut(s, ":")
+	switch field {
+	case GroupByNamespace, GroupByWorkload, GroupByPod:
+		if hasKey {
+			return GroupBy{}, fmt.Errorf("invalid group-by value: %s (%s takes no key)", s, field)
+		}
+		return GroupBy{Field: field}, nil
+	case GroupByLabel, GroupByAnnotation:
+		if key == "" {
+			return GroupBy{}, fmt.Errorf("invalid group-by value: %s (must be %s:<key>)", s, field)
+		}
+		return GroupBy{Field: field, Key: key}, nil
+	}
+	return GroupBy{}, fmt.Errorf("invalid group-by value: %s (must be one of: %v)", s, GroupByFields())
+}
+
+// String returns the group-by value as parsed by ParseGroupBy
+func (g GroupBy) String() string {
+	if g.Key == "" {
+		return g.Field
+	}
+	return g.Field + ":" + g.Key
+}
+
+// Column returns the column header naming the groups, e.g. TEAM for label:team
+// or PART-OF for label:app.kubernetes.io/part-of
+func (g GroupBy) Column() string {
+	if g.Key == "" {
+		return strings.ToUpper(g.Field)
+	}
+	return strings.ToUpper(g.Key[strings.LastIndex(g.Key, "/")+1:])
+}
+
+// UsesNamespaceMetadata reports whether grouping reads namespace labels or annotations
+func (g GroupBy) UsesNamespaceMetadata() bool {
+	return g.Field == GroupByLabel || g.Field == GroupByAnnotation
+}
+
+// Rollup aggregates pod usages into groups sorted by name. Label and
+// annotation groupings use the pod's value, then its namespace's, and put
+// pods without either in UnsetGroup.
+func (g GroupBy) Rollup(pods []PodUsage, namespaces map[string]NamespaceMetadata) []GroupUsage {
+	switch g.Field {
+	case GroupByWorkload:
+		return RollupByWorkload(pods)
+	case GroupByPod:
+		return Rollup(pods, PodUsage.Key)
+	case GroupByLabel:
+		return Rollup(pods, func(pu PodUsage) string {
+			return metadataValue(pu.Labels, namespaces[pu.Namespace].Labels, g.Key)
+		})
+	case GroupByAnnotation:
+		return Rollup(pods, func(pu PodUsage) string {
+			return metadataValue(pu.Annotations, namespaces[pu.Namespace].Annotations, g.Key)
+		})
+	default:
+		return RollupByNamespace(pods)
+	}
+}
+
+// metadataValue returns the pod's value for key, falling back to the namespace's
+func metadataValue(pod, namespace map[string]string, key string) string {
+	if v := pod[key]; v != "" {
+		return v
+	}
+	if v := namespace[key]; v != "" {
+		return v
+	}
+	return UnsetGroup
+}
@@ -0,0 +1,85 @@
+package calculator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   GroupBy
+		column string
+		errMsg string
+	}{
+		{input: "namespace", want: GroupBy{Field: GroupByNamespace}, column: "NAMESPACE"},
+		{input: "workload", want: GroupBy{Field: GroupByWorkload}, column: "WORKLOAD"},
+		{input: "label:team", want: GroupBy{Field: GroupByLabel, Key: "team"}, column: "TEAM"},
+		{input: "label:app.kubernetes.io/part-of", want: GroupBy{Field: GroupByLabel, Key: "app.kubernetes.io/part-of"}, column: "PART-OF"},
+		{input: "annotation:example.com/cost-center", want: GroupBy{Field: GroupByAnnotation, Key: "example.com/cost-center"}, column: "COST-CENTER"},
+		{input: "label", errMsg: "must be label:<key>"},
+		{input: "annotation:", errMsg: "must be annotation:<key>"},
+		{input: "pod:name", errMsg: "pod takes no key"},
+		{input: "team", errMsg: "must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseGroupBy(tt.input)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if got.String() != tt.input {
+				t.Errorf("expected String() %q, got %q", tt.input, got.String())
+			}
+			if got.Column() != tt.column {
+				t.Errorf("expected column %q, got %q", tt.column, got.Column())
+			}
+		})
+	}
+}
+
+func TestGroupByRollupMetadata(t *testing.T) {
+	pods := []PodUsage{
+		{Namespace: "payment", Name: "api", Labels: map[string]string{"team": "checkout"}},
+		{Namespace: "payment", Name: "worker", Annotations: map[string]string{"owner": "alice"}},
+		{Namespace: "search", Name: "indexer", Labels: map[string]string{"team": ""}},
+		{Namespace: "batch", Name: "job"},
+	}
+	namespaces := map[string]NamespaceMetadata{
+		"payment": {Labels: map[string]string{"team": "payments"}},
+		"search":  {Labels: map[string]string{"team": "search"}, Annotations: map[string]string{"owner": "bob"}},
+	}
+
+	tests := []struct {
+		groupBy GroupBy
+		want    map[string]int
+	}{
+		{GroupBy{Field: GroupByLabel, Key: "team"}, map[string]int{"checkout": 1, "payments": 1, "search": 1, UnsetGroup: 1}},
+		{GroupBy{Field: GroupByAnnotation, Key: "owner"}, map[string]int{"alice": 1, "bob": 1, UnsetGroup: 2}},
+		{GroupBy{Field: GroupByNamespace}, map[string]int{"payment": 2, "search": 1, "batch": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.groupBy.String(), func(t *testing.T) {
+			groups := tt.groupBy.Rollup(pods, namespaces)
+			if len(groups) != len(tt.want) {
+				t.Fatalf("expected %d groups, got %+v", len(tt.want), groups)
+			}
+			for _, g := range groups {
+				if g.Pods != tt.want[g.Name] {
+					t.Errorf("group %s: expected %d pods, got %d", g.Name, tt.want[g.Name], g.Pods)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package calculator
+
+import (
+	"sort"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ScalingState is how much room a HorizontalPodAutoscaler has left to add replicas
+type ScalingState string
+
+// Scaling states, from the most to the least headroom
+const (
+	ScalingOK      ScalingState = "ok"
+	ScalingNearMax ScalingState = "near-max" // at least nearMaxRatio of maxReplicas
+	ScalingAtMax   ScalingState = "at-max"   // capped: more load won't add replicas
+)
+
+// nearMaxRatio is the share of maxReplicas from which an HPA is near its maximum
+const nearMaxRatio = 0.8
+
+// HPAUsage compares a HorizontalPodAutoscaler's utilization targets with the
+// usage of the pods it scales
+type HPAUsage struct {
+	Namespace       string
+	Name            string
+	Target          string // scale target as Kind/name, matching PodUsage.Workload
+	MinReplicas     int32
+	MaxReplicas     int32
+	CurrentReplicas int32
+	DesiredReplicas int32
+	Pods            int         // pods of the scale target with metrics
+	Metrics         []HPAMetric // resource utilization targets, sorted by resource name
+	State           ScalingState
+}
+
+// HPAMetric is a resource utilization target next to the pods' current average Request%
+type HPAMetric struct {
+	Resource      corev1.ResourceName
+	TargetPercent float64 // averageUtilization, relative to requests like Request%
+
+	// RequestPercent is the usage of the pods relative to their requests,
+	// averaged across replicas the way the HPA does. nil without requests or usage.
+	RequestPercent *float64
+}
+
+// CalculateHPAUsages matches each HPA to the pods of its scale target and
+// averages their Request% for every resource the HPA has a utilization
+// target on. Targets on other metrics are left out; the replica counts and
+// scaling state are reported either way. Results are sorted by namespace and name.
+func CalculateHPAUsages(hpas []autoscalingv2.HorizontalPodAutoscaler, pods []PodUsage) []HPAUsage {
+	byWorkload := make(map[string][]PodUsage)
+	for _, pu := range pods {
+		key := pu.Namespace + "/" + pu.Workload
+		byWorkload[key] = append(byWorkload[key], pu)
+	}
+
+	usages := make([]HPAUsage, 0, len(hpas))
+	for _, hpa := range hpas {
+		ref := hpa.Spec.ScaleTargetRef
+		usage := HPAUsage{
+			Namespace:       hpa.Namespace,
+			Name:            hpa.Name,
+			Target:          ref.Kind + "/" + ref.Name,
+			MinReplicas:     1,
+			MaxReplicas:     hpa.Spec.MaxReplicas,
+			CurrentReplicas: hpa.Status.CurrentReplicas,
+			DesiredReplicas: hpa.Status.DesiredReplicas,
+		}
+		if hpa.Spec.MinReplicas != nil {
+			usage.MinReplicas = *hpa.Spec.MinReplicas
+		}
+		usage.State = scalingState(usage)
+
+		targetPods := byWorkload[hpa.Namespace+"/"+usage.Target]
+		usage.Pods = len(targetPods)
+		var totals ResourceUsages
+		if len(targetPods) > 0 {
+			totals = Rollup(targetPods, func(PodUsage) string { return usage.Target })[0].Resources
+		}
+		for _, spec := range hpa.Spec.Metrics {
+			if spec.Type != autoscalingv2.ResourceMetricSourceType || spec.Resource == nil ||
+				spec.Resource.Target.Type != autoscalingv2.UtilizationMetricType || spec.Resource.Target.AverageUtilization == nil {
+				continue
+			}
+			usage.Metrics = append(usage.Metrics, HPAMetric{
+				Resource:       spec.Resource.Name,
+				TargetPercent:  float64(*spec.Resource.Target.AverageUtilization),
+				RequestPercent: totals[spec.Resource.Name].RequestPercent,
+			})
+		}
+		sort.Slice(usage.Metrics, func(i, j int) bool {
+			return usage.Metrics[i].Resource < usage.Metrics[j].Resource
+		})
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
+
+// scalingState compares the larger of the current and desired replicas with
+// maxReplicas, so an HPA about to scale out to its maximum counts as capped
+func scalingState(usage HPAUsage) ScalingState {
+	replicas := max(usage.CurrentReplicas, usage.DesiredReplicas)
+	switch {
+	case replicas >= usage.MaxReplicas:
+		return ScalingAtMax
+	case float64(replicas) >= nearMaxRatio*float64(usage.MaxReplicas):
+		return ScalingNearMax
+	default:
+		return ScalingOK
+	}
+}
@@ -0,0 +1,104 @@
+package calculator
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCalculateHPAUsages(t *testing.T) {
+	utilization := func(name corev1.ResourceName, percent int32) autoscalingv2.MetricSpec {
+		return autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   name,
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: &percent},
+			},
+		}
+	}
+	minReplicas := int32(2)
+	hpas := []autoscalingv2.HorizontalPodAutoscaler{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "payment"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "StatefulSet", Name: "worker"},
+				MaxReplicas:    3,
+				Metrics:        []autoscalingv2.MetricSpec{{Type: autoscalingv2.PodsMetricSourceType}},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 3},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payment"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "api"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    10,
+				Metrics:        []autoscalingv2.MetricSpec{utilization(corev1.ResourceMemory, 80), utilization(corev1.ResourceCPU, 60)},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: 2, DesiredReplicas: 2},
+		},
+	}
+	pod := func(namespace, workload, cpuUsage string) PodUsage {
+		return PodUsage{
+			Namespace: namespace,
+			Workload:  workload,
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse(cpuUsage), Requests: quantityPtr("200m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("128Mi")},
+			},
+		}
+	}
+	pods := []PodUsage{
+		pod("payment", "Deployment/api", "100m"),
+		pod("payment", "Deployment/api", "200m"),
+		pod("default", "Deployment/api", "1"),
+	}
+
+	usages := CalculateHPAUsages(hpas, pods)
+	if len(usages) != 2 || usages[0].Name != "api" || usages[1].Name != "worker" {
+		t.Fatalf("expected api then worker, got %+v", usages)
+	}
+
+	api := usages[0]
+	if api.Target != "Deployment/api" || api.Pods != 2 || api.MinReplicas != 2 || api.State != ScalingOK {
+		t.Errorf("unexpected api usage: %+v", api)
+	}
+	if len(api.Metrics) != 2 || api.Metrics[0].Resource != corev1.ResourceCPU || api.Metrics[0].TargetPercent != 60 {
+		t.Fatalf("expected cpu then memory targets, got %+v", api.Metrics)
+	}
+	// (100m + 200m) / (200m + 200m), ignoring the pod of the same workload name in another namespace
+	if !equalPercent(api.Metrics[0].RequestPercent, floatPtr(75)) {
+		t.Errorf("expected cpu Request%% 75, got %v", fmtPercent(api.Metrics[0].RequestPercent))
+	}
+	if api.Metrics[1].RequestPercent != nil {
+		t.Errorf("expected no memory Request%% without requests, got %v", fmtPercent(api.Metrics[1].RequestPercent))
+	}
+
+	worker := usages[1]
+	if worker.MinReplicas != 1 || worker.Pods != 0 || len(worker.Metrics) != 0 || worker.State != ScalingAtMax {
+		t.Errorf("unexpected worker usage: %+v", worker)
+	}
+}
+
+func TestScalingState(t *testing.T) {
+	tests := []struct {
+		current, desired, max int32
+		want                  ScalingState
+	}{
+		{current: 2, desired: 2, max: 10, want: ScalingOK},
+		{current: 8, desired: 8, max: 10, want: ScalingNearMax},
+		{current: 7, desired: 9, max: 10, want: ScalingNearMax},
+		{current: 10, desired: 10, max: 10, want: ScalingAtMax},
+		{current: 1, desired: 1, max: 1, want: ScalingAtMax},
+	}
+
+	for _, tt := range tests {
+		usage := HPAUsage{CurrentReplicas: tt.current, DesiredReplicas: tt.desired, MaxReplicas: tt.max}
+		if got := scalingState(usage); got != tt.want {
+			t.Errorf("%d/%d replicas of %d: expected %s, got %s", tt.current, tt.desired, tt.max, tt.want, got)
+		}
+	}
+}
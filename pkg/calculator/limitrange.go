@@ -0,0 +1,125 @@
+package calculator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ValueSource records where a request or limit value came from
+type ValueSource string
+
+const (
+	SourceExplicit   ValueSource = "explicit"   // set in the pod spec
+	SourceLimitRange ValueSource = "limitrange" // a default of the namespace's LimitRanges applies
+	SourceNone       ValueSource = "none"       // not set anywhere
+)
+
+// LimitRangeDefaults are the container defaults a namespace's LimitRanges
+// inject at admission for containers that do not set their own
+type LimitRangeDefaults struct {
+	Requests corev1.ResourceList
+	Limits   corev1.ResourceList
+
+	// Assumed fills missing requests and limits with the defaults, so
+	// percentages are computed against them; otherwise the defaults are
+	// only reported as the source and value that would apply
+	Assumed bool
+}
+
+// NewLimitRangeDefaults merges the Container defaults of a namespace's
+// LimitRanges. Like the LimitRanger admission plugin, a default limit also
+// serves as the default request when no default request is set.
+func NewLimitRangeDefaults(limitRanges []corev1.LimitRange) LimitRangeDefaults {
+	defaults := LimitRangeDefaults{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, q := range item.Default {
+				defaults.Limits[name] = q.DeepCopy()
+			}
+			for name, q := range item.DefaultRequest {
+				defaults.Requests[name] = q.DeepCopy()
+			}
+		}
+	}
+	for name, q := range defaults.Limits {
+		if _, ok := defaults.Requests[name]; !ok {
+			defaults.Requests[name] = q.DeepCopy()
+		}
+	}
+	return defaults
+}
+
+// requests returns the default requests, or nil if d is nil
+func (d *LimitRangeDefaults) requests() corev1.ResourceList {
+	if d == nil {
+		return nil
+	}
+	return d.Requests
+}
+
+// limits returns the default limits, or nil if d is nil
+func (d *LimitRangeDefaults) limits() corev1.ResourceList {
+	if d == nil {
+		return nil
+	}
+	return d.Limits
+}
+
+// assumed reports whether missing values are filled with the defaults
+func (d *LimitRangeDefaults) assumed() bool {
+	return d != nil && d.Assumed
+}
+
+// LimitRangeDefaultsByNamespace groups LimitRanges by namespace and merges
+// their defaults, which fill missing values if assumed is set
+func LimitRangeDefaultsByNamespace(limitRanges []corev1.LimitRange, assumed bool) map[string]*LimitRangeDefaults {
+	grouped := map[string][]corev1.LimitRange{}
+	for _, lr := range limitRanges {
+		grouped[lr.Namespace] = append(grouped[lr.Namespace], lr)
+	}
+
+	byNamespace := make(map[string]*LimitRangeDefaults, len(grouped))
+	for namespace, lrs := range grouped {
+		defaults := NewLimitRangeDefaults(lrs)
+		defaults.Assumed = assumed
+		byNamespace[namespace] = &defaults
+	}
+	return byNamespace
+}
+
+// resolveValue returns the value of name from explicit, the default that
+// applies if explicit does not set it, and where the value came from. The
+// value falls back to the default only if the defaults are assumed.
+func resolveValue(explicit, defaults corev1.ResourceList, assumed bool, name corev1.ResourceName) (value, def *resource.Quantity, source ValueSource) {
+	if v, ok := explicit[name]; ok {
+		v := v.DeepCopy()
+		return &v, nil, SourceExplicit
+	}
+	if v, ok := defaults[name]; ok {
+		v := v.DeepCopy()
+		if assumed {
+			value = &v
+		}
+		return value, &v, SourceLimitRange
+	}
+	return nil, nil, SourceNone
+}
+
+// mergeSource combines the sources of the values summed into a pod total.
+// Any assumed default makes the total assumed; any explicit value makes it explicit.
+func mergeSource(a, b ValueSource) ValueSource {
+	switch {
+	case a == SourceLimitRange || b == SourceLimitRange:
+		return SourceLimitRange
+	case a == SourceExplicit || b == SourceExplicit:
+		return SourceExplicit
+	default:
+		return SourceNone
+	}
+}
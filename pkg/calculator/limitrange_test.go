@@ -0,0 +1,181 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestNewLimitRangeDefaults(t *testing.T) {
+	limitRanges := []corev1.LimitRange{
+		{
+			Spec: corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{
+					{
+						Type:           corev1.LimitTypeContainer,
+						Default:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+						DefaultRequest: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+					},
+					{
+						Type:    corev1.LimitTypePod,
+						Default: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+					},
+				},
+			},
+		},
+	}
+
+	defaults := NewLimitRangeDefaults(limitRanges)
+
+	tests := []struct {
+		name string
+		list corev1.ResourceList
+		res  corev1.ResourceName
+		want string
+	}{
+		{"cpu limit", defaults.Limits, corev1.ResourceCPU, "1"},
+		{"memory limit", defaults.Limits, corev1.ResourceMemory, "512Mi"},
+		{"memory request", defaults.Requests, corev1.ResourceMemory, "256Mi"},
+		{"cpu request falls back to default limit", defaults.Requests, corev1.ResourceCPU, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, ok := tt.list[tt.res]
+			if !ok {
+				t.Fatalf("expected %s default", tt.res)
+			}
+			if q.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, q.String())
+			}
+		})
+	}
+}
+
+func TestCalculatePodUsageWithDefaults(t *testing.T) {
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{
+				Name: "app",
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+					},
+				},
+			},
+		},
+	}
+	defaults := &LimitRangeDefaults{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		Assumed:  true,
+	}
+
+	without := CalculatePodUsage(podMetric, pod)
+	withoutMemory := without.Resources[corev1.ResourceMemory]
+	if withoutMemory.LimitPercent != nil || withoutMemory.LimitSource != SourceNone {
+		t.Errorf("expected no memory limit without defaults, got %v (%s)", withoutMemory.LimitPercent, withoutMemory.LimitSource)
+	}
+	withoutCPU := without.Resources[corev1.ResourceCPU]
+	if withoutCPU.RequestSource != SourceExplicit {
+		t.Errorf("expected explicit cpu request, got %s", withoutCPU.RequestSource)
+	}
+
+	with := CalculatePodUsageWithDefaults(podMetric, pod, defaults)
+	withCPU := with.Resources[corev1.ResourceCPU]
+	if withCPU.RequestSource != SourceExplicit || *withCPU.RequestPercent != 50 {
+		t.Errorf("expected explicit cpu request at 50%%, got %s at %v", withCPU.RequestSource, withCPU.RequestPercent)
+	}
+	withMemory := with.Resources[corev1.ResourceMemory]
+	if withMemory.RequestSource != SourceLimitRange || *withMemory.RequestPercent != 50 {
+		t.Errorf("expected limitrange memory request at 50%%, got %s at %v", withMemory.RequestSource, withMemory.RequestPercent)
+	}
+	if withMemory.LimitSource != SourceLimitRange || *withMemory.LimitPercent != 25 {
+		t.Errorf("expected limitrange memory limit at 25%%, got %s at %v", withMemory.LimitSource, withMemory.LimitPercent)
+	}
+	if withCPU.LimitSource != SourceNone || withCPU.LimitPercent != nil {
+		t.Errorf("expected no cpu limit, got %s at %v", withCPU.LimitSource, withCPU.LimitPercent)
+	}
+	if c := with.Containers[0]; c.Resources[corev1.ResourceMemory].LimitSource != SourceLimitRange {
+		t.Errorf("expected container memory limit from limitrange, got %s", c.Resources[corev1.ResourceMemory].LimitSource)
+	}
+}
+
+func TestCalculatePodUsageWithUnassumedDefaults(t *testing.T) {
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}},
+			{Name: "sidecar", Usage: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}},
+		},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app"},
+				{Name: "sidecar", Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+				}},
+			},
+		},
+	}
+	defaults := &LimitRangeDefaults{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+
+	pu := CalculatePodUsageWithDefaults(podMetric, pod, defaults)
+
+	memory := pu.Resources[corev1.ResourceMemory]
+	if memory.RequestSource != SourceLimitRange || memory.Requests != nil || memory.RequestPercent != nil {
+		t.Errorf("expected unassumed limitrange request without a percentage, got %s %v at %v", memory.RequestSource, memory.Requests, memory.RequestPercent)
+	}
+	if memory.DefaultRequests == nil || memory.DefaultRequests.String() != "512Mi" {
+		t.Errorf("expected 512Mi requests with defaults filled in, got %v", memory.DefaultRequests)
+	}
+	// The sidecar's explicit limit still counts; the app's default does not
+	if memory.LimitSource != SourceLimitRange || memory.Limits.String() != "128Mi" || *memory.LimitPercent != 150 {
+		t.Errorf("expected 128Mi explicit limits at 150%%, got %s %v at %v", memory.LimitSource, memory.Limits, memory.LimitPercent)
+	}
+	if memory.DefaultLimits == nil || memory.DefaultLimits.String() != "640Mi" {
+		t.Errorf("expected 640Mi limits with defaults filled in, got %v", memory.DefaultLimits)
+	}
+
+	app := pu.Containers[0].Resources[corev1.ResourceMemory]
+	if app.LimitSource != SourceLimitRange || app.Limits != nil || app.DefaultLimits.String() != "512Mi" {
+		t.Errorf("expected the app's default limit to be reported but not used, got %s %v default %v", app.LimitSource, app.Limits, app.DefaultLimits)
+	}
+}
+
+func TestMergeSource(t *testing.T) {
+	tests := []struct {
+		a, b ValueSource
+		want ValueSource
+	}{
+		{SourceNone, SourceNone, SourceNone},
+		{SourceNone, SourceExplicit, SourceExplicit},
+		{SourceExplicit, SourceLimitRange, SourceLimitRange},
+		{SourceLimitRange, SourceNone, SourceLimitRange},
+	}
+	for _, tt := range tests {
+		if got := mergeSource(tt.a, tt.b); got != tt.want {
+			t.Errorf("mergeSource(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package calculator
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NodeAllocation is how much of a node's resources its pods have requested
+type NodeAllocation struct {
+	Node      string
+	Resources []ResourceAllocation // sorted by resource name
+}
+
+// ResourceAllocation is the requested share of one node resource
+type ResourceAllocation struct {
+	Name             corev1.ResourceName
+	Capacity         resource.Quantity
+	Allocatable      resource.Quantity
+	Requested        resource.Quantity
+	Pods             int      // pods requesting the resource
+	AllocatedPercent *float64 // Requested relative to Allocatable, nil if nothing is allocatable
+}
+
+// CalculateNodeAllocations sums the requests of the pods scheduled on each
+// node and compares them with the node's capacity and allocatable. Every
+// resource the node advertises or its pods request is included, except the
+// pod count; finished pods no longer hold their requests and are skipped.
+func CalculateNodeAllocations(nodes []corev1.Node, pods []corev1.Pod) []NodeAllocation {
+	type requestTotals struct {
+		requested resource.Quantity
+		pods      int
+	}
+
+	requested := make(map[string]map[corev1.ResourceName]*requestTotals)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		byName, ok := requested[pod.Spec.NodeName]
+		if !ok {
+			byName = make(map[corev1.ResourceName]*requestTotals)
+			requested[pod.Spec.NodeName] = byName
+		}
+		for name, q := range EffectivePodRequests(pod) {
+			if q.IsZero() {
+				continue
+			}
+			t, ok := byName[name]
+			if !ok {
+				t = &requestTotals{}
+				byName[name] = t
+			}
+			t.requested.Add(q)
+			t.pods++
+		}
+	}
+
+	allocations := make([]NodeAllocation, 0, len(nodes))
+	for _, node := range nodes {
+		names := make(map[corev1.ResourceName]bool)
+		for _, list := range []corev1.ResourceList{node.Status.Capacity, node.Status.Allocatable} {
+			for name, q := range list {
+				// Nodes advertise every hugepages size, usually with nothing reserved
+				if IsHugePagesResource(name) && q.IsZero() {
+					continue
+				}
+				names[name] = true
+			}
+		}
+		for name := range requested[node.Name] {
+			names[name] = true
+		}
+		delete(names, corev1.ResourcePods)
+
+		allocation := NodeAllocation{Node: node.Name}
+		for _, name := range SortedResourceNames(names) {
+			ra := ResourceAllocation{
+				Name:        name,
+				Capacity:    node.Status.Capacity[name],
+				Allocatable: node.Status.Allocatable[name],
+			}
+			if t, ok := requested[node.Name][name]; ok {
+				ra.Requested = t.requested
+				ra.Pods = t.pods
+			}
+			ra.AllocatedPercent = CalculatePercent(&ra.Requested, &ra.Allocatable)
+			allocation.Resources = append(allocation.Resources, ra)
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Node < allocations[j].Node
+	})
+	return allocations
+}
+
+// EffectivePodRequests returns the requests the scheduler reserves for a pod:
+// the sum of its containers' requests or the largest init container request,
+// whichever is higher, plus the pod overhead
+func EffectivePodRequests(pod corev1.Pod) corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		for name, q := range c.Resources.Requests {
+			sum := total[name]
+			sum.Add(q)
+			total[name] = sum
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		for name, q := range c.Resources.Requests {
+			if current, ok := total[name]; !ok || q.Cmp(current) > 0 {
+				total[name] = q.DeepCopy()
+			}
+		}
+	}
+	for name, q := range pod.Spec.Overhead {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+	return total
+}
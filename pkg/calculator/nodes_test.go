@@ -0,0 +1,100 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCalculateNodeAllocations(t *testing.T) {
+	gpuNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-1"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("8"),
+				corev1.ResourcePods: resource.MustParse("110"),
+				resourceGPU:         resource.MustParse("4"),
+				"hugepages-1Gi":     resource.MustParse("0"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("7500m"),
+				corev1.ResourcePods: resource.MustParse("110"),
+				resourceGPU:         resource.MustParse("4"),
+				"hugepages-1Gi":     resource.MustParse("0"),
+			},
+		},
+	}
+	cpuNode := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "cpu-1"},
+		Status: corev1.NodeStatus{
+			Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+		},
+	}
+	gpuPod := func(name string, gpus string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ml"},
+			Spec: corev1.PodSpec{
+				NodeName: "gpu-1",
+				Containers: []corev1.Container{{
+					Name: "train",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+						resourceGPU:        resource.MustParse(gpus),
+					}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []corev1.Pod{
+		gpuPod("a", "2", corev1.PodRunning),
+		gpuPod("b", "1", corev1.PodPending),
+		gpuPod("done", "4", corev1.PodSucceeded),
+		{ObjectMeta: metav1.ObjectMeta{Name: "unscheduled"}, Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "c"}}}},
+	}
+
+	allocations := CalculateNodeAllocations([]corev1.Node{gpuNode, cpuNode}, pods)
+	if len(allocations) != 2 || allocations[0].Node != "cpu-1" || allocations[1].Node != "gpu-1" {
+		t.Fatalf("expected cpu-1 and gpu-1 sorted by name, got %+v", allocations)
+	}
+
+	gpu := allocations[1]
+	if len(gpu.Resources) != 2 {
+		t.Fatalf("expected cpu and gpu on gpu-1 (no pods or empty hugepages), got %+v", gpu.Resources)
+	}
+	cpu, gpus := gpu.Resources[0], gpu.Resources[1]
+	if cpu.Name != corev1.ResourceCPU || cpu.Requested.String() != "2" || *cpu.AllocatedPercent != 80.0/3 {
+		t.Errorf("unexpected cpu allocation: %s requested at %v%%", cpu.Requested.String(), *cpu.AllocatedPercent)
+	}
+	if gpus.Name != resourceGPU || gpus.Requested.Value() != 3 || gpus.Pods != 2 || *gpus.AllocatedPercent != 75 {
+		t.Errorf("unexpected gpu allocation: %d requested by %d pods at %v%%", gpus.Requested.Value(), gpus.Pods, *gpus.AllocatedPercent)
+	}
+
+	if idle := allocations[0].Resources[0]; idle.Pods != 0 || *idle.AllocatedPercent != 0 {
+		t.Errorf("expected idle cpu-1, got %+v", idle)
+	}
+}
+
+func TestEffectivePodRequests(t *testing.T) {
+	requests := func(cpu string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}}
+	}
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "migrate", Resources: requests("2")}},
+			Containers: []corev1.Container{
+				{Name: "app", Resources: requests("500m")},
+				{Name: "sidecar", Resources: requests("100m")},
+			},
+			Overhead: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		},
+	}
+
+	got := EffectivePodRequests(pod)[corev1.ResourceCPU]
+	if got.String() != "2250m" {
+		t.Errorf("expected init container peak plus overhead 2250m, got %s", got.String())
+	}
+}
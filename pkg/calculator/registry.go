@@ -0,0 +1,123 @@
+package calculator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// UnitFamily is how quantities of a resource are measured and displayed
+type UnitFamily string
+
+const (
+	UnitFamilyCPU   UnitFamily = "cpu"   // cores, displayed in millicores or cores
+	UnitFamilyBytes UnitFamily = "bytes" // sizes, displayed in Ki, Mi or Gi
+	UnitFamilyCount UnitFamily = "count" // whole units such as GPUs
+)
+
+// ResourceDefinition describes how a resource is reported
+type ResourceDefinition struct {
+	Name          corev1.ResourceName // e.g. "memory", also used by flags, sort fields and rules
+	Column        string              // column prefix in tables, e.g. "MEM"
+	Unit          UnitFamily
+	RequireLimits bool // pods without a limit for it match --no-limits
+	Optional      bool // only reported once a usage source filled it in, e.g. ephemeral storage from the kubelet
+}
+
+// registry holds the resources every pod usage reports, in display order
+var registry = []ResourceDefinition{
+	{Name: corev1.ResourceCPU, Column: "CPU", Unit: UnitFamilyCPU, RequireLimits: true},
+	{Name: corev1.ResourceMemory, Column: "MEM", Unit: UnitFamilyBytes, RequireLimits: true},
+	{Name: corev1.ResourceEphemeralStorage, Column: "EPH", Unit: UnitFamilyBytes, Optional: true},
+}
+
+// Resources returns the registered resources in display order
+func Resources() []ResourceDefinition {
+	return append([]ResourceDefinition(nil), registry...)
+}
+
+// ShownResources returns the registered resources to report for the pods in
+// display order. Optional resources are left out unless a pod has their usage.
+func ShownResources(podUsages []PodUsage) []ResourceDefinition {
+	shown := make([]ResourceDefinition, 0, len(registry))
+	for _, def := range registry {
+		if !def.Optional || hasUsage(podUsages, def.Name) {
+			shown = append(shown, def)
+		}
+	}
+	return shown
+}
+
+// hasUsage reports whether any pod has the usage of a resource
+func hasUsage(podUsages []PodUsage, name corev1.ResourceName) bool {
+	for _, pu := range podUsages {
+		if ru, ok := pu.Resources[name]; ok && !ru.UsageUnavailable {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterResource adds a resource to every pod usage and formatter, replacing
+// a registered resource of the same name. It is not safe for concurrent use
+// and is meant to be called during initialization.
+func RegisterResource(def ResourceDefinition) {
+	for i, existing := range registry {
+		if existing.Name == def.Name {
+			registry[i] = def
+			return
+		}
+	}
+	registry = append(registry, def)
+}
+
+// ResourceNames returns the names of the registered resources
+func ResourceNames() []string {
+	names := make([]string, 0, len(registry))
+	for _, def := range registry {
+		names = append(names, string(def.Name))
+	}
+	return names
+}
+
+// registeredNames returns the names of the registered resources
+func registeredNames() []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(registry))
+	for _, def := range registry {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+// LookupResource finds a registered resource by name
+func LookupResource(name string) (ResourceDefinition, bool) {
+	for _, def := range registry {
+		if string(def.Name) == name {
+			return def, true
+		}
+	}
+	return ResourceDefinition{}, false
+}
+
+// DefinitionFor returns the definition of a resource. Unregistered resources
+// are extended resources: hugepages are measured in bytes, the rest in units.
+func DefinitionFor(name corev1.ResourceName) ResourceDefinition {
+	for _, def := range registry {
+		if def.Name == name {
+			return def
+		}
+	}
+	unit := UnitFamilyCount
+	if IsHugePagesResource(name) {
+		unit = UnitFamilyBytes
+	}
+	return ResourceDefinition{Name: name, Column: string(name), Unit: unit}
+}
+
+// IsRegistered reports whether a resource is in the registry
+func IsRegistered(name corev1.ResourceName) bool {
+	for _, def := range registry {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestDefinitionFor(t *testing.T) {
+	tests := []struct {
+		name corev1.ResourceName
+		want UnitFamily
+	}{
+		{corev1.ResourceCPU, UnitFamilyCPU},
+		{corev1.ResourceMemory, UnitFamilyBytes},
+		{corev1.ResourceEphemeralStorage, UnitFamilyBytes},
+		{"hugepages-2Mi", UnitFamilyBytes},
+		{resourceGPU, UnitFamilyCount},
+	}
+	for _, tt := range tests {
+		if got := DefinitionFor(tt.name).Unit; got != tt.want {
+			t.Errorf("DefinitionFor(%s).Unit = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookupResource(t *testing.T) {
+	if def, ok := LookupResource("memory"); !ok || def.Column != "MEM" {
+		t.Errorf("expected memory to be registered as MEM, got %+v (%v)", def, ok)
+	}
+	if _, ok := LookupResource(string(resourceGPU)); ok {
+		t.Errorf("expected %s not to be registered", resourceGPU)
+	}
+}
+
+func TestRegisterResource(t *testing.T) {
+	saved := Resources()
+	defer func() { registry = saved }()
+
+	RegisterResource(ResourceDefinition{Name: resourceGPU, Column: "GPU", Unit: UnitFamilyCount})
+	if got := ResourceNames(); len(got) != len(saved)+1 || got[len(got)-1] != string(resourceGPU) {
+		t.Fatalf("expected %s appended to the registry, got %v", resourceGPU, got)
+	}
+	if !contains(SortFields(), string(resourceGPU)) {
+		t.Errorf("expected %s to be a sort field, got %v", resourceGPU, SortFields())
+	}
+
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "trainer", Namespace: "ml"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "train", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+		},
+	}
+	pod := corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "train"}}}}
+
+	// Registered resources are reported even when the pod doesn't set them
+	pu := CalculatePodUsage(podMetric, pod)
+	gpu, ok := pu.Resources[resourceGPU]
+	if !ok || !gpu.UsageUnavailable || gpu.Requests != nil {
+		t.Fatalf("expected an unavailable GPU usage without requests, got %+v (%v)", gpu, ok)
+	}
+
+	SetResourceUsage(&pu, resourceGPU, resource.MustParse("1"), map[string]resource.Quantity{"train": resource.MustParse("1")})
+	if gpu := pu.Resources[resourceGPU]; gpu.UsageUnavailable || gpu.Usage.Value() != 1 {
+		t.Errorf("expected a GPU usage of 1, got %+v", gpu)
+	}
+	if gpu := pu.Containers[0].Resources[resourceGPU]; gpu.UsageUnavailable || gpu.Usage.Value() != 1 {
+		t.Errorf("expected a container GPU usage of 1, got %+v", gpu)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package calculator
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// GroupUsage represents aggregated resource usage of a group of pods,
+// such as all pods in a namespace or on a node
+type GroupUsage struct {
+	Name      string
+	Pods      int
+	Resources ResourceUsages
+	Cost      *Cost // summed over the pods with an estimated cost, nil if none has one
+}
+
+// resourceTotals accumulates a resource across pods. Percentages only count
+// the usage of pods that set the corresponding requests or limits, so pods
+// without them don't inflate the group's Request% or Limit%.
+type resourceTotals struct {
+	usage             resource.Quantity
+	requests          resource.Quantity
+	limits            resource.Quantity
+	usageWithRequests resource.Quantity
+	usageWithLimits   resource.Quantity
+	hasRequests       bool
+	hasLimits         bool
+	hasUsage          bool
+}
+
+// add accumulates one pod's resource usage
+func (t *resourceTotals) add(ru ResourceUsage) {
+	if !ru.UsageUnavailable {
+		t.usage.Add(ru.Usage)
+		t.hasUsage = true
+	}
+	if ru.Requests != nil {
+		t.requests.Add(*ru.Requests)
+		t.usageWithRequests.Add(ru.Usage)
+		t.hasRequests = true
+	}
+	if ru.Limits != nil {
+		t.limits.Add(*ru.Limits)
+		t.usageWithLimits.Add(ru.Usage)
+		t.hasLimits = true
+	}
+}
+
+// toResourceUsage converts the totals to a ResourceUsage
+func (t *resourceTotals) toResourceUsage() ResourceUsage {
+	ru := ResourceUsage{Usage: t.usage, UsageUnavailable: !t.hasUsage}
+	if t.hasRequests {
+		requests := t.requests
+		ru.Requests = &requests
+		if t.hasUsage {
+			ru.RequestPercent = CalculatePercent(&t.usageWithRequests, &requests)
+		}
+	}
+	if t.hasLimits {
+		limits := t.limits
+		ru.Limits = &limits
+		if t.hasUsage {
+			ru.LimitPercent = CalculatePercent(&t.usageWithLimits, &limits)
+		}
+	}
+	return ru
+}
+
+// RollupByNamespace aggregates pod usages per namespace, sorted by name.
+// Namespaces of different clusters are named cluster/namespace.
+func RollupByNamespace(pods []PodUsage) []GroupUsage {
+	return Rollup(pods, func(pu PodUsage) string { return clusterScoped(pu, pu.Namespace) })
+}
+
+// RollupByNode aggregates pod usages per node, sorted by name.
+// Nodes of different clusters are named cluster/node.
+func RollupByNode(pods []PodUsage) []GroupUsage {
+	return Rollup(pods, func(pu PodUsage) string { return clusterScoped(pu, pu.Node) })
+}
+
+// RollupByWorkload aggregates pod usages per workload, named namespace/workload
+// (cluster/namespace/workload across clusters) and sorted by name
+func RollupByWorkload(pods []PodUsage) []GroupUsage {
+	return Rollup(pods, func(pu PodUsage) string { return clusterScoped(pu, pu.Namespace+"/"+pu.Workload) })
+}
+
+// clusterScoped prefixes a group name with the pod's cluster, if any
+func clusterScoped(pu PodUsage, name string) string {
+	if pu.Cluster == "" {
+		return name
+	}
+	return pu.Cluster + "/" + name
+}
+
+// Rollup aggregates pod usages by the group name returned by key, sorted by name
+func Rollup(pods []PodUsage, key func(PodUsage) string) []GroupUsage {
+	type groupTotals struct {
+		pods      int
+		resources map[corev1.ResourceName]*resourceTotals
+		cost      *Cost
+	}
+
+	groups := make(map[string]*groupTotals)
+	for _, pu := range pods {
+		name := key(pu)
+		g, ok := groups[name]
+		if !ok {
+			g = &groupTotals{resources: make(map[corev1.ResourceName]*resourceTotals)}
+			groups[name] = g
+		}
+		g.pods++
+		for resourceName, ru := range pu.Resources {
+			t, ok := g.resources[resourceName]
+			if !ok {
+				t = &resourceTotals{}
+				g.resources[resourceName] = t
+			}
+			t.add(ru)
+		}
+		if pu.Cost != nil {
+			if g.cost == nil {
+				g.cost = &Cost{}
+			}
+			g.cost.Add(*pu.Cost)
+		}
+	}
+
+	result := make([]GroupUsage, 0, len(groups))
+	for name, g := range groups {
+		resources := make(ResourceUsages, len(g.resources))
+		for resourceName, t := range g.resources {
+			resources[resourceName] = t.toResourceUsage()
+		}
+		result = append(result, GroupUsage{Name: name, Pods: g.pods, Resources: resources, Cost: g.cost})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
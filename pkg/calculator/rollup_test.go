@@ -0,0 +1,115 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestRollupByNamespace(t *testing.T) {
+	pods := []PodUsage{
+		{
+			Namespace: "default",
+			Name:      "pod1",
+			Node:      "node-1",
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("100m"), Requests: quantityPtr("200m"), Limits: quantityPtr("1")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("256Mi"), Limits: quantityPtr("512Mi")},
+			},
+		},
+		{
+			Namespace: "default",
+			Name:      "pod2",
+			Node:      "node-2",
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("300m"), Requests: quantityPtr("200m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("1Gi")},
+			},
+		},
+		{
+			Namespace: "kube-system",
+			Name:      "pod3",
+			Node:      "node-1",
+			Resources: ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("50m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("64Mi")},
+			},
+		},
+	}
+
+	groups := RollupByNamespace(pods)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+
+	def := groups[0]
+	if def.Name != "default" || def.Pods != 2 {
+		t.Errorf("expected default with 2 pods, got %s with %d", def.Name, def.Pods)
+	}
+	cpu, memory := def.Resources[corev1.ResourceCPU], def.Resources[corev1.ResourceMemory]
+	if cpu.Usage.MilliValue() != 400 {
+		t.Errorf("expected CPU usage 400m, got %s", cpu.Usage.String())
+	}
+	// CPU: (100m + 300m) / (200m + 200m) = 100% request
+	if cpu.RequestPercent == nil || *cpu.RequestPercent != 100 {
+		t.Errorf("expected CPU request percent 100, got %v", cpu.RequestPercent)
+	}
+	// CPU limit only set on pod1: 100m / 1 = 10%
+	if cpu.LimitPercent == nil || *cpu.LimitPercent != 10 {
+		t.Errorf("expected CPU limit percent 10, got %v", cpu.LimitPercent)
+	}
+	// Memory limit only set on pod1: 256Mi / 512Mi = 50%, pod2's usage is excluded
+	if memory.LimitPercent == nil || *memory.LimitPercent != 50 {
+		t.Errorf("expected memory limit percent 50, got %v", memory.LimitPercent)
+	}
+	if memory.Requests != nil || memory.RequestPercent != nil {
+		t.Errorf("expected no memory requests")
+	}
+
+	sys := groups[1]
+	if sys.Name != "kube-system" || sys.Pods != 1 {
+		t.Errorf("expected kube-system with 1 pod, got %s with %d", sys.Name, sys.Pods)
+	}
+	if cpu := sys.Resources[corev1.ResourceCPU]; cpu.RequestPercent != nil || cpu.LimitPercent != nil {
+		t.Errorf("expected nil CPU percentages for kube-system")
+	}
+}
+
+func TestRollupByNode(t *testing.T) {
+	pods := []PodUsage{
+		{Name: "pod1", Node: "node-b"},
+		{Name: "pod2", Node: "node-a"},
+		{Name: "pod3", Node: "node-b"},
+	}
+
+	groups := RollupByNode(pods)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].Name != "node-a" || groups[0].Pods != 1 {
+		t.Errorf("expected node-a with 1 pod, got %s with %d", groups[0].Name, groups[0].Pods)
+	}
+	if groups[1].Name != "node-b" || groups[1].Pods != 2 {
+		t.Errorf("expected node-b with 2 pods, got %s with %d", groups[1].Name, groups[1].Pods)
+	}
+}
+
+func TestRollupByNamespaceClusters(t *testing.T) {
+	pods := []PodUsage{
+		{Cluster: "prod-us", Namespace: "default", Name: "pod1"},
+		{Cluster: "prod-eu", Namespace: "default", Name: "pod2"},
+		{Cluster: "prod-eu", Namespace: "default", Name: "pod3"},
+	}
+
+	groups := RollupByNamespace(pods)
+	if len(groups) != 2 {
+		t.Fatalf("expected namespaces of different clusters to stay apart, got %+v", groups)
+	}
+	if groups[0].Name != "prod-eu/default" || groups[0].Pods != 2 {
+		t.Errorf("expected prod-eu/default with 2 pods, got %s with %d", groups[0].Name, groups[0].Pods)
+	}
+	if groups[1].Name != "prod-us/default" || groups[1].Pods != 1 {
+		t.Errorf("expected prod-us/default with 1 pod, got %s with %d", groups[1].Name, groups[1].Pods)
+	}
+}
@@ -0,0 +1,116 @@
+package calculator
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ReasonOOMKilled is the termination reason of a container killed for exceeding its memory limit
+const ReasonOOMKilled = "OOMKilled"
+
+// PodStatus holds the pod status fields that show how a pod copes with its resources
+type PodStatus struct {
+	Phase           corev1.PodPhase
+	QOSClass        corev1.PodQOSClass
+	Restarts        int32        // sum of the container restart counts
+	LastTermination *Termination // most recent termination of any container, nil if none
+	LastOOMKill     *Termination // most recent OOM kill of any container, nil if none
+}
+
+// Termination describes a container's last termination
+type Termination struct {
+	Container  string
+	Reason     string
+	ExitCode   int32
+	FinishedAt time.Time
+}
+
+// OOMKilled reports whether the termination was an OOM kill
+func (t *Termination) OOMKilled() bool {
+	return t != nil && t.Reason == ReasonOOMKilled
+}
+
+// NewPodStatus extracts the status of a pod
+func NewPodStatus(pod corev1.Pod) PodStatus {
+	status := PodStatus{
+		Phase:    pod.Status.Phase,
+		QOSClass: QOSClass(pod),
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		status.Restarts += cs.RestartCount
+		t := lastTermination(cs)
+		status.LastTermination = latest(status.LastTermination, t)
+		if t.OOMKilled() {
+			status.LastOOMKill = latest(status.LastOOMKill, t)
+		}
+	}
+	return status
+}
+
+// latest returns the more recent of two terminations, either of which may be nil
+func latest(a, b *Termination) *Termination {
+	if a == nil || (b != nil && b.FinishedAt.After(a.FinishedAt)) {
+		return b
+	}
+	return a
+}
+
+// containerStatuses indexes a pod's container statuses by container name
+func containerStatuses(pod corev1.Pod) map[string]corev1.ContainerStatus {
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	return statuses
+}
+
+// lastTermination returns the previous termination of a container, or nil if it never terminated
+func lastTermination(cs corev1.ContainerStatus) *Termination {
+	terminated := cs.LastTerminationState.Terminated
+	if terminated == nil {
+		return nil
+	}
+	return &Termination{
+		Container:  cs.Name,
+		Reason:     terminated.Reason,
+		ExitCode:   terminated.ExitCode,
+		FinishedAt: terminated.FinishedAt.Time,
+	}
+}
+
+// QOSClass returns the pod's QoS class, computing it like the API server
+// when the status does not carry it yet
+func QOSClass(pod corev1.Pod) corev1.PodQOSClass {
+	if pod.Status.QOSClass != "" {
+		return pod.Status.QOSClass
+	}
+
+	hasResources := false
+	guaranteed := true
+	for _, c := range pod.Spec.Containers {
+		if len(c.Resources.Requests) > 0 || len(c.Resources.Limits) > 0 {
+			hasResources = true
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			lim, hasLim := c.Resources.Limits[name]
+			if !hasLim {
+				guaranteed = false
+				continue
+			}
+			// A request defaults to the limit when only the limit is set
+			if req, hasReq := c.Resources.Requests[name]; hasReq && req.Cmp(lim) != 0 {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case !hasResources:
+		return corev1.PodQOSBestEffort
+	case guaranteed:
+		return corev1.PodQOSGuaranteed
+	default:
+		return corev1.PodQOSBurstable
+	}
+}
@@ -0,0 +1,139 @@
+package calculator
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func terminatedStatus(name, reason string, restarts int32, finishedAt time.Time) corev1.ContainerStatus {
+	return corev1.ContainerStatus{
+		Name:         name,
+		RestartCount: restarts,
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				Reason:     reason,
+				ExitCode:   137,
+				FinishedAt: metav1.NewTime(finishedAt),
+			},
+		},
+	}
+}
+
+func TestNewPodStatus(t *testing.T) {
+	oom := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	pod := corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase:    corev1.PodRunning,
+			QOSClass: corev1.PodQOSBurstable,
+			ContainerStatuses: []corev1.ContainerStatus{
+				terminatedStatus("app", ReasonOOMKilled, 2, oom),
+				terminatedStatus("sidecar", "Error", 1, oom.Add(time.Minute)),
+				{Name: "proxy"},
+			},
+		},
+	}
+
+	status := NewPodStatus(pod)
+	if status.Phase != corev1.PodRunning || status.QOSClass != corev1.PodQOSBurstable {
+		t.Errorf("expected Running/Burstable, got %s/%s", status.Phase, status.QOSClass)
+	}
+	if status.Restarts != 3 {
+		t.Errorf("expected 3 restarts, got %d", status.Restarts)
+	}
+	if status.LastTermination == nil || status.LastTermination.Container != "sidecar" {
+		t.Errorf("expected last termination of sidecar, got %+v", status.LastTermination)
+	}
+	if status.LastOOMKill == nil || status.LastOOMKill.Container != "app" || !status.LastOOMKill.FinishedAt.Equal(oom) {
+		t.Errorf("expected last OOM kill of app at %s, got %+v", oom, status.LastOOMKill)
+	}
+}
+
+func TestQOSClass(t *testing.T) {
+	resources := func(req, lim corev1.ResourceList) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Resources: corev1.ResourceRequirements{Requests: req, Limits: lim}},
+		}}}
+	}
+	full := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+	half := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")}
+
+	reported := resources(nil, nil)
+	reported.Status.QOSClass = corev1.PodQOSGuaranteed
+
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want corev1.PodQOSClass
+	}{
+		{"status is trusted", reported, corev1.PodQOSGuaranteed},
+		{"no resources", resources(nil, nil), corev1.PodQOSBestEffort},
+		{"limits only", resources(nil, full), corev1.PodQOSGuaranteed},
+		{"requests equal limits", resources(full, full), corev1.PodQOSGuaranteed},
+		{"requests below limits", resources(half, full), corev1.PodQOSBurstable},
+		{"requests only", resources(full, nil), corev1.PodQOSBurstable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QOSClass(tt.pod); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterPodUsages_Status(t *testing.T) {
+	oom := &Termination{Reason: ReasonOOMKilled, FinishedAt: time.Now()}
+	pods := []PodUsage{
+		{Name: "crashy", Status: PodStatus{Phase: corev1.PodRunning, QOSClass: corev1.PodQOSBurstable, Restarts: 2, LastOOMKill: oom}},
+		{Name: "steady", Status: PodStatus{Phase: corev1.PodRunning, QOSClass: corev1.PodQOSGuaranteed}},
+		{Name: "pending", Status: PodStatus{Phase: corev1.PodPending, QOSClass: corev1.PodQOSBestEffort}},
+	}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{"qos is case insensitive", FilterOptions{Above: -1, Below: -1, QOSClass: "guaranteed"}, []string{"steady"}},
+		{"phase", FilterOptions{Above: -1, Below: -1, Phase: "Running"}, []string{"crashy", "steady"}},
+		{"min restarts", FilterOptions{Above: -1, Below: -1, MinRestarts: 1}, []string{"crashy"}},
+		{"oom killed", FilterOptions{Above: -1, Below: -1, OOMKilled: true}, []string{"crashy"}},
+		{"combined with usage", FilterOptions{Above: 50, Below: -1, Field: "memory", Phase: "Running"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FilterPodUsages(pods, tt.opts)
+			if len(result) != len(tt.want) {
+				t.Fatalf("expected %v, got %d pods", tt.want, len(result))
+			}
+			for i, pod := range result {
+				if pod.Name != tt.want[i] {
+					t.Errorf("expected %s at %d, got %s", tt.want[i], i, pod.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSortPodUsagesByStatus(t *testing.T) {
+	now := time.Now()
+	pods := []PodUsage{
+		{Name: "old-oom", Status: PodStatus{Restarts: 1, LastOOMKill: &Termination{Reason: ReasonOOMKilled, FinishedAt: now.Add(-time.Hour)}}},
+		{Name: "never", Status: PodStatus{Restarts: 0}},
+		{Name: "new-oom", Status: PodStatus{Restarts: 5, LastOOMKill: &Termination{Reason: ReasonOOMKilled, FinishedAt: now}}},
+	}
+
+	SortPodUsages(pods, SortByRestarts, false)
+	if pods[0].Name != "new-oom" || pods[1].Name != "old-oom" || pods[2].Name != "never" {
+		t.Errorf("restarts descending sort failed: %v", []string{pods[0].Name, pods[1].Name, pods[2].Name})
+	}
+
+	SortPodUsages(pods, SortByOOM, true)
+	if pods[0].Name != "old-oom" || pods[1].Name != "new-oom" || pods[2].Name != "never" {
+		t.Errorf("oom ascending sort failed: %v", []string{pods[0].Name, pods[1].Name, pods[2].Name})
+	}
+}
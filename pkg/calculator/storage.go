@@ -0,0 +1,52 @@
+package calculator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// SetEphemeralStorageUsage fills in the ephemeral storage usage of a pod and
+// its containers, as reported in bytes by the kubelet. Containers missing
+// from containerBytes keep their usage unavailable.
+func SetEphemeralStorageUsage(pu *PodUsage, podBytes int64, containerBytes map[string]int64) {
+	containers := make(map[string]resource.Quantity, len(containerBytes))
+	for name, bytes := range containerBytes {
+		containers[name] = *resource.NewQuantity(bytes, resource.BinarySI)
+	}
+	SetResourceUsage(pu, corev1.ResourceEphemeralStorage, *resource.NewQuantity(podBytes, resource.BinarySI), containers)
+}
+
+// SetResourceUsage fills in the usage of a resource the metrics API doesn't
+// report, for a pod and its containers. Containers missing from containers
+// keep their usage unavailable.
+func SetResourceUsage(pu *PodUsage, name corev1.ResourceName, usage resource.Quantity, containers map[string]resource.Quantity) {
+	pu.Resources = withUsage(pu.Resources, name, usage)
+	for i := range pu.Containers {
+		if q, ok := containers[pu.Containers[i].Name]; ok {
+			pu.Containers[i].Resources = withUsage(pu.Containers[i].Resources, name, q)
+		}
+	}
+}
+
+// withUsage sets the usage of a resource and recalculates its percentages,
+// allocating the map if needed
+func withUsage(resources ResourceUsages, name corev1.ResourceName, usage resource.Quantity) ResourceUsages {
+	if resources == nil {
+		resources = make(ResourceUsages)
+	}
+	ru := resources[name]
+	ru.Usage = usage
+	ru.UsageUnavailable = false
+	ru.RequestPercent = CalculatePercent(&ru.Usage, ru.Requests)
+	ru.LimitPercent = CalculatePercent(&ru.Usage, ru.Limits)
+	resources[name] = ru
+	return resources
+}
+
+// withoutUsage marks a resource usage as unavailable, dropping its percentages
+func withoutUsage(ru ResourceUsage) ResourceUsage {
+	ru.UsageUnavailable = true
+	ru.RequestPercent = nil
+	ru.LimitPercent = nil
+	return ru
+}
@@ -0,0 +1,69 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func TestSetEphemeralStorageUsage(t *testing.T) {
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: "default"},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}},
+			{Name: "sidecar", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}},
+		},
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "writer", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("1Gi")},
+						Limits:   corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("2Gi")},
+					},
+				},
+				{Name: "sidecar"},
+			},
+		},
+	}
+
+	pu := CalculatePodUsage(podMetric, pod)
+	storage := pu.Resources[corev1.ResourceEphemeralStorage]
+	if !storage.UsageUnavailable || storage.LimitPercent != nil {
+		t.Fatalf("expected unavailable storage usage before stats are set, got %+v", storage)
+	}
+	if storage.Limits == nil || storage.Limits.String() != "2Gi" {
+		t.Errorf("expected 2Gi storage limit, got %v", storage.Limits)
+	}
+
+	SetEphemeralStorageUsage(&pu, 1<<30, map[string]int64{"app": 512 << 20})
+	storage = pu.Resources[corev1.ResourceEphemeralStorage]
+	if storage.UsageUnavailable {
+		t.Fatal("expected storage usage to be available")
+	}
+	if *storage.RequestPercent != 100 || *storage.LimitPercent != 50 {
+		t.Errorf("expected 100%%/50%%, got %v%%/%v%%", *storage.RequestPercent, *storage.LimitPercent)
+	}
+	if app := pu.Containers[0].Resources[corev1.ResourceEphemeralStorage]; app.UsageUnavailable || *app.LimitPercent != 25 {
+		t.Errorf("expected app container at 25%% of its limit, got %+v", app)
+	}
+	if sidecar := pu.Containers[1].Resources[corev1.ResourceEphemeralStorage]; !sidecar.UsageUnavailable {
+		t.Errorf("expected sidecar storage usage to stay unavailable, got %+v", sidecar)
+	}
+
+	pods := []PodUsage{{Name: "empty"}, pu}
+	SortPodUsages(pods, string(corev1.ResourceEphemeralStorage), false)
+	if pods[0].Name != "writer" {
+		t.Errorf("expected writer first, got %s", pods[0].Name)
+	}
+	filtered := FilterPodUsages(pods, FilterOptions{Above: 40, Below: -1, Field: string(corev1.ResourceEphemeralStorage)})
+	if len(filtered) != 1 || filtered[0].Name != "writer" {
+		t.Errorf("expected only writer above 40%%, got %d pods", len(filtered))
+	}
+}
@@ -0,0 +1,126 @@
+package calculator
+
+import "sync"
+
+// SortByThrottled sorts pods by the share of CFS periods they were throttled in
+const SortByThrottled = "throttled"
+
+// Throttling is a pod's CPU throttling and pressure as reported by cadvisor,
+// since the previous sample when a ThrottlingTracker has one and since the
+// containers started otherwise. CPU usage from the metrics API is an
+// average that hides short bursts capped by the CPU limit; throttling
+// shows them.
+type Throttling struct {
+	Periods          int64    // CFS enforcement periods of the containers with a CPU limit
+	ThrottledPeriods int64    // periods in which a container used up its quota
+	ThrottledPercent *float64 // ThrottledPeriods relative to Periods, nil without periods
+
+	// PressurePercent is the share of the containers' running time their
+	// tasks spent waiting for CPU (PSI "some"), nil where the kernel or
+	// cadvisor doesn't report pressure
+	PressurePercent *float64
+}
+
+// CPUStats are the cadvisor CPU counters of a container, or summed over a pod's containers
+type CPUStats struct {
+	Periods          int64
+	ThrottledPeriods int64
+	WaitingSeconds   *float64 // PSI CPU waiting time, nil if unavailable
+	RunningSeconds   float64  // time the waiting time was counted over
+}
+
+// Add sums two CPU counters. Running time only counts where waiting time is known.
+func (s CPUStats) Add(o CPUStats) CPUStats {
+	s.Periods += o.Periods
+	s.ThrottledPeriods += o.ThrottledPeriods
+	if o.WaitingSeconds != nil && o.RunningSeconds > 0 {
+		waiting := *o.WaitingSeconds
+		if s.WaitingSeconds != nil {
+			waiting += *s.WaitingSeconds
+		}
+		s.WaitingSeconds = &waiting
+		s.RunningSeconds += o.RunningSeconds
+	}
+	return s
+}
+
+// ThrottlingTracker keeps the previous cumulative CPU counters of containers
+// so repeated collections report throttling and pressure between samples
+// rather than since the containers started. It is safe for concurrent use.
+type ThrottlingTracker struct {
+	mu   sync.Mutex
+	prev map[string]CPUStats // keyed by namespace/pod/container
+}
+
+// NewThrottlingTracker creates a ThrottlingTracker without samples
+func NewThrottlingTracker() *ThrottlingTracker {
+	return &ThrottlingTracker{prev: make(map[string]CPUStats)}
+}
+
+// Since returns a container's counters since its previous sample and records
+// the current ones. The first sample of a container, and one whose counters
+// went backwards because it restarted, is returned unchanged.
+func (t *ThrottlingTracker) Since(key string, current CPUStats) CPUStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.prev[key]
+	t.prev[key] = current
+	if !ok || restarted(prev, current) {
+		return current
+	}
+
+	delta := CPUStats{
+		Periods:          current.Periods - prev.Periods,
+		ThrottledPeriods: current.ThrottledPeriods - prev.ThrottledPeriods,
+		RunningSeconds:   current.RunningSeconds - prev.RunningSeconds,
+	}
+	if current.WaitingSeconds != nil && prev.WaitingSeconds != nil {
+		waiting := *current.WaitingSeconds - *prev.WaitingSeconds
+		delta.WaitingSeconds = &waiting
+	}
+	return delta
+}
+
+// Prune drops the samples of containers whose keys keep rejects, e.g. of
+// pods that no longer exist
+func (t *ThrottlingTracker) Prune(keep func(key string) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key := range t.prev {
+		if !keep(key) {
+			delete(t.prev, key)
+		}
+	}
+}
+
+// restarted reports whether counters went backwards between two samples
+func restarted(prev, current CPUStats) bool {
+	if current.Periods < prev.Periods || current.ThrottledPeriods < prev.ThrottledPeriods || current.RunningSeconds < prev.RunningSeconds {
+		return true
+	}
+	return current.WaitingSeconds != nil && prev.WaitingSeconds != nil && *current.WaitingSeconds < *prev.WaitingSeconds
+}
+
+// SetThrottling fills in a pod's throttling from its containers' summed CPU counters
+func SetThrottling(pu *PodUsage, stats CPUStats) {
+	t := &Throttling{Periods: stats.Periods, ThrottledPeriods: stats.ThrottledPeriods}
+	if stats.Periods > 0 {
+		percent := float64(stats.ThrottledPeriods) * 100 / float64(stats.Periods)
+		t.ThrottledPercent = &percent
+	}
+	if stats.WaitingSeconds != nil && stats.RunningSeconds > 0 {
+		percent := min(*stats.WaitingSeconds*100/stats.RunningSeconds, 100)
+		t.PressurePercent = &percent
+	}
+	pu.Throttling = t
+}
+
+// throttledPercent returns the pod's throttled percentage, nil if it is unknown
+func (pu PodUsage) throttledPercent() *float64 {
+	if pu.Throttling == nil {
+		return nil
+	}
+	return pu.Throttling.ThrottledPercent
+}
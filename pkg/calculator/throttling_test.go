@@ -0,0 +1,135 @@
+package calculator
+
+import "testing"
+
+func TestSetThrottling(t *testing.T) {
+	waiting := 40.0
+	tests := []struct {
+		name          string
+		stats         CPUStats
+		wantThrottled *float64
+		wantPressure  *float64
+	}{
+		{
+			name:          "throttled with pressure",
+			stats:         CPUStats{Periods: 1000, ThrottledPeriods: 250, WaitingSeconds: &waiting, RunningSeconds: 200},
+			wantThrottled: floatPtr(25),
+			wantPressure:  floatPtr(20),
+		},
+		{
+			name:          "no CPU limit",
+			stats:         CPUStats{WaitingSeconds: &waiting, RunningSeconds: 400},
+			wantThrottled: nil,
+			wantPressure:  floatPtr(10),
+		},
+		{
+			name:          "no PSI",
+			stats:         CPUStats{Periods: 3, ThrottledPeriods: 1},
+			wantThrottled: floatPtr(100.0 / 3),
+			wantPressure:  nil,
+		},
+		{
+			name:          "pressure capped at 100%",
+			stats:         CPUStats{WaitingSeconds: &waiting, RunningSeconds: 20},
+			wantThrottled: nil,
+			wantPressure:  floatPtr(100),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pu PodUsage
+			SetThrottling(&pu, tt.stats)
+			if pu.Throttling == nil || pu.Throttling.Periods != tt.stats.Periods {
+				t.Fatalf("expected throttling with %d periods, got %+v", tt.stats.Periods, pu.Throttling)
+			}
+			if !equalPercent(pu.Throttling.ThrottledPercent, tt.wantThrottled) {
+				t.Errorf("expected throttled %v, got %v", fmtPercent(tt.wantThrottled), fmtPercent(pu.Throttling.ThrottledPercent))
+			}
+			if !equalPercent(pu.Throttling.PressurePercent, tt.wantPressure) {
+				t.Errorf("expected pressure %v, got %v", fmtPercent(tt.wantPressure), fmtPercent(pu.Throttling.PressurePercent))
+			}
+		})
+	}
+}
+
+func TestThrottlingTracker(t *testing.T) {
+	waiting := func(s float64) *float64 { return &s }
+	tracker := NewThrottlingTracker()
+
+	first := tracker.Since("default/api/app", CPUStats{Periods: 1000, ThrottledPeriods: 900, WaitingSeconds: waiting(50), RunningSeconds: 100})
+	if first.Periods != 1000 || first.ThrottledPeriods != 900 {
+		t.Errorf("expected the first sample unchanged, got %+v", first)
+	}
+
+	delta := tracker.Since("default/api/app", CPUStats{Periods: 1100, ThrottledPeriods: 910, WaitingSeconds: waiting(51), RunningSeconds: 110})
+	if delta.Periods != 100 || delta.ThrottledPeriods != 10 || *delta.WaitingSeconds != 1 || delta.RunningSeconds != 10 {
+		t.Errorf("expected the counters since the previous sample, got %+v", delta)
+	}
+	var pu PodUsage
+	SetThrottling(&pu, delta)
+	if *pu.Throttling.ThrottledPercent != 10 || *pu.Throttling.PressurePercent != 10 {
+		t.Errorf("expected 10%% throttled and pressure between samples, got %+v", pu.Throttling)
+	}
+
+	restart := tracker.Since("default/api/app", CPUStats{Periods: 20, ThrottledPeriods: 2, RunningSeconds: 2})
+	if restart.Periods != 20 || restart.ThrottledPeriods != 2 {
+		t.Errorf("expected a restarted container's counters unchanged, got %+v", restart)
+	}
+
+	tracker.Prune(func(key string) bool { return false })
+	if again := tracker.Since("default/api/app", CPUStats{Periods: 30}); again.Periods != 30 {
+		t.Errorf("expected a pruned container to start over, got %+v", again)
+	}
+}
+
+func TestCPUStatsAdd(t *testing.T) {
+	waiting := 40.0
+	sum := CPUStats{Periods: 100, ThrottledPeriods: 10}.
+		Add(CPUStats{Periods: 50, ThrottledPeriods: 5, WaitingSeconds: &waiting, RunningSeconds: 200}).
+		Add(CPUStats{WaitingSeconds: &waiting})
+	if sum.Periods != 150 || sum.ThrottledPeriods != 15 || *sum.WaitingSeconds != 40 || sum.RunningSeconds != 200 {
+		t.Errorf("expected pressure summed only where running time is known, got %+v", sum)
+	}
+}
+
+func TestThrottledSortAndFilter(t *testing.T) {
+	throttled := func(name string, percent *float64) PodUsage {
+		return PodUsage{Name: name, Throttling: &Throttling{ThrottledPercent: percent}}
+	}
+	pods := []PodUsage{
+		throttled("calm", floatPtr(0.5)),
+		{Name: "unknown"},
+		throttled("busy", floatPtr(42)),
+		throttled("unlimited", nil),
+	}
+
+	SortPodUsages(pods, SortByThrottled, false)
+	if pods[0].Name != "busy" || pods[1].Name != "calm" {
+		t.Errorf("expected busy then calm, got %v", []string{pods[0].Name, pods[1].Name, pods[2].Name, pods[3].Name})
+	}
+
+	opts := NewFilterOptions()
+	opts.Field = SortByThrottled
+	opts.Above = 10
+	filtered := FilterPodUsages(pods, opts)
+	if len(filtered) != 1 || filtered[0].Name != "busy" {
+		t.Errorf("expected only busy above 10%%, got %+v", filtered)
+	}
+}
+
+// equalPercent reports whether two optional percentages are equal
+func equalPercent(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// fmtPercent formats an optional percentage for test messages
+func fmtPercent(p *float64) any {
+	if p == nil {
+		return "nil"
+	}
+	return *p
+}
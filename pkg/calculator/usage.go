@@ -1,139 +1,375 @@
 package calculator
 
 import (
+	"math"
+	"math/big"
 	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 )
 
-// ResourceUsage represents CPU or Memory usage with requests/limits
+// ResourceUsage represents the usage of one resource with requests/limits
 type ResourceUsage struct {
 	Usage          resource.Quantity
 	Requests       *resource.Quantity
 	Limits         *resource.Quantity
-	RequestPercent *int
-	LimitPercent   *int
+	RequestPercent *float64
+	LimitPercent   *float64
+	RequestSource  ValueSource
+	LimitSource    ValueSource
+
+	// DefaultRequests and DefaultLimits are the LimitRange defaults that apply
+	// to a missing request or limit, whether or not they are assumed; for pods,
+	// the total with the defaults filled in. Nil if no default applies.
+	DefaultRequests *resource.Quantity
+	DefaultLimits   *resource.Quantity
+
+	// UsageUnavailable is set when no source reported the usage; Usage and
+	// the percentages are then N/A
+	UsageUnavailable bool
+}
+
+// ResourceUsages holds resource usage keyed by resource name
+type ResourceUsages map[corev1.ResourceName]ResourceUsage
+
+// ContainerUsage represents resource usage for a single container
+type ContainerUsage struct {
+	Name            string
+	Resources       ResourceUsages
+	Restarts        int32
+	LastTermination *Termination
 }
 
 // PodUsage represents resource usage for a single pod
 type PodUsage struct {
-	Namespace string
-	Name      string
-	Node      string
-	CPU       ResourceUsage
-	Memory    ResourceUsage
+	Cluster    string // kubeconfig context the pod was collected from, empty for a single cluster
+	Namespace  string
+	Name       string
+	Node       string
+	Workload   string
+	Containers []ContainerUsage
+	Status     PodStatus
+
+	// Labels and Annotations are the pod's own, used to group pods
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// Resources holds every registered resource, plus the extended resources
+	// such as GPUs and hugepages the pod's containers set. Resources the
+	// metrics API doesn't report, like ephemeral storage, stay unavailable
+	// until set with SetResourceUsage.
+	Resources ResourceUsages
+
+	// Cost is the estimated monthly cost, nil unless pricing is configured
+	Cost *Cost
+
+	// Throttling is the CPU throttling reported by cadvisor, nil unless collected
+	Throttling *Throttling
+}
+
+// Key identifies a pod across clusters as cluster/namespace/name, or
+// namespace/name when it was collected from a single cluster
+func (pu PodUsage) Key() string {
+	if pu.Cluster == "" {
+		return pu.Namespace + "/" + pu.Name
+	}
+	return pu.Cluster + "/" + pu.Namespace + "/" + pu.Name
 }
 
 // CalculatePercent calculates usage percentage relative to base
-// Returns nil if base is nil or zero
-func CalculatePercent(usage, base *resource.Quantity) *int {
+// Returns nil if base is nil or zero. The ratio is computed exactly, so
+// fractional percentages survive and large quantities cannot overflow.
+func CalculatePercent(usage, base *resource.Quantity) *float64 {
 	if base == nil || base.IsZero() {
 		return nil
 	}
-	percent := int(usage.MilliValue() * 100 / base.MilliValue())
+	ratio := new(big.Rat).Quo(quantityRat(*usage), quantityRat(*base))
+	percent, _ := ratio.Mul(ratio, big.NewRat(100, 1)).Float64()
 	return &percent
 }
 
+// quantityRat returns the exact value of a quantity
+func quantityRat(q resource.Quantity) *big.Rat {
+	// AsDec may convert q's representation; q is a copy
+	d := q.AsDec()
+	r := new(big.Rat).SetInt(d.UnscaledBig())
+	scale := int64(d.Scale())
+	pow := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(max(scale, -scale)), nil))
+	if scale > 0 {
+		return r.Quo(r, pow)
+	}
+	return r.Mul(r, pow)
+}
+
 // CalculatePodUsage calculates resource usage for a pod
 func CalculatePodUsage(podMetric metricsv1beta1.PodMetrics, pod corev1.Pod) PodUsage {
+	return CalculatePodUsageWithDefaults(podMetric, pod, nil)
+}
+
+// CalculatePodUsageWithDefaults calculates resource usage for a pod, reporting
+// the LimitRange defaults for requests and limits its containers do not set
+// and, if the defaults are assumed, computing percentages against them.
+// A nil defaults behaves like CalculatePodUsage.
+func CalculatePodUsageWithDefaults(podMetric metricsv1beta1.PodMetrics, pod corev1.Pod, defaults *LimitRangeDefaults) PodUsage {
 	// Sum up container metrics
-	var totalCPU, totalMem resource.Quantity
+	usage := corev1.ResourceList{}
 	for _, container := range podMetric.Containers {
-		if cpu := container.Usage.Cpu(); cpu != nil {
-			totalCPU.Add(*cpu)
+		for name, q := range container.Usage {
+			total := usage[name]
+			total.Add(q)
+			usage[name] = total
 		}
-		if mem := container.Usage.Memory(); mem != nil {
-			totalMem.Add(*mem)
+	}
+
+	names := append(registeredNames(), extendedResourceNames(pod.Spec.Containers...)...)
+	resources := make(ResourceUsages, len(names))
+	for _, name := range appendUsageNames(names, usage) {
+		total, ok := usage[name]
+		ru := podResourceUsage(total, pod, defaults, name)
+		if !ok {
+			ru = withoutUsage(ru)
 		}
+		resources[name] = ru
 	}
 
-	// Sum up container requests/limits
-	var cpuReq, cpuLim, memReq, memLim resource.Quantity
-	var hasCPUReq, hasCPULim, hasMemReq, hasMemLim bool
+	return PodUsage{
+		Namespace:  podMetric.Namespace,
+		Name:       podMetric.Name,
+		Node:       pod.Spec.NodeName,
+		Workload:   WorkloadName(pod),
+		Containers: calculateContainerUsages(podMetric, pod, defaults),
+		Status:     NewPodStatus(pod),
+
+		Labels:      pod.Labels,
+		Annotations: pod.Annotations,
+		Resources:   resources,
+	}
+}
 
+// appendUsageNames adds the resources reported in usage that are not yet in names
+func appendUsageNames(names []corev1.ResourceName, usage corev1.ResourceList) []corev1.ResourceName {
+	seen := make(map[corev1.ResourceName]bool, len(names))
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, name := range SortedResourceNames(usage) {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// podResourceUsage sums the requests and limits of a pod's containers for one resource
+func podResourceUsage(usage resource.Quantity, pod corev1.Pod, defaults *LimitRangeDefaults, name corev1.ResourceName) ResourceUsage {
+	ru := ResourceUsage{
+		Usage:         usage,
+		RequestSource: SourceNone,
+		LimitSource:   SourceNone,
+	}
+
+	var req, lim, defReq, defLim resource.Quantity
+	var hasReq, hasLim, hasDefReq, hasDefLim bool
 	for _, container := range pod.Spec.Containers {
-		if req, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-			cpuReq.Add(req)
-			hasCPUReq = true
+		q, def, source := resolveValue(container.Resources.Requests, defaults.requests(), defaults.assumed(), name)
+		if q != nil {
+			req.Add(*q)
+			hasReq = true
+		}
+		if def != nil {
+			hasDefReq = true
 		}
-		if lim, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
-			cpuLim.Add(lim)
-			hasCPULim = true
+		if withDefault := firstQuantity(q, def); withDefault != nil {
+			defReq.Add(*withDefault)
 		}
-		if req, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-			memReq.Add(req)
-			hasMemReq = true
+		ru.RequestSource = mergeSource(ru.RequestSource, source)
+
+		q, def, source = resolveValue(container.Resources.Limits, defaults.limits(), defaults.assumed(), name)
+		if q != nil {
+			lim.Add(*q)
+			hasLim = true
+		}
+		if def != nil {
+			hasDefLim = true
 		}
-		if lim, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
-			memLim.Add(lim)
-			hasMemLim = true
+		if withDefault := firstQuantity(q, def); withDefault != nil {
+			defLim.Add(*withDefault)
 		}
+		ru.LimitSource = mergeSource(ru.LimitSource, source)
 	}
 
-	// Build ResourceUsage for CPU
-	cpuUsage := ResourceUsage{
-		Usage: totalCPU,
+	if hasDefReq {
+		ru.DefaultRequests = &defReq
+	}
+	if hasDefLim {
+		ru.DefaultLimits = &defLim
+	}
+	if hasReq {
+		ru.Requests = &req
+		ru.RequestPercent = CalculatePercent(&ru.Usage, &req)
 	}
-	if hasCPUReq {
-		cpuUsage.Requests = &cpuReq
-		cpuUsage.RequestPercent = CalculatePercent(&totalCPU, &cpuReq)
+	if hasLim {
+		ru.Limits = &lim
+		ru.LimitPercent = CalculatePercent(&ru.Usage, &lim)
 	}
-	if hasCPULim {
-		cpuUsage.Limits = &cpuLim
-		cpuUsage.LimitPercent = CalculatePercent(&totalCPU, &cpuLim)
+	return ru
+}
+
+// firstQuantity returns the first of the quantities that is not nil
+func firstQuantity(qs ...*resource.Quantity) *resource.Quantity {
+	for _, q := range qs {
+		if q != nil {
+			return q
+		}
 	}
+	return nil
+}
 
-	// Build ResourceUsage for Memory
-	memUsage := ResourceUsage{
-		Usage: totalMem,
+// WorkloadName returns the workload owning a pod as Kind/name.
+// Pods owned by a ReplicaSet are attributed to its Deployment using the
+// pod-template-hash label; pods without a controller are their own workload.
+func WorkloadName(pod corev1.Pod) string {
+	owner := metav1.GetControllerOf(&pod)
+	if owner == nil {
+		return "Pod/" + pod.Name
 	}
-	if hasMemReq {
-		memUsage.Requests = &memReq
-		memUsage.RequestPercent = CalculatePercent(&totalMem, &memReq)
+	if owner.Kind == "ReplicaSet" {
+		if hash, ok := pod.Labels["pod-template-hash"]; ok && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment/" + strings.TrimSuffix(owner.Name, "-"+hash)
+		}
 	}
-	if hasMemLim {
-		memUsage.Limits = &memLim
-		memUsage.LimitPercent = CalculatePercent(&totalMem, &memLim)
+	return owner.Kind + "/" + owner.Name
+}
+
+// calculateContainerUsages calculates resource usage for each container reported by metrics
+func calculateContainerUsages(podMetric metricsv1beta1.PodMetrics, pod corev1.Pod, defaults *LimitRangeDefaults) []ContainerUsage {
+	specs := make(map[string]corev1.Container, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		specs[container.Name] = container
 	}
 
-	return PodUsage{
-		Namespace: podMetric.Namespace,
-		Name:      podMetric.Name,
-		Node:      pod.Spec.NodeName,
-		CPU:       cpuUsage,
-		Memory:    memUsage,
+	statuses := containerStatuses(pod)
+
+	containers := make([]ContainerUsage, 0, len(podMetric.Containers))
+	for _, cm := range podMetric.Containers {
+		spec := specs[cm.Name]
+		status := statuses[cm.Name]
+
+		names := append(registeredNames(), extendedResourceNames(spec)...)
+		resources := make(ResourceUsages, len(names))
+		for _, name := range appendUsageNames(names, cm.Usage) {
+			ru := newResourceUsage(cm.Usage, spec.Resources, defaults, name)
+			if _, ok := cm.Usage[name]; !ok {
+				ru = withoutUsage(ru)
+			}
+			resources[name] = ru
+		}
+
+		containers = append(containers, ContainerUsage{
+			Name:            cm.Name,
+			Resources:       resources,
+			Restarts:        status.RestartCount,
+			LastTermination: lastTermination(status),
+		})
+	}
+	return containers
+}
+
+// newResourceUsage builds the ResourceUsage of a single resource for one container
+func newResourceUsage(usage corev1.ResourceList, resources corev1.ResourceRequirements, defaults *LimitRangeDefaults, name corev1.ResourceName) ResourceUsage {
+	var ru ResourceUsage
+	if u, ok := usage[name]; ok {
+		ru.Usage = u.DeepCopy()
+	}
+	ru.Requests, ru.DefaultRequests, ru.RequestSource = resolveValue(resources.Requests, defaults.requests(), defaults.assumed(), name)
+	if ru.Requests != nil {
+		ru.RequestPercent = CalculatePercent(&ru.Usage, ru.Requests)
+	}
+	ru.Limits, ru.DefaultLimits, ru.LimitSource = resolveValue(resources.Limits, defaults.limits(), defaults.assumed(), name)
+	if ru.Limits != nil {
+		ru.LimitPercent = CalculatePercent(&ru.Usage, ru.Limits)
 	}
+	return ru
+}
+
+// Sort fields accepted by SortPodUsages besides the registered resources,
+// which sort by their limit percentage
+const (
+	SortByRestarts = "restarts" // total container restarts
+	SortByOOM      = "oom"      // time of the most recent OOM kill
+)
+
+// SortFields returns the fields accepted by SortPodUsages
+func SortFields() []string {
+	return append(append(ResourceNames(), SortByRestarts, SortByOOM, SortByThrottled), CostSortFields()...)
 }
 
 // SortPodUsages sorts pod usages by the specified field
-// field can be "cpu" or "memory"
+// field is one of SortFields; unknown fields sort by memory
 // N/A values are sorted to the end
 func SortPodUsages(pods []PodUsage, field string, ascending bool) {
-	sort.Slice(pods, func(i, j int) bool {
-		var vi, vj *int
-
-		if field == "cpu" {
-			vi, vj = pods[i].CPU.LimitPercent, pods[j].CPU.LimitPercent
-		} else {
-			vi, vj = pods[i].Memory.LimitPercent, pods[j].Memory.LimitPercent
-		}
+	sort.SliceStable(pods, func(i, j int) bool {
+		vi, oki := sortKey(pods[i], field)
+		vj, okj := sortKey(pods[j], field)
 
 		// N/A values go to the end
-		if vi == nil && vj == nil {
+		if !oki && !okj {
 			return false
 		}
-		if vi == nil {
+		if !oki {
 			return false // i goes after j
 		}
-		if vj == nil {
+		if !okj {
 			return true // j goes after i
 		}
 
 		if ascending {
-			return *vi < *vj
+			return vi < vj
 		}
-		return *vi > *vj
+		return vi > vj
 	})
 }
+
+// sortKey returns the value a pod is sorted by, and false if it is N/A
+func sortKey(pod PodUsage, field string) (int64, bool) {
+	switch field {
+	case SortByRestarts:
+		return int64(pod.Status.Restarts), true
+	case SortByOOM:
+		if pod.Status.LastOOMKill == nil {
+			return 0, false
+		}
+		return pod.Status.LastOOMKill.FinishedAt.UnixNano(), true
+	case SortByCost, SortByUsageCost, SortByIdleCost:
+		return costKey(pod, field)
+	case SortByThrottled:
+		return percentKey(pod.throttledPercent())
+	default:
+		return percentKey(pod.Resources[fieldResource(field)].LimitPercent)
+	}
+}
+
+// percentKey converts an optional percentage into a sort key in millionths
+// of a percent, so fractional percentages keep their order
+func percentKey(p *float64) (int64, bool) {
+	if p == nil {
+		return 0, false
+	}
+	return int64(math.Min(*p*1e6, maxPercentKey)), true
+}
+
+// maxPercentKey caps percentage sort keys below the int64 range
+const maxPercentKey = 1 << 62
+
+// fieldResource resolves a filter or sort field to a registered resource,
+// falling back to memory
+func fieldResource(field string) corev1.ResourceName {
+	if def, ok := LookupResource(field); ok {
+		return def.Name
+	}
+	return corev1.ResourceMemory
+}
@@ -14,19 +14,19 @@ func TestCalculatePercent(t *testing.T) {
 		name     string
 		usage    *resource.Quantity
 		base     *resource.Quantity
-		expected *int
+		expected *float64
 	}{
 		{
 			name:     "normal calculation",
 			usage:    resourcePtr(resource.MustParse("500m")),
 			base:     resourcePtr(resource.MustParse("1000m")),
-			expected: intPtr(50),
+			expected: floatPtr(50),
 		},
 		{
 			name:     "over 100%",
 			usage:    resourcePtr(resource.MustParse("2000m")),
 			base:     resourcePtr(resource.MustParse("1000m")),
-			expected: intPtr(200),
+			expected: floatPtr(200),
 		},
 		{
 			name:     "nil base returns nil",
@@ -44,7 +44,31 @@ func TestCalculatePercent(t *testing.T) {
 			name:     "memory calculation",
 			usage:    resourcePtr(resource.MustParse("256Mi")),
 			base:     resourcePtr(resource.MustParse("512Mi")),
-			expected: intPtr(50),
+			expected: floatPtr(50),
+		},
+		{
+			name:     "fractional percentage",
+			usage:    resourcePtr(resource.MustParse("4m")),
+			base:     resourcePtr(resource.MustParse("1")),
+			expected: floatPtr(0.4),
+		},
+		{
+			name:     "decimal usage is exact",
+			usage:    resourcePtr(resource.MustParse("290m")),
+			base:     resourcePtr(resource.MustParse("1")),
+			expected: floatPtr(29),
+		},
+		{
+			name:     "tiny request",
+			usage:    resourcePtr(resource.MustParse("250m")),
+			base:     resourcePtr(resource.MustParse("1m")),
+			expected: floatPtr(25000),
+		},
+		{
+			name:     "huge quantities do not overflow",
+			usage:    resourcePtr(resource.MustParse("4E")),
+			base:     resourcePtr(resource.MustParse("8E")),
+			expected: floatPtr(50),
 		},
 	}
 
@@ -53,13 +77,13 @@ func TestCalculatePercent(t *testing.T) {
 			result := CalculatePercent(tt.usage, tt.base)
 			if tt.expected == nil {
 				if result != nil {
-					t.Errorf("expected nil, got %d", *result)
+					t.Errorf("expected nil, got %v", *result)
 				}
 			} else {
 				if result == nil {
-					t.Errorf("expected %d, got nil", *tt.expected)
+					t.Errorf("expected %v, got nil", *tt.expected)
 				} else if *result != *tt.expected {
-					t.Errorf("expected %d, got %d", *tt.expected, *result)
+					t.Errorf("expected %v, got %v", *tt.expected, *result)
 				}
 			}
 		})
@@ -121,19 +145,21 @@ func TestCalculatePodUsage(t *testing.T) {
 	}
 
 	// CPU: 100m / 200m = 50% request, 100m / 500m = 20% limit
-	if result.CPU.RequestPercent == nil || *result.CPU.RequestPercent != 50 {
-		t.Errorf("expected CPU request percent 50, got %v", result.CPU.RequestPercent)
+	cpu := result.Resources[corev1.ResourceCPU]
+	if cpu.RequestPercent == nil || *cpu.RequestPercent != 50 {
+		t.Errorf("expected CPU request percent 50, got %v", cpu.RequestPercent)
 	}
-	if result.CPU.LimitPercent == nil || *result.CPU.LimitPercent != 20 {
-		t.Errorf("expected CPU limit percent 20, got %v", result.CPU.LimitPercent)
+	if cpu.LimitPercent == nil || *cpu.LimitPercent != 20 {
+		t.Errorf("expected CPU limit percent 20, got %v", cpu.LimitPercent)
 	}
 
 	// Memory: 128Mi / 256Mi = 50% request, 128Mi / 512Mi = 25% limit
-	if result.Memory.RequestPercent == nil || *result.Memory.RequestPercent != 50 {
-		t.Errorf("expected Memory request percent 50, got %v", result.Memory.RequestPercent)
+	memory := result.Resources[corev1.ResourceMemory]
+	if memory.RequestPercent == nil || *memory.RequestPercent != 50 {
+		t.Errorf("expected Memory request percent 50, got %v", memory.RequestPercent)
 	}
-	if result.Memory.LimitPercent == nil || *result.Memory.LimitPercent != 25 {
-		t.Errorf("expected Memory limit percent 25, got %v", result.Memory.LimitPercent)
+	if memory.LimitPercent == nil || *memory.LimitPercent != 25 {
+		t.Errorf("expected Memory limit percent 25, got %v", memory.LimitPercent)
 	}
 }
 
@@ -172,26 +198,172 @@ func TestCalculatePodUsageNoLimits(t *testing.T) {
 
 	result := CalculatePodUsage(podMetric, pod)
 
-	if result.CPU.RequestPercent != nil {
-		t.Errorf("expected nil CPU request percent, got %d", *result.CPU.RequestPercent)
+	cpu := result.Resources[corev1.ResourceCPU]
+	if cpu.RequestPercent != nil {
+		t.Errorf("expected nil CPU request percent, got %v", *cpu.RequestPercent)
 	}
-	if result.CPU.LimitPercent != nil {
-		t.Errorf("expected nil CPU limit percent, got %d", *result.CPU.LimitPercent)
+	if cpu.LimitPercent != nil {
+		t.Errorf("expected nil CPU limit percent, got %v", *cpu.LimitPercent)
 	}
-	if result.Memory.RequestPercent != nil {
-		t.Errorf("expected nil Memory request percent, got %d", *result.Memory.RequestPercent)
+	memory := result.Resources[corev1.ResourceMemory]
+	if memory.RequestPercent != nil {
+		t.Errorf("expected nil Memory request percent, got %v", *memory.RequestPercent)
 	}
-	if result.Memory.LimitPercent != nil {
-		t.Errorf("expected nil Memory limit percent, got %d", *result.Memory.LimitPercent)
+	if memory.LimitPercent != nil {
+		t.Errorf("expected nil Memory limit percent, got %v", *memory.LimitPercent)
+	}
+}
+
+func TestCalculatePodUsageContainers(t *testing.T) {
+	podMetric := metricsv1beta1.PodMetrics{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Containers: []metricsv1beta1.ContainerMetrics{
+			{
+				Name: "app",
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("300m"),
+					corev1.ResourceMemory: resource.MustParse("384Mi"),
+				},
+			},
+			{
+				Name: "sidecar",
+				Usage: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("10m"),
+					corev1.ResourceMemory: resource.MustParse("16Mi"),
+				},
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("1"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+				{
+					Name: "sidecar",
+				},
+			},
+		},
+	}
+
+	result := CalculatePodUsage(podMetric, pod)
+
+	if len(result.Containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(result.Containers))
+	}
+
+	app := result.Containers[0]
+	if app.Name != "app" {
+		t.Errorf("expected container 'app', got '%s'", app.Name)
+	}
+	// CPU: 300m / 1 = 30% request, no limit
+	appCPU := app.Resources[corev1.ResourceCPU]
+	if appCPU.RequestPercent == nil || *appCPU.RequestPercent != 30 {
+		t.Errorf("expected app CPU request percent 30, got %v", appCPU.RequestPercent)
+	}
+	if appCPU.LimitPercent != nil {
+		t.Errorf("expected nil app CPU limit percent, got %v", *appCPU.LimitPercent)
+	}
+	// Memory: 384Mi / 512Mi = 75% limit
+	appMemory := app.Resources[corev1.ResourceMemory]
+	if appMemory.LimitPercent == nil || *appMemory.LimitPercent != 75 {
+		t.Errorf("expected app memory limit percent 75, got %v", appMemory.LimitPercent)
+	}
+
+	sidecar := result.Containers[1]
+	sidecarCPU := sidecar.Resources[corev1.ResourceCPU]
+	if sidecarCPU.Usage.MilliValue() != 10 {
+		t.Errorf("expected sidecar CPU usage 10m, got %s", sidecarCPU.Usage.String())
+	}
+	sidecarMemory := sidecar.Resources[corev1.ResourceMemory]
+	if sidecarMemory.Requests != nil || sidecarMemory.Limits != nil {
+		t.Errorf("expected sidecar without memory requests/limits")
+	}
+}
+
+func TestWorkloadName(t *testing.T) {
+	controller := true
+	tests := []struct {
+		name   string
+		labels map[string]string
+		owners []metav1.OwnerReference
+		want   string
+	}{
+		{
+			name:   "deployment via replicaset",
+			labels: map[string]string{"pod-template-hash": "5d8f7c9b6"},
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-5d8f7c9b6", Controller: &controller}},
+			want:   "Deployment/api",
+		},
+		{
+			name:   "bare replicaset",
+			owners: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "legacy", Controller: &controller}},
+			want:   "ReplicaSet/legacy",
+		},
+		{
+			name:   "statefulset",
+			owners: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &controller}},
+			want:   "StatefulSet/db",
+		},
+		{
+			name:   "non-controller owner",
+			owners: []metav1.OwnerReference{{Kind: "ConfigMap", Name: "cfg"}},
+			want:   "Pod/test-pod",
+		},
+		{
+			name: "no owner",
+			want: "Pod/test-pod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "test-pod",
+					Labels:          tt.labels,
+					OwnerReferences: tt.owners,
+				},
+			}
+			if got := WorkloadName(pod); got != tt.want {
+				t.Errorf("WorkloadName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPodUsageKey(t *testing.T) {
+	if got := (PodUsage{Namespace: "default", Name: "api"}).Key(); got != "default/api" {
+		t.Errorf("expected default/api, got %q", got)
+	}
+	if got := (PodUsage{Cluster: "prod-eu", Namespace: "default", Name: "api"}).Key(); got != "prod-eu/default/api" {
+		t.Errorf("expected prod-eu/default/api, got %q", got)
 	}
 }
 
 func TestSortPodUsages(t *testing.T) {
 	pods := []PodUsage{
-		{Name: "pod1", CPU: ResourceUsage{LimitPercent: intPtr(50)}, Memory: ResourceUsage{LimitPercent: intPtr(30)}},
-		{Name: "pod2", CPU: ResourceUsage{LimitPercent: intPtr(80)}, Memory: ResourceUsage{LimitPercent: intPtr(60)}},
-		{Name: "pod3", CPU: ResourceUsage{LimitPercent: nil}, Memory: ResourceUsage{LimitPercent: nil}},
-		{Name: "pod4", CPU: ResourceUsage{LimitPercent: intPtr(20)}, Memory: ResourceUsage{LimitPercent: intPtr(90)}},
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(50)}, corev1.ResourceMemory: {LimitPercent: floatPtr(30)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(80)}, corev1.ResourceMemory: {LimitPercent: floatPtr(60)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: nil}, corev1.ResourceMemory: {LimitPercent: nil}}},
+		{Name: "pod4", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(20)}, corev1.ResourceMemory: {LimitPercent: floatPtr(90)}}},
 	}
 
 	// Sort by CPU descending
@@ -207,8 +379,22 @@ func TestSortPodUsages(t *testing.T) {
 	}
 }
 
-func intPtr(i int) *int {
-	return &i
+func TestSortPodUsages_FractionalPercentages(t *testing.T) {
+	pods := []PodUsage{
+		{Name: "pod1", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(0.4)}}},
+		{Name: "pod2", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(0.04)}}},
+		{Name: "pod3", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(0.9)}}},
+		{Name: "pod4", Resources: ResourceUsages{corev1.ResourceCPU: {LimitPercent: floatPtr(1e300)}}},
+	}
+
+	SortPodUsages(pods, "cpu", true)
+	if pods[0].Name != "pod2" || pods[1].Name != "pod1" || pods[2].Name != "pod3" || pods[3].Name != "pod4" {
+		t.Errorf("expected percentages below 1%% to keep their order, got %v", []string{pods[0].Name, pods[1].Name, pods[2].Name, pods[3].Name})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
 }
 
 func resourcePtr(r resource.Quantity) *resource.Quantity {
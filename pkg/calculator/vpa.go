@@ -0,0 +1,188 @@
+package calculator
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Drift is where a container's requests sit against a VPA recommendation
+type Drift string
+
+// Drift states. VPA only updates requests outside the bounds, so requests
+// between them count as matching the recommendation.
+const (
+	DriftOK        Drift = "ok"
+	DriftUnder     Drift = "under"      // requests below the lower bound
+	DriftOver      Drift = "over"       // requests above the upper bound
+	DriftNoRequest Drift = "no-request" // the container requests none of the resource
+)
+
+// VPA is a VerticalPodAutoscaler's scale target and recommendations
+type VPA struct {
+	Namespace       string
+	Name            string
+	Target          string // scale target as Kind/name, matching PodUsage.Workload
+	UpdateMode      string
+	Recommendations []ContainerRecommendation
+}
+
+// ContainerRecommendation is the resources a VPA recommends for one container
+type ContainerRecommendation struct {
+	Container      string
+	Target         corev1.ResourceList
+	LowerBound     corev1.ResourceList
+	UpperBound     corev1.ResourceList
+	UncappedTarget corev1.ResourceList
+}
+
+// VPAUsage compares a VPA's recommendations with the requests and usage of the pods it controls
+type VPAUsage struct {
+	Namespace  string
+	Name       string
+	Target     string
+	UpdateMode string
+	Containers []VPAContainerUsage // sorted by container name
+}
+
+// VPAContainerUsage compares one container's recommendation with its requests and usage
+type VPAContainerUsage struct {
+	Container string
+	Pods      int                // pods of the target running the container
+	Resources []VPAResourceUsage // recommended resources, sorted by name
+}
+
+// VPAResourceUsage is one recommended resource next to the container's requests and usage,
+// averaged across the pods running it
+type VPAResourceUsage struct {
+	Resource       corev1.ResourceName
+	Requests       *resource.Quantity // nil if the container requests none
+	Usage          *resource.Quantity // nil if no pod reports usage
+	Target         resource.Quantity
+	LowerBound     *resource.Quantity
+	UpperBound     *resource.Quantity
+	UncappedTarget *resource.Quantity
+
+	// DriftPercent is how far Requests are from Target, relative to Target:
+	// positive when over-requested. nil without requests or a zero target.
+	DriftPercent *float64
+	Drift        Drift
+}
+
+// CalculateVPAUsages matches each VPA to the pods of its target and compares
+// every recommended container resource with the containers' requests and
+// usage. VPAs without recommendations yet are reported without containers.
+// Results are sorted by namespace and name.
+func CalculateVPAUsages(vpas []VPA, pods []PodUsage) []VPAUsage {
+	byWorkload := make(map[string][]PodUsage)
+	for _, pu := range pods {
+		key := pu.Namespace + "/" + pu.Workload
+		byWorkload[key] = append(byWorkload[key], pu)
+	}
+
+	usages := make([]VPAUsage, 0, len(vpas))
+	for _, vpa := range vpas {
+		usage := VPAUsage{Namespace: vpa.Namespace, Name: vpa.Name, Target: vpa.Target, UpdateMode: vpa.UpdateMode}
+		targetPods := byWorkload[vpa.Namespace+"/"+vpa.Target]
+		for _, rec := range vpa.Recommendations {
+			usage.Containers = append(usage.Containers, containerVPAUsage(rec, targetPods))
+		}
+		sort.Slice(usage.Containers, func(i, j int) bool {
+			return usage.Containers[i].Container < usage.Containers[j].Container
+		})
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Namespace != usages[j].Namespace {
+			return usages[i].Namespace < usages[j].Namespace
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
+
+// containerVPAUsage compares a container recommendation with the container in the given pods
+func containerVPAUsage(rec ContainerRecommendation, pods []PodUsage) VPAContainerUsage {
+	var containers []ContainerUsage
+	for _, pu := range pods {
+		for _, c := range pu.Containers {
+			if c.Name == rec.Container {
+				containers = append(containers, c)
+			}
+		}
+	}
+
+	names := make(map[corev1.ResourceName]bool, len(rec.Target))
+	for name := range rec.Target {
+		names[name] = true
+	}
+
+	usage := VPAContainerUsage{Container: rec.Container, Pods: len(containers)}
+	for _, name := range SortedResourceNames(names) {
+		ru := VPAResourceUsage{
+			Resource:       name,
+			Target:         rec.Target[name],
+			LowerBound:     quantityOf(rec.LowerBound, name),
+			UpperBound:     quantityOf(rec.UpperBound, name),
+			UncappedTarget: quantityOf(rec.UncappedTarget, name),
+		}
+		var requests, used []resource.Quantity
+		for _, c := range containers {
+			r := c.Resources[name]
+			if r.Requests != nil {
+				requests = append(requests, *r.Requests)
+			}
+			if !r.UsageUnavailable {
+				used = append(used, r.Usage)
+			}
+		}
+		ru.Requests = averageQuantity(requests)
+		ru.Usage = averageQuantity(used)
+		ru.DriftPercent, ru.Drift = drift(ru)
+		usage.Resources = append(usage.Resources, ru)
+	}
+	return usage
+}
+
+// drift compares the requests with the recommended target and bounds
+func drift(ru VPAResourceUsage) (*float64, Drift) {
+	if ru.Requests == nil {
+		return nil, DriftNoRequest
+	}
+	var percent *float64
+	if p := CalculatePercent(ru.Requests, &ru.Target); p != nil {
+		d := *p - 100
+		percent = &d
+	}
+	switch {
+	case ru.LowerBound != nil && ru.Requests.Cmp(*ru.LowerBound) < 0:
+		return percent, DriftUnder
+	case ru.UpperBound != nil && ru.Requests.Cmp(*ru.UpperBound) > 0:
+		return percent, DriftOver
+	default:
+		return percent, DriftOK
+	}
+}
+
+// quantityOf returns a copy of a resource's quantity in the list, nil if absent
+func quantityOf(list corev1.ResourceList, name corev1.ResourceName) *resource.Quantity {
+	q, ok := list[name]
+	if !ok {
+		return nil
+	}
+	return &q
+}
+
+// averageQuantity returns the mean of the quantities in millis, nil if there are none
+func averageQuantity(quantities []resource.Quantity) *resource.Quantity {
+	if len(quantities) == 0 {
+		return nil
+	}
+	var total resource.Quantity
+	for _, q := range quantities {
+		total.Add(q)
+	}
+	return resource.NewMilliQuantity(total.MilliValue()/int64(len(quantities)), quantities[0].Format)
+}
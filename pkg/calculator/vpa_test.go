@@ -0,0 +1,109 @@
+package calculator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCalculateVPAUsages(t *testing.T) {
+	list := func(cpu, memory string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+	}
+	vpas := []VPA{
+		{Namespace: "payment", Name: "worker", Target: "StatefulSet/worker", UpdateMode: "Auto"},
+		{
+			Namespace: "payment", Name: "api", Target: "Deployment/api", UpdateMode: "Off",
+			Recommendations: []ContainerRecommendation{
+				{Container: "sidecar", Target: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")}},
+				{
+					Container:      "app",
+					Target:         list("250m", "256Mi"),
+					LowerBound:     list("200m", "128Mi"),
+					UpperBound:     list("400m", "384Mi"),
+					UncappedTarget: list("300m", "256Mi"),
+				},
+			},
+		},
+	}
+	pod := func(workload, cpuUsage string) PodUsage {
+		return PodUsage{
+			Namespace: "payment",
+			Workload:  workload,
+			Containers: []ContainerUsage{{
+				Name: "app",
+				Resources: ResourceUsages{
+					corev1.ResourceCPU:    {Usage: resource.MustParse(cpuUsage), Requests: quantityPtr("100m")},
+					corev1.ResourceMemory: {Usage: resource.MustParse("200Mi"), Requests: quantityPtr("1Gi")},
+				},
+			}},
+		}
+	}
+	pods := []PodUsage{pod("Deployment/api", "100m"), pod("Deployment/api", "200m"), pod("Deployment/web", "1")}
+
+	usages := CalculateVPAUsages(vpas, pods)
+	if len(usages) != 2 || usages[0].Name != "api" || usages[1].Name != "worker" {
+		t.Fatalf("expected api then worker, got %+v", usages)
+	}
+	if len(usages[1].Containers) != 0 {
+		t.Errorf("expected no containers without recommendations, got %+v", usages[1].Containers)
+	}
+
+	api := usages[0]
+	if len(api.Containers) != 2 || api.Containers[0].Container != "app" || api.Containers[0].Pods != 2 {
+		t.Fatalf("expected app on 2 pods then sidecar, got %+v", api.Containers)
+	}
+
+	app := api.Containers[0].Resources
+	if len(app) != 2 || app[0].Resource != corev1.ResourceCPU {
+		t.Fatalf("expected cpu then memory, got %+v", app)
+	}
+	cpu, memory := app[0], app[1]
+	if cpu.Usage == nil || cpu.Usage.MilliValue() != 150 {
+		t.Errorf("expected an average cpu usage of 150m, got %v", cpu.Usage)
+	}
+	// 100m requested against a 250m target, below the 200m lower bound
+	if cpu.Drift != DriftUnder || !equalPercent(cpu.DriftPercent, floatPtr(-60)) {
+		t.Errorf("expected cpu under by -60%%, got %s %v", cpu.Drift, fmtPercent(cpu.DriftPercent))
+	}
+	if cpu.UncappedTarget == nil || cpu.UncappedTarget.MilliValue() != 300 {
+		t.Errorf("expected an uncapped target of 300m, got %v", cpu.UncappedTarget)
+	}
+	// 1Gi requested against a 256Mi target, above the 384Mi upper bound
+	if memory.Drift != DriftOver || !equalPercent(memory.DriftPercent, floatPtr(300)) {
+		t.Errorf("expected memory over by 300%%, got %s %v", memory.Drift, fmtPercent(memory.DriftPercent))
+	}
+
+	sidecar := api.Containers[1]
+	if sidecar.Pods != 0 || len(sidecar.Resources) != 1 || sidecar.Resources[0].Drift != DriftNoRequest || sidecar.Resources[0].Usage != nil {
+		t.Errorf("expected sidecar without pods, requests or usage, got %+v", sidecar)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests string
+		want     Drift
+	}{
+		{"within bounds", "300m", DriftOK},
+		{"at the lower bound", "200m", DriftOK},
+		{"below the lower bound", "199m", DriftUnder},
+		{"above the upper bound", "401m", DriftOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ru := VPAResourceUsage{
+				Requests:   quantityPtr(tt.requests),
+				Target:     resource.MustParse("250m"),
+				LowerBound: quantityPtr("200m"),
+				UpperBound: quantityPtr("400m"),
+			}
+			if _, got := drift(ru); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/audit"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// AuditOptions contains the options for the audit command
+type AuditOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	selector         string
+	output           string
+	maxMemoryRatio   float64
+	latencySensitive []string
+	production       []string
+}
+
+// auditReport is the structured output of the audit command
+type auditReport struct {
+	Findings []audit.Finding `json:"findings" yaml:"findings"`
+}
+
+// NewAuditOptions creates a new AuditOptions with default values
+func NewAuditOptions(streams genericclioptions.IOStreams) *AuditOptions {
+	return &AuditOptions{
+		configFlags:    genericclioptions.NewConfigFlags(true),
+		IOStreams:      streams,
+		output:         "table",
+		maxMemoryRatio: 2,
+	}
+}
+
+// NewCmdAudit creates the audit command
+func NewCmdAudit(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewAuditOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audit container resource configuration",
+		Long: `Check every container's requests and limits against configuration rules.
+Each finding has a rule ID, a severity and a remediation hint. Audit reads
+pod specs only, so it works without metrics-server.`,
+		Example: `  # Audit all namespaces
+  kubectl resource-usage audit
+
+  # Treat prod as production and checkout as latency-sensitive
+  kubectl resource-usage audit --production-namespaces prod --latency-sensitive-namespaces checkout
+
+  # Flag memory limits more than 1.5x the request, as JSON
+  kubectl resource-usage audit --max-memory-ratio 1.5 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter by label selector (e.g., app=api)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, json, or yaml")
+	cmd.Flags().Float64Var(&o.maxMemoryRatio, "max-memory-ratio", o.maxMemoryRatio, "Maximum memory limit/request ratio (0 disables the check)")
+	cmd.Flags().StringSliceVar(&o.latencySensitive, "latency-sensitive-namespaces", nil, "Namespaces where CPU limits are reported")
+	cmd.Flags().StringSliceVar(&o.production, "production-namespaces", nil, "Namespaces where BestEffort pods are reported")
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *AuditOptions) Validate() error {
+	if o.selector != "" {
+		if _, err := labels.Parse(o.selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', or 'yaml')", o.output)
+	}
+	if o.maxMemoryRatio != 0 && o.maxMemoryRatio < 1 {
+		return fmt.Errorf("invalid --max-memory-ratio: %g (must be 0 or at least 1)", o.maxMemoryRatio)
+	}
+	return nil
+}
+
+// Run lists pods and nodes, audits them and prints the findings
+func (o *AuditOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil && *o.configFlags.Namespace != "" {
+		namespace = *o.configFlags.Namespace
+	}
+
+	podCollector, err := collector.NewPodCollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create pod collector: %w", err)
+	}
+
+	nodeCollector, err := collector.NewNodeCollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create node collector: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	pods, err := podCollector.GetPods(ctx, namespace, o.selector)
+	if err != nil {
+		return fmt.Errorf("failed to get pods: %w", err)
+	}
+
+	// Node access is often restricted; audit without RU007 rather than failing
+	var nodes []corev1.Node
+	if nodeList, err := nodeCollector.GetNodes(ctx, ""); err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "Warning: skipping node allocatable check: %v\n", err)
+	} else {
+		nodes = nodeList.Items
+	}
+
+	findings := audit.Audit(pods.Items, nodes, audit.Options{
+		MaxMemoryLimitRatio:        o.maxMemoryRatio,
+		LatencySensitiveNamespaces: o.latencySensitive,
+		ProductionNamespaces:       o.production,
+	})
+	return o.writeFindings(o.Out, findings)
+}
+
+// writeFindings writes the findings in the selected output format
+func (o *AuditOptions) writeFindings(w io.Writer, findings []audit.Finding) (err error) {
+	report := auditReport{Findings: findings}
+	if report.Findings == nil {
+		report.Findings = []audit.Finding{}
+	}
+
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer func() {
+			if closeErr := enc.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		return enc.Encode(report)
+	}
+
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No findings")
+		return err
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, "SEVERITY\tRULE\tNAMESPACE\tPOD\tCONTAINER\tFINDING")
+	for _, f := range findings {
+		container := f.Container
+		if container == "" {
+			container = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Severity, f.RuleID, f.Namespace, f.Pod, container, f.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Print each remediation once, for the rules that produced findings
+	found := map[string]bool{}
+	for _, f := range findings {
+		found[f.RuleID] = true
+	}
+	_, _ = fmt.Fprintln(w, "\nRemediation:")
+	for _, rule := range audit.Rules() {
+		if found[rule.ID] {
+			_, _ = fmt.Fprintf(w, "  %s %s: %s\n", rule.ID, rule.Description, rule.Remediation)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/audit"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestAuditOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		ratio    float64
+		selector string
+		errMsg   string
+	}{
+		{name: "defaults", output: "table", ratio: 2},
+		{name: "ratio check disabled", output: "json", ratio: 0},
+		{name: "invalid output", output: "wide", ratio: 2, errMsg: "invalid output format"},
+		{name: "ratio below one", output: "table", ratio: 0.5, errMsg: "invalid --max-memory-ratio"},
+		{name: "invalid selector", output: "table", ratio: 2, selector: "app in (", errMsg: "invalid label selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewAuditOptions(genericclioptions.IOStreams{})
+			o.output = tt.output
+			o.maxMemoryRatio = tt.ratio
+			o.selector = tt.selector
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestAuditOptions_WriteFindings(t *testing.T) {
+	findings := []audit.Finding{
+		{
+			RuleID:      audit.RuleMissingLimits.ID,
+			Severity:    audit.RuleMissingLimits.Severity,
+			Namespace:   "default",
+			Pod:         "api",
+			Container:   "app",
+			Message:     "no memory limits",
+			Remediation: audit.RuleMissingLimits.Remediation,
+		},
+	}
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewAuditOptions(streams)
+	if err := o.writeFindings(out, findings); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"SEVERITY", "RU002", "no memory limits", "Remediation:", audit.RuleMissingLimits.Remediation} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
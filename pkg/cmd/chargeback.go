@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// NewChargebackOptions creates CostOptions for the chargeback command, which
+// reports requests and usage without pricing too
+func NewChargebackOptions(streams genericclioptions.IOStreams) *CostOptions {
+	o := NewCostOptions(streams)
+	o.requirePricing = false
+	return o
+}
+
+// NewCmdChargeback creates the chargeback command
+func NewCmdChargeback(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewChargebackOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "chargeback",
+		Short: "Report requests, usage and monthly cost per namespace, workload, pod, label or annotation",
+		Long: `Aggregate the CPU and memory requested and used by each namespace, workload,
+pod, or value of a pod label or annotation, for chargeback to the teams
+owning them. Label and annotation groupings fall back to the namespace's
+label or annotation, and pods without either are reported as <none>.
+
+With a pricing file, also price the requests and usage, and show the idle
+cost of the requests left unused. Rates can differ per node label, such as
+the instance type or spot capacity. Costs are projected over a 730-hour month.`,
+		Example: `  # Requests and usage per team label
+  kubectl resource-usage chargeback --group-by label:team
+
+  # Monthly cost per team as CSV for a spreadsheet
+  kubectl resource-usage chargeback --group-by label:team --pricing pricing.yaml -o csv
+
+  # Workloads wasting the most money in a namespace
+  kubectl resource-usage chargeback --pricing pricing.yaml -n payment --group-by workload --sort idle-cost
+
+  # Cost centers from a namespace annotation, as markdown
+  kubectl resource-usage chargeback --group-by annotation:example.com/cost-center --pricing pricing.yaml -o markdown`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.runCommand(cmd)
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter pods by label selector (e.g., app=api)")
+	cmd.Flags().StringVar(&o.pricingFile, "pricing", "", "YAML file with per-core-hour and per-GiB-hour rates; adds monthly costs")
+	cmd.Flags().StringVar(&o.groupBy, "group-by", o.groupBy, fmt.Sprintf("Group by: %s", strings.Join(calculator.GroupByFields(), ", ")))
+	cmd.Flags().StringVar(&o.sortBy, "sort", "", fmt.Sprintf("Sort by: %s, %s (default: cost with --pricing, otherwise name)", sortByName, strings.Join(calculator.CostSortFields(), ", ")))
+	cmd.Flags().BoolVar(&o.ascending, "asc", false, "Sort costs in ascending order (default: descending)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, csv, json, yaml, or markdown")
+
+	return cmd
+}
+
+// writeChargebackCSV writes the per-group report as CSV with raw, unformatted costs
+func writeChargebackCSV(w io.Writer, column string, report costReport) error {
+	cw := csv.NewWriter(w)
+	header := []string{column, "PODS", "CPU_REQ", "CPU_USAGE", "MEM_REQ", "MEM_USAGE"}
+	if report.Currency != "" {
+		header = append(header, "CURRENCY", "COST/MO", "USAGE/MO", "IDLE/MO")
+	}
+	_ = cw.Write(header)
+	for _, g := range append(report.Groups, report.Total) {
+		row := []string{g.Name, strconv.Itoa(g.Pods), g.CPURequests, g.CPUUsage, g.MemoryRequests, g.MemoryUsage}
+		if report.Currency != "" {
+			c := groupCost(g)
+			row = append(row, report.Currency,
+				strconv.FormatFloat(c.Requests, 'f', 2, 64),
+				strconv.FormatFloat(c.Usage, 'f', 2, 64),
+				strconv.FormatFloat(c.Idle, 'f', 2, 64))
+		}
+		_ = cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
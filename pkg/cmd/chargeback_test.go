@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestChargebackOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pricing string
+		groupBy string
+		sortBy  string
+		output  string
+		errMsg  string
+	}{
+		{name: "defaults"},
+		{name: "label", groupBy: "label:team", output: "csv"},
+		{name: "annotation", groupBy: "annotation:example.com/cost-center", output: "json"},
+		{name: "workload by idle cost", pricing: "pricing.yaml", groupBy: "workload", sortBy: "idle-cost", output: "markdown"},
+		{name: "sort by name", sortBy: "name"},
+		{name: "cost sort without pricing", sortBy: "cost", errMsg: "--sort cost requires --pricing"},
+		{name: "label without key", groupBy: "label:", errMsg: "invalid group-by value"},
+		{name: "invalid group", groupBy: "team", errMsg: "invalid group-by value"},
+		{name: "invalid sort", pricing: "pricing.yaml", sortBy: "memory", errMsg: "invalid sort field"},
+		{name: "invalid output", output: "wide", errMsg: "invalid output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewChargebackOptions(genericclioptions.IOStreams{})
+			o.pricingFile = tt.pricing
+			if tt.groupBy != "" {
+				o.groupBy = tt.groupBy
+			}
+			o.sortBy = tt.sortBy
+			if tt.output != "" {
+				o.output = tt.output
+			}
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestChargebackOptions_WriteReportByLabel(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{Namespace: "payment", Name: "api", Labels: map[string]string{"team": "checkout"}},
+		{Namespace: "payment", Name: "worker"},
+		{Namespace: "batch", Name: "job"},
+	}
+	namespaces := map[string]calculator.NamespaceMetadata{
+		"payment": {Labels: map[string]string{"team": "payments"}},
+	}
+
+	o := NewChargebackOptions(genericclioptions.IOStreams{})
+	o.groupBy = "label:team"
+	o.output = "csv"
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Complete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := o.writeReport(&buf, podUsages, namespaces); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+
+	var names []string
+	for _, record := range records {
+		names = append(names, record[0])
+	}
+	if got, want := strings.Join(names, ","), "TEAM,<none>,checkout,payments,TOTAL"; got != want {
+		t.Errorf("expected groups %s, got %s", want, got)
+	}
+	if len(records[0]) != 6 {
+		t.Errorf("expected no cost columns without pricing, got header %v", records[0])
+	}
+	if records[4][1] != "3" {
+		t.Errorf("expected 3 pods in total, got %s", records[4][1])
+	}
+}
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/policy"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// CheckOptions contains the options for the check command
+type CheckOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	selector  string
+	failOn    []string
+	output    string
+	junitFile string
+}
+
+// NewCheckOptions creates a new CheckOptions with default values
+func NewCheckOptions(streams genericclioptions.IOStreams) *CheckOptions {
+	return &CheckOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+		output:      "table",
+	}
+}
+
+// NewCmdCheck creates the check command
+func NewCmdCheck(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCheckOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check containers against usage policies and exit non-zero on violations",
+		Long: `Evaluate every container against --fail-on rules and list the violations.
+The command exits with code 2 when any rule is violated, so it can gate
+CI pipelines and staging rollouts. Exit code 1 means the check itself failed.`,
+		Example: `  # Fail if any container is above 90% of its memory limit
+  kubectl resource-usage check --fail-on 'memory.limitPercent > 90'
+
+  # Require memory limits and flag over-provisioned CPU requests
+  kubectl resource-usage check -n payment \
+    --fail-on 'memory.limits missing' --fail-on 'cpu.requestPercent < 5'
+
+  # Write a JUnit report for the CI system
+  kubectl resource-usage check --fail-on 'memory.limitPercent > 90' --junit-file report.xml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter by label selector (e.g., app=api)")
+	cmd.Flags().StringArrayVar(&o.failOn, "fail-on", nil, "Rule whose matches are violations, e.g. 'memory.limitPercent > 90' or 'memory.limits missing' (repeatable)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, json, or junit")
+	cmd.Flags().StringVar(&o.junitFile, "junit-file", "", "Also write a JUnit XML report to this file")
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *CheckOptions) Validate() error {
+	if len(o.failOn) == 0 {
+		return fmt.Errorf("at least one --fail-on rule is required")
+	}
+	for _, r := range o.failOn {
+		if _, err := policy.ParseRule(r); err != nil {
+			return err
+		}
+	}
+	if o.selector != "" {
+		if _, err := labels.Parse(o.selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	validOutputs := map[string]bool{"table": true, "json": true, "junit": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', or 'junit')", o.output)
+	}
+	return nil
+}
+
+// Run collects pod usages, checks them and reports violations through the exit code
+func (o *CheckOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil && *o.configFlags.Namespace != "" {
+		namespace = *o.configFlags.Namespace
+	}
+
+	rules := make([]policy.Rule, 0, len(o.failOn))
+	for _, r := range o.failOn {
+		rule, _ := policy.ParseRule(r) // validated in Validate
+		rules = append(rules, rule)
+	}
+
+	// Storage usage is read from every kubelet, so only when a threshold rule needs it
+	client, err := usage.NewClient(restConfig, usage.Options{
+		Storage: policy.NeedsUsage(rules, corev1.ResourceEphemeralStorage),
+		Warn: func(err error) {
+			_, _ = fmt.Fprintf(o.ErrOut, "Warning: %s\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// Presence rules check the pod specs, which include the pods metrics-server has not reported
+	podCollector, err := collector.NewPodCollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create pod collector: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	pods, err := podCollector.GetPods(ctx, namespace, o.selector)
+	if err != nil {
+		return fmt.Errorf("failed to get pods: %w", err)
+	}
+	podUsages, err := client.CollectPodUsages(ctx, namespace, o.selector)
+	if err != nil {
+		return err
+	}
+
+	return o.report(policy.Check(pods.Items, podUsages, rules))
+}
+
+// report writes the result and returns an ExitCodeError if there were violations
+func (o *CheckOptions) report(result policy.Result) error {
+	if err := o.writeResult(o.Out, result); err != nil {
+		return err
+	}
+
+	if o.junitFile != "" {
+		if err := writeJUnitFile(o.junitFile, result); err != nil {
+			return err
+		}
+	}
+
+	if !result.Passed() {
+		return &ExitCodeError{
+			Code: ExitViolations,
+			Err:  fmt.Errorf("%d policy violations in %d containers checked", len(result.Violations), result.Checked),
+		}
+	}
+	return nil
+}
+
+// writeJUnitFile writes the JUnit report to path. The file is closed before
+// returning so that a failed flush to disk is reported too.
+func writeJUnitFile(path string, result policy.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := policy.WriteJUnit(f, result); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// writeResult writes the result in the selected output format
+func (o *CheckOptions) writeResult(w io.Writer, result policy.Result) error {
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case "junit":
+		return policy.WriteJUnit(w, result)
+	}
+
+	if result.Passed() {
+		_, err := fmt.Fprintf(w, "PASS: %d containers checked against %d rules\n", result.Checked, len(result.Rules))
+		return err
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tPOD\tCONTAINER\tRULE\tVIOLATION")
+	for _, v := range result.Violations {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Namespace, v.Pod, v.Container, v.Rule, v.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "FAIL: %d violations in %d containers checked\n", len(result.Violations), result.Checked)
+	return err
+}
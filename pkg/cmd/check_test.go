@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/policy"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCheckOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		failOn []string
+		output string
+		errMsg string
+	}{
+		{name: "valid rules", failOn: []string{"memory.limitPercent > 90", "memory.limits missing"}, output: "table"},
+		{name: "junit output", failOn: []string{"cpu.requestPercent < 5"}, output: "junit"},
+		{name: "no rules", output: "table", errMsg: "at least one --fail-on rule is required"},
+		{name: "invalid rule", failOn: []string{"memory.limits absent"}, output: "table", errMsg: "invalid rule"},
+		{name: "invalid output", failOn: []string{"memory.limits missing"}, output: "yaml", errMsg: "invalid output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewCheckOptions(genericclioptions.IOStreams{})
+			o.failOn = tt.failOn
+			o.output = tt.output
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestCheckOptions_ReportViolations(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewCheckOptions(streams)
+	o.junitFile = filepath.Join(t.TempDir(), "report.xml")
+
+	result := policy.Result{
+		Rules:   []string{"memory.limits missing"},
+		Targets: []policy.Target{{Namespace: "default", Pod: "api", Container: "app"}},
+		Checked: 1,
+		Violations: []policy.Violation{{
+			Target:  policy.Target{Namespace: "default", Pod: "api", Container: "app"},
+			Rule:    "memory.limits missing",
+			Message: "memory limits not set",
+		}},
+	}
+
+	err := o.report(result)
+	var exitErr *ExitCodeError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitViolations {
+		t.Fatalf("expected exit code %d, got %v", ExitViolations, err)
+	}
+	if !strings.Contains(out.String(), "memory limits not set") || !strings.Contains(out.String(), "FAIL: 1 violations") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	report, err := os.ReadFile(o.junitFile)
+	if err != nil {
+		t.Fatalf("expected JUnit report: %v", err)
+	}
+	if !strings.Contains(string(report), `<failure message="memory limits not set"`) {
+		t.Errorf("unexpected JUnit report:\n%s", report)
+	}
+}
+
+func TestCheckOptions_ReportJUnitError(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewCheckOptions(streams)
+	o.junitFile = filepath.Join(t.TempDir(), "missing", "report.xml")
+
+	err := o.report(policy.Result{Checked: 1})
+	if err == nil || !strings.Contains(err.Error(), "failed to create JUnit report") {
+		t.Errorf("expected a JUnit report error, got %v", err)
+	}
+}
+
+func TestCheckOptions_ReportPassed(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewCheckOptions(streams)
+
+	if err := o.report(policy.Result{Rules: []string{"memory.limits missing"}, Checked: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "PASS: 3 containers checked against 1 rules") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
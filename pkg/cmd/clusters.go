@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// usageSource collects pod usages from one or more clusters
+type usageSource interface {
+	CollectPodUsages(ctx context.Context, namespace, selector string) ([]calculator.PodUsage, error)
+}
+
+// clusterCollectors are the usage collectors of a single kubeconfig context
+type clusterCollectors struct {
+	context    string
+	collectors usageSource
+	err        error // set if the collectors could not be created
+}
+
+// clusterSet collects pod usages from several kubeconfig contexts concurrently
+type clusterSet struct {
+	clusters []clusterCollectors
+}
+
+// clusterError is the failure of a single cluster
+type clusterError struct {
+	Context string
+	Err     error
+}
+
+// clusterErrors reports the clusters that failed while others returned pods;
+// if every cluster fails, collect returns a plain error instead
+type clusterErrors []clusterError
+
+// Error joins the per-cluster failures
+func (e clusterErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, ce := range e {
+		parts = append(parts, fmt.Sprintf("cluster %s: %v", ce.Context, ce.Err))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// newClusterSet creates a usage client with the options for each context. Contexts whose
+// client cannot be created are kept and reported as failed on every collect.
+func newClusterSet(raw clientcmdapi.Config, contexts []string, overrides *clientcmd.ConfigOverrides, opts usage.Options) *clusterSet {
+	set := &clusterSet{}
+	for _, name := range contexts {
+		cluster := clusterCollectors{context: name}
+		cluster.collectors, cluster.err = newContextClient(raw, name, overrides, opts)
+		set.clusters = append(set.clusters, cluster)
+	}
+	return set
+}
+
+// newContextClient creates the usage client for a kubeconfig context
+func newContextClient(raw clientcmdapi.Config, name string, overrides *clientcmd.ConfigOverrides, opts usage.Options) (*usage.Client, error) {
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(raw, name, overrides, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST config: %w", err)
+	}
+	opts.Cluster = name
+	return usage.NewClient(restConfig, opts)
+}
+
+// contextOverrides applies the authentication, impersonation, TLS and timeout
+// flags to every context, the way kubectl applies them to the current one.
+// --context, --cluster and --server select a single cluster and are rejected
+// with several contexts, so they are not carried over.
+func contextOverrides(f *genericclioptions.ConfigFlags) *clientcmd.ConfigOverrides {
+	overrides := &clientcmd.ConfigOverrides{}
+	if f == nil {
+		return overrides
+	}
+	set := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	set(&overrides.Context.AuthInfo, f.AuthInfoName)
+	set(&overrides.AuthInfo.ClientCertificate, f.CertFile)
+	set(&overrides.AuthInfo.ClientKey, f.KeyFile)
+	set(&overrides.AuthInfo.Token, f.BearerToken)
+	set(&overrides.AuthInfo.Impersonate, f.Impersonate)
+	set(&overrides.AuthInfo.ImpersonateUID, f.ImpersonateUID)
+	set(&overrides.AuthInfo.Username, f.Username)
+	set(&overrides.AuthInfo.Password, f.Password)
+	set(&overrides.ClusterInfo.TLSServerName, f.TLSServerName)
+	set(&overrides.ClusterInfo.CertificateAuthority, f.CAFile)
+	set(&overrides.Timeout, f.Timeout)
+	if f.ImpersonateGroup != nil {
+		overrides.AuthInfo.ImpersonateGroups = *f.ImpersonateGroup
+	}
+	if f.Insecure != nil {
+		overrides.ClusterInfo.InsecureSkipTLSVerify = *f.Insecure
+	}
+	if f.DisableCompression != nil {
+		overrides.ClusterInfo.DisableCompression = *f.DisableCompression
+	}
+	return overrides
+}
+
+// CollectPodUsages fetches pod usages from every cluster concurrently and merges them
+// in context order, tagging each pod with its context. If only some clusters
+// fail, their pods are returned together with a clusterErrors.
+func (s *clusterSet) CollectPodUsages(ctx context.Context, namespace, selector string) ([]calculator.PodUsage, error) {
+	results := make([][]calculator.PodUsage, len(s.clusters))
+	errs := make([]error, len(s.clusters))
+
+	var wg sync.WaitGroup
+	for i, cluster := range s.clusters {
+		if cluster.err != nil {
+			errs[i] = cluster.err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, cluster clusterCollectors) {
+			defer wg.Done()
+			results[i], errs[i] = cluster.collectors.CollectPodUsages(ctx, namespace, selector)
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	var (
+		podUsages []calculator.PodUsage
+		failed    clusterErrors
+	)
+	for i, cluster := range s.clusters {
+		if errs[i] != nil {
+			failed = append(failed, clusterError{Context: cluster.context, Err: errs[i]})
+			continue
+		}
+		for _, pu := range results[i] {
+			pu.Cluster = cluster.context
+			podUsages = append(podUsages, pu)
+		}
+	}
+
+	if len(failed) == 0 {
+		return podUsages, nil
+	}
+	if len(failed) == len(s.clusters) {
+		return nil, fmt.Errorf("all clusters failed: %s", failed)
+	}
+	if podUsages == nil {
+		podUsages = []calculator.PodUsage{}
+	}
+	return podUsages, failed
+}
+
+// kubeconfigContexts returns the contexts to collect from: every context in
+// the kubeconfig for --all-contexts, otherwise the --contexts names
+func kubeconfigContexts(raw clientcmdapi.Config, names []string, all bool) ([]string, error) {
+	if all {
+		contexts := make([]string, 0, len(raw.Contexts))
+		for name := range raw.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+		if len(contexts) == 0 {
+			return nil, fmt.Errorf("no contexts found in kubeconfig")
+		}
+		return contexts, nil
+	}
+
+	for _, name := range names {
+		if _, ok := raw.Contexts[name]; !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// fakeSource returns fixed pod usages or an error
+type fakeSource struct {
+	pods []calculator.PodUsage
+	err  error
+}
+
+func (f fakeSource) CollectPodUsages(_ context.Context, _, _ string) ([]calculator.PodUsage, error) {
+	return f.pods, f.err
+}
+
+func TestClusterSetCollect(t *testing.T) {
+	set := &clusterSet{clusters: []clusterCollectors{
+		{context: "prod-eu", collectors: fakeSource{pods: []calculator.PodUsage{{Namespace: "default", Name: "api"}}}},
+		{context: "prod-us", collectors: fakeSource{err: errors.New("connection refused")}},
+		{context: "staging", collectors: fakeSource{pods: []calculator.PodUsage{{Namespace: "default", Name: "api"}}}},
+		{context: "broken", err: errors.New("failed to create REST config")},
+	}}
+
+	pods, err := set.CollectPodUsages(context.Background(), "", "")
+
+	var failed clusterErrors
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected clusterErrors, got %v", err)
+	}
+	if len(failed) != 2 || failed[0].Context != "prod-us" || failed[1].Context != "broken" {
+		t.Errorf("expected prod-us and broken to fail, got %v", failed)
+	}
+	if len(pods) != 2 || pods[0].Key() != "prod-eu/default/api" || pods[1].Key() != "staging/default/api" {
+		t.Errorf("expected pods tagged with their context in context order, got %+v", pods)
+	}
+}
+
+func TestClusterSetCollectAllFailed(t *testing.T) {
+	set := &clusterSet{clusters: []clusterCollectors{
+		{context: "prod-eu", collectors: fakeSource{err: errors.New("timeout")}},
+		{context: "prod-us", collectors: fakeSource{err: errors.New("connection refused")}},
+	}}
+
+	pods, err := set.CollectPodUsages(context.Background(), "", "")
+
+	var failed clusterErrors
+	if err == nil || errors.As(err, &failed) {
+		t.Fatalf("expected a plain error when every cluster fails, got %v", err)
+	}
+	if pods != nil || !strings.Contains(err.Error(), "cluster prod-us: connection refused") {
+		t.Errorf("unexpected result: %v, %v", pods, err)
+	}
+}
+
+func TestRunOncePartialFailure(t *testing.T) {
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	opts := NewResourceUsageOptions(streams)
+	opts.interval = 2 * time.Second
+	opts.contexts = []string{"prod-eu", "prod-us"}
+
+	set := &clusterSet{clusters: []clusterCollectors{
+		{context: "prod-eu", collectors: fakeSource{pods: []calculator.PodUsage{{Namespace: "default", Name: "api"}}}},
+		{context: "prod-us", collectors: fakeSource{err: errors.New("connection refused")}},
+	}}
+	formatter := output.NewFormatter("table", output.FormatterOptions{ColorMode: output.ColorModeNever, Unit: "auto"})
+
+	err := opts.runOnce(context.Background(), out, set, "", formatter)
+
+	var failed clusterErrors
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected clusterErrors, got %v", err)
+	}
+	opts.warnClusterErrors(failed)
+	if !strings.HasPrefix(out.String(), "CLUSTER") || !strings.Contains(out.String(), "prod-eu") {
+		t.Errorf("expected a CLUSTER column with the prod-eu pods:\n%s", out.String())
+	}
+	if got, want := errOut.String(), "Warning: cluster prod-us: connection refused\n"; got != want {
+		t.Errorf("expected warning %q, got %q", want, got)
+	}
+}
+
+func TestKubeconfigContexts(t *testing.T) {
+	raw := clientcmdapi.Config{Contexts: map[string]*clientcmdapi.Context{
+		"staging": {Cluster: "staging"},
+		"prod-eu": {Cluster: "eu"},
+	}}
+
+	all, err := kubeconfigContexts(raw, nil, true)
+	if err != nil || strings.Join(all, ",") != "prod-eu,staging" {
+		t.Errorf("expected every context sorted, got %v (%v)", all, err)
+	}
+
+	named, err := kubeconfigContexts(raw, []string{"staging"}, false)
+	if err != nil || strings.Join(named, ",") != "staging" {
+		t.Errorf("expected the named context, got %v (%v)", named, err)
+	}
+
+	if _, err := kubeconfigContexts(raw, []string{"prod-us"}, false); err == nil || !strings.Contains(err.Error(), `context "prod-us" not found`) {
+		t.Errorf("expected unknown context error, got %v", err)
+	}
+}
+
+func TestContextOverrides(t *testing.T) {
+	flags := genericclioptions.NewConfigFlags(true)
+	*flags.BearerToken = "secret"
+	*flags.Impersonate = "alice"
+	*flags.Insecure = true
+	raw := clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"eu": {Server: "https://eu.example.com"},
+			"us": {Server: "https://us.example.com"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {Token: "kubeconfig"}},
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod-eu": {Cluster: "eu", AuthInfo: "admin"},
+			"prod-us": {Cluster: "us", AuthInfo: "admin"},
+		},
+	}
+
+	for _, name := range []string{"prod-eu", "prod-us"} {
+		config, err := clientcmd.NewNonInteractiveClientConfig(raw, name, contextOverrides(flags), nil).ClientConfig()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if config.BearerToken != "secret" || config.Impersonate.UserName != "alice" || !config.Insecure {
+			t.Errorf("%s: expected flag overrides to apply, got token %q, impersonate %q, insecure %v",
+				name, config.BearerToken, config.Impersonate.UserName, config.Insecure)
+		}
+	}
+
+	if overrides := contextOverrides(nil); overrides.AuthInfo.Token != "" {
+		t.Errorf("expected empty overrides without flags, got %+v", overrides)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/config"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// newProfileTestCommand registers a few of the resource-usage flags on a bare command
+func newProfileTestCommand(o *ResourceUsageOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "resource-usage"}
+	cmd.Flags().StringVar(&o.unit, "unit", o.unit, "")
+	cmd.Flags().StringVar(&o.sortBy, "sort", "", "")
+	cmd.Flags().IntVar(&o.above, "above", o.above, "")
+	cmd.Flags().StringSliceVar(&o.excludeNamespaces, "exclude-namespaces", nil, "")
+	cmd.Flags().StringArrayVar(&o.alertRules, "alert", nil, "")
+	cmd.Flags().StringVar(&o.pricingFile, "pricing", "", "")
+	cmd.Flags().StringVar(&o.configFile, "config", "", "")
+	cmd.Flags().StringVar(&o.profile, "profile", "", "")
+	cmd.Flags().DurationVar(&o.interval, "interval", 2*time.Second, "")
+	return cmd
+}
+
+func TestApplyProfileFlags(t *testing.T) {
+	o := NewResourceUsageOptions(genericclioptions.IOStreams{})
+	cmd := newProfileTestCommand(o)
+	if err := cmd.Flags().Parse([]string{"--unit", "Ki"}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile := config.Profile{Flags: map[string]config.FlagValue{
+		"unit":               {"Gi"},
+		"above":              {"80"},
+		"exclude-namespaces": {"kube-system", "monitoring"},
+		"alert":              {"memory.limitPercent >= 90", "cpu.limitPercent > 95"},
+	}}
+	if err := applyProfileFlags(cmd.Flags(), profile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.unit != "Ki" {
+		t.Errorf("expected the command line to win over the profile, got unit %q", o.unit)
+	}
+	if o.above != 80 {
+		t.Errorf("expected above 80, got %d", o.above)
+	}
+	if !reflect.DeepEqual(o.excludeNamespaces, []string{"kube-system", "monitoring"}) {
+		t.Errorf("unexpected excluded namespaces: %v", o.excludeNamespaces)
+	}
+	if len(o.alertRules) != 2 {
+		t.Errorf("expected both alert rules, got %v", o.alertRules)
+	}
+
+	tests := []struct {
+		name   string
+		flags  map[string]config.FlagValue
+		errMsg string
+	}{
+		{"unknown flag", map[string]config.FlagValue{"colour": {"never"}}, `unknown flag "colour"`},
+		{"profile flag", map[string]config.FlagValue{"profile": {"oncall"}}, `unknown flag "profile"`},
+		{"invalid value", map[string]config.FlagValue{"above": {"high"}}, "invalid config value for --above"},
+		{"list for a scalar flag", map[string]config.FlagValue{"sort": {"memory", "cpu"}}, "invalid config value for --sort: a list is only allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewResourceUsageOptions(genericclioptions.IOStreams{})
+			err := applyProfileFlags(newProfileTestCommand(o).Flags(), config.Profile{Flags: tt.flags})
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestResourceUsageOptions_CompleteProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+defaults:
+  flags:
+    unit: Mi
+profiles:
+  finops:
+    flags:
+      sort: idle-cost
+    colors: {warning: 5, critical: 10}
+    pricing: {currency: "€", cpuCoreHour: 0.03}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewResourceUsageOptions(genericclioptions.IOStreams{})
+	cmd := newProfileTestCommand(o)
+	if err := cmd.Flags().Parse([]string{"--config", path, "--profile", "finops"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Complete(cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.unit != "Mi" || o.sortBy != "idle-cost" {
+		t.Errorf("expected the profile flags, got unit %q and sort %q", o.unit, o.sortBy)
+	}
+	if o.colors.Thresholds != (output.Thresholds{Warning: 5, Critical: 10, Over: 100}) {
+		t.Errorf("unexpected colors: %+v", o.colors)
+	}
+	if o.currency() != "€" {
+		t.Errorf("expected the inline pricing, got currency %q", o.currency())
+	}
+	if err := o.Validate(); err != nil {
+		t.Errorf("expected a cost sort to be valid with inline pricing, got %v", err)
+	}
+
+	o = NewResourceUsageOptions(genericclioptions.IOStreams{})
+	cmd = newProfileTestCommand(o)
+	if err := cmd.Flags().Parse([]string{"--config", path, "--profile", "oncall"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Complete(cmd); err == nil || !strings.Contains(err.Error(), `profile "oncall" not found`) {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+}
+
+func TestCompleteProfileSubcommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+defaults:
+  flags:
+    unit: Mi
+  commands:
+    chargeback:
+      sort: idle-cost
+      group-by: workload
+  pricing: {currency: "€", cpuCoreHour: 0.03}
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	root := NewCmdResourceUsage(genericclioptions.IOStreams{})
+	chargeback, _, err := root.Find([]string{"chargeback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chargeback.ParseFlags([]string{"--config", path, "--group-by", "namespace"}); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := completeProfile(chargeback)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Pricing == nil || profile.Pricing.CurrencySymbol() != "€" {
+		t.Errorf("expected the inline pricing, got %+v", profile.Pricing)
+	}
+	if got := chargeback.Flags().Lookup("sort").Value.String(); got != "idle-cost" {
+		t.Errorf("expected the chargeback sort from the config, got %q", got)
+	}
+	if got := chargeback.Flags().Lookup("group-by").Value.String(); got != "namespace" {
+		t.Errorf("expected the command line to win over the config, got %q", got)
+	}
+
+	if err := os.WriteFile(path, []byte("defaults:\n  commands:\n    chargebak: {sort: cost}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := completeProfile(chargeback); err == nil || !strings.Contains(err.Error(), `unknown command "chargebak"`) {
+		t.Errorf("expected an unknown command error, got %v", err)
+	}
+}
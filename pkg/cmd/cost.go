@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cost"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+)
+
+// sortByName orders groups by name, the chargeback default without pricing
+const sortByName = "name"
+
+// CostOptions contains the options for the cost and chargeback commands
+type CostOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	selector    string
+	pricingFile string
+	groupBy     string
+	sortBy      string
+	ascending   bool
+	output      string
+
+	// requirePricing is set for cost; chargeback also reports unpriced groups
+	requirePricing bool
+
+	grouping calculator.GroupBy
+	pricing  *cost.Pricing // nil unless --pricing or the config sets pricing
+}
+
+// costReport is the structured output of the cost and chargeback commands
+type costReport struct {
+	GroupBy  string            `json:"groupBy" yaml:"groupBy"`
+	Currency string            `json:"currency,omitempty" yaml:"currency,omitempty"`
+	Total    costGroupReport   `json:"total" yaml:"total"`
+	Groups   []costGroupReport `json:"groups" yaml:"groups"`
+}
+
+// costGroupReport is one group's requests, usage and monthly cost in structured output
+type costGroupReport struct {
+	Name           string                 `json:"name" yaml:"name"`
+	Pods           int                    `json:"pods" yaml:"pods"`
+	CPURequests    string                 `json:"cpuRequests" yaml:"cpuRequests"`
+	CPUUsage       string                 `json:"cpuUsage" yaml:"cpuUsage"`
+	MemoryRequests string                 `json:"memoryRequests" yaml:"memoryRequests"`
+	MemoryUsage    string                 `json:"memoryUsage" yaml:"memoryUsage"`
+	Cost           *output.StructuredCost `json:"cost,omitempty" yaml:"cost,omitempty"`
+}
+
+// NewCostOptions creates a new CostOptions with default values
+func NewCostOptions(streams genericclioptions.IOStreams) *CostOptions {
+	return &CostOptions{
+		configFlags:    genericclioptions.NewConfigFlags(true),
+		IOStreams:      streams,
+		groupBy:        calculator.GroupByNamespace,
+		output:         "table",
+		requirePricing: true,
+	}
+}
+
+// NewCmdCost creates the cost command
+func NewCmdCost(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCostOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Estimate the monthly cost of requests and usage per namespace, workload or pod",
+		Long: `Price the CPU and memory each namespace, workload or pod requests and
+actually uses at the rates in a pricing file, and show the idle cost of the
+requests left unused. Rates can differ per node label, such as the instance
+type or spot capacity. Costs are projected over a 730-hour month.`,
+		Example: `  # Monthly cost per namespace, most expensive first
+  kubectl resource-usage cost --pricing pricing.yaml
+
+  # Workloads wasting the most money in a namespace
+  kubectl resource-usage cost --pricing pricing.yaml -n payment --group-by workload --sort idle-cost
+
+  # As markdown for a FinOps review
+  kubectl resource-usage cost --pricing pricing.yaml -o markdown`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.runCommand(cmd)
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter pods by label selector (e.g., app=api)")
+	cmd.Flags().StringVar(&o.pricingFile, "pricing", "", "YAML file with per-core-hour and per-GiB-hour rates (required unless the config sets pricing)")
+	cmd.Flags().StringVar(&o.groupBy, "group-by", o.groupBy, fmt.Sprintf("Group costs by: %s", strings.Join(calculator.GroupByFields(), ", ")))
+	cmd.Flags().StringVar(&o.sortBy, "sort", "", fmt.Sprintf("Sort by: %s (default: cost)", strings.Join(calculator.CostSortFields(), ", ")))
+	cmd.Flags().BoolVar(&o.ascending, "asc", false, "Sort in ascending order (default: descending)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, csv, json, yaml, or markdown")
+
+	return cmd
+}
+
+// runCommand applies the config profile, then validates the options and prints the report
+func (o *CostOptions) runCommand(cmd *cobra.Command) error {
+	profile, err := completeProfile(cmd)
+	if err != nil {
+		return err
+	}
+	// Inline pricing applies unless Complete loads a --pricing file
+	o.pricing = profile.Pricing
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if err := o.Complete(); err != nil {
+		return err
+	}
+	return o.Run(cmd.Context())
+}
+
+// Validate validates the options
+func (o *CostOptions) Validate() error {
+	if o.requirePricing && o.pricingFile == "" && o.pricing == nil {
+		return fmt.Errorf("--pricing is required")
+	}
+	if o.selector != "" {
+		if _, err := labels.Parse(o.selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	grouping, err := calculator.ParseGroupBy(o.groupBy)
+	if err != nil {
+		return err
+	}
+	o.grouping = grouping
+	sortFields := append([]string{sortByName}, calculator.CostSortFields()...)
+	if o.sortBy != "" && !slices.Contains(sortFields, o.sortBy) {
+		return fmt.Errorf("invalid sort field: %s (must be one of: %v)", o.sortBy, sortFields)
+	}
+	if slices.Contains(calculator.CostSortFields(), o.sortBy) && o.pricingFile == "" && o.pricing == nil {
+		return fmt.Errorf("--sort %s requires --pricing", o.sortBy)
+	}
+	validOutputs := map[string]bool{"table": true, "csv": true, "json": true, "yaml": true, "markdown": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'csv', 'json', 'yaml', or 'markdown')", o.output)
+	}
+	return nil
+}
+
+// Complete loads the pricing file, if any, over the config's pricing and defaults the sort field
+func (o *CostOptions) Complete() error {
+	if o.pricingFile != "" {
+		pricing, err := cost.LoadPricing(o.pricingFile)
+		if err != nil {
+			return err
+		}
+		o.pricing = pricing
+	}
+	if o.sortBy == "" {
+		o.sortBy = sortByName
+		if o.pricing != nil {
+			o.sortBy = calculator.SortByCost
+		}
+	}
+	return nil
+}
+
+// Run collects pod usages, estimates their costs if priced and prints them per group
+func (o *CostOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil {
+		namespace = *o.configFlags.Namespace
+	}
+
+	// Costs cover cpu and memory only, so storage is not read from the kubelets
+	client, err := usage.NewClient(restConfig, usage.Options{Pricing: o.pricing})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	podUsages, err := client.CollectPodUsages(ctx, namespace, o.selector)
+	if err != nil {
+		return err
+	}
+
+	var namespaces map[string]calculator.NamespaceMetadata
+	if o.grouping.UsesNamespaceMetadata() {
+		namespaces, err = collectNamespaceMetadata(ctx, restConfig, namespace)
+		if err != nil {
+			// Pod labels alone still group most pods, so don't fail the report
+			_, _ = fmt.Fprintf(o.ErrOut, "Warning: %v; grouping by pod %ss only\n", err, o.grouping.Field)
+		}
+	}
+	return o.writeReport(o.Out, podUsages, namespaces)
+}
+
+// collectNamespaceMetadata fetches the labels and annotations of the namespace, or of every namespace
+func collectNamespaceMetadata(ctx context.Context, restConfig *rest.Config, namespace string) (map[string]calculator.NamespaceMetadata, error) {
+	namespaceCollector, err := collector.NewNamespaceCollector(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create namespace collector: %w", err)
+	}
+	items, err := namespaceCollector.GetNamespaces(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := make(map[string]calculator.NamespaceMetadata, len(items))
+	for _, ns := range items {
+		namespaces[ns.Name] = calculator.NamespaceMetadata{Labels: ns.Labels, Annotations: ns.Annotations}
+	}
+	return namespaces, nil
+}
+
+// groups aggregates pod usages by the --group-by value, ordered by --sort
+func (o *CostOptions) groups(podUsages []calculator.PodUsage, namespaces map[string]calculator.NamespaceMetadata) []calculator.GroupUsage {
+	groups := o.grouping.Rollup(podUsages, namespaces)
+	if o.sortBy != sortByName {
+		calculator.SortGroupsByCost(groups, o.sortBy, o.ascending)
+	}
+	return groups
+}
+
+// currency returns the currency symbol costs are shown in, empty without pricing
+func (o *CostOptions) currency() string {
+	if o.pricing == nil {
+		return ""
+	}
+	return o.pricing.CurrencySymbol()
+}
+
+// writeReport writes the per-group report in the selected output format
+func (o *CostOptions) writeReport(w io.Writer, podUsages []calculator.PodUsage, namespaces map[string]calculator.NamespaceMetadata) (err error) {
+	report := o.toReport(podUsages, namespaces)
+
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer func() {
+			if closeErr := enc.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		return enc.Encode(report)
+	case "csv":
+		return writeChargebackCSV(w, o.grouping.Column(), report)
+	case "markdown":
+		return writeCostMarkdown(w, o.grouping.Column(), report)
+	}
+
+	if len(report.Groups) == 0 {
+		_, err := fmt.Fprintln(w, "No pods found")
+		return err
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, strings.Join(costHeader(o.grouping.Column(), report), "\t"))
+	for _, g := range append(report.Groups, report.Total) {
+		_, _ = fmt.Fprintln(tw, strings.Join(costRow(report.Currency, g), "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCostMarkdown writes the per-group report as a markdown table
+func writeCostMarkdown(w io.Writer, column string, report costReport) error {
+	header := costHeader(column, report)
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %s |\n", strings.Join(header, " | "))
+	b.WriteString("|---|" + strings.Repeat("--:|", len(header)-1) + "\n")
+	for _, g := range append(report.Groups, report.Total) {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(costRow(report.Currency, g), " | "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// costHeader returns the table columns, with the cost columns only when priced
+func costHeader(column string, report costReport) []string {
+	header := []string{column, "PODS", "CPU_REQ", "CPU_USAGE", "MEM_REQ", "MEM_USAGE"}
+	if report.Currency != "" {
+		header = append(header, "COST/MO", "USAGE/MO", "IDLE/MO")
+	}
+	return header
+}
+
+// costRow returns a group's table cells, with its costs only when priced
+func costRow(currency string, g costGroupReport) []string {
+	row := []string{g.Name, strconv.Itoa(g.Pods), g.CPURequests, g.CPUUsage, g.MemoryRequests, g.MemoryUsage}
+	if currency != "" {
+		c := groupCost(g)
+		row = append(row,
+			output.FormatCost(currency, c.Requests),
+			output.FormatCost(currency, c.Usage),
+			output.FormatCost(currency, c.Idle))
+	}
+	return row
+}
+
+// groupCost returns a group's cost, zero if none of its pods were priced
+func groupCost(g costGroupReport) output.StructuredCost {
+	if g.Cost == nil {
+		return output.StructuredCost{}
+	}
+	return *g.Cost
+}
+
+// toReport converts pod usages to the structured report, with a TOTAL row over every pod
+func (o *CostOptions) toReport(podUsages []calculator.PodUsage, namespaces map[string]calculator.NamespaceMetadata) costReport {
+	report := costReport{GroupBy: o.grouping.String(), Currency: o.currency(), Groups: []costGroupReport{}}
+	for _, g := range o.groups(podUsages, namespaces) {
+		report.Groups = append(report.Groups, toCostGroupReport(g))
+	}
+
+	total := calculator.Rollup(podUsages, func(calculator.PodUsage) string { return "TOTAL" })
+	if len(total) == 0 {
+		total = []calculator.GroupUsage{{Name: "TOTAL"}}
+	}
+	report.Total = toCostGroupReport(total[0])
+	return report
+}
+
+// toCostGroupReport converts a group usage to its structured report
+func toCostGroupReport(g calculator.GroupUsage) costGroupReport {
+	cpu, memory := g.Resources[corev1.ResourceCPU], g.Resources[corev1.ResourceMemory]
+	unitFormatter := output.NewUnitFormatter("auto")
+	return costGroupReport{
+		Name:           g.Name,
+		Pods:           g.Pods,
+		CPURequests:    unitFormatter.FormatQuantityOrNA(calculator.UnitFamilyCPU, cpu.Requests),
+		CPUUsage:       unitFormatter.FormatUsage(calculator.UnitFamilyCPU, cpu),
+		MemoryRequests: unitFormatter.FormatQuantityOrNA(calculator.UnitFamilyBytes, memory.Requests),
+		MemoryUsage:    unitFormatter.FormatUsage(calculator.UnitFamilyBytes, memory),
+		Cost:           output.ToStructuredCost(g.Cost),
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cost"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestCostOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pricing string
+		groupBy string
+		sortBy  string
+		output  string
+		errMsg  string
+	}{
+		{name: "defaults", pricing: "pricing.yaml"},
+		{name: "workload by idle cost", pricing: "pricing.yaml", groupBy: "workload", sortBy: "idle-cost", output: "markdown"},
+		{name: "label as csv", pricing: "pricing.yaml", groupBy: "label:team", output: "csv"},
+		{name: "missing pricing", errMsg: "--pricing is required"},
+		{name: "invalid group", pricing: "pricing.yaml", groupBy: "team", errMsg: "invalid group-by value"},
+		{name: "invalid sort", pricing: "pricing.yaml", sortBy: "memory", errMsg: "invalid sort field"},
+		{name: "invalid output", pricing: "pricing.yaml", output: "wide", errMsg: "invalid output format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewCostOptions(genericclioptions.IOStreams{})
+			o.pricingFile = tt.pricing
+			if tt.groupBy != "" {
+				o.groupBy = tt.groupBy
+			}
+			o.sortBy = tt.sortBy
+			if tt.output != "" {
+				o.output = tt.output
+			}
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	o := NewCostOptions(genericclioptions.IOStreams{})
+	o.pricing = &cost.Pricing{}
+	if err := o.Validate(); err != nil {
+		t.Errorf("expected the config pricing to satisfy --pricing, got %v", err)
+	}
+}
+
+func TestCostOptions_WriteReport(t *testing.T) {
+	cpu := resource.MustParse("1")
+	podUsages := []calculator.PodUsage{
+		{Namespace: "payment", Name: "api-1", Workload: "Deployment/api", Cost: &calculator.Cost{Requests: 30, Usage: 10, Idle: 20},
+			Resources: calculator.ResourceUsages{corev1.ResourceCPU: {Usage: resource.MustParse("250m"), Requests: &cpu}}},
+		{Namespace: "payment", Name: "api-2", Workload: "Deployment/api", Cost: &calculator.Cost{Requests: 30, Usage: 25, Idle: 5},
+			Resources: calculator.ResourceUsages{corev1.ResourceCPU: {Usage: resource.MustParse("750m"), Requests: &cpu}}},
+		{Namespace: "search", Name: "indexer", Workload: "StatefulSet/indexer", Cost: &calculator.Cost{Requests: 100, Usage: 90, Idle: 10}},
+	}
+
+	o := NewCostOptions(genericclioptions.IOStreams{})
+	o.pricing = &cost.Pricing{}
+	o.pricingFile = "pricing.yaml"
+	o.sortBy = calculator.SortByIdleCost
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := o.writeReport(&buf, podUsages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "NAMESPACE") || !strings.HasPrefix(lines[1], "payment") || !strings.HasPrefix(lines[3], "TOTAL") {
+		t.Fatalf("expected namespaces by idle cost and a total, got:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "$25.00") || !strings.Contains(lines[3], "$160.00") {
+		t.Errorf("unexpected costs:\n%s", buf.String())
+	}
+
+	o.output = "json"
+	o.groupBy = "workload"
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf.Reset()
+	if err := o.writeReport(&buf, podUsages, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report costReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(report.Groups) != 2 || report.Groups[0].Name != "payment/Deployment/api" || report.Groups[0].Pods != 2 {
+		t.Fatalf("unexpected workloads: %+v", report.Groups)
+	}
+	if g := report.Groups[0]; g.CPURequests != "2000m" || g.CPUUsage != "1000m" || g.Cost == nil || g.Cost.Idle != 25 {
+		t.Errorf("unexpected workload totals: %+v", g)
+	}
+}
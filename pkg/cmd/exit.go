@@ -0,0 +1,24 @@
+package cmd
+
+// Exit codes returned by the plugin
+const (
+	ExitOK         = 0 // completed successfully
+	ExitError      = 1 // execution failed, e.g. metrics-server not installed
+	ExitViolations = 2 // check found policy violations
+)
+
+// ExitCodeError is an error that requests a specific process exit code
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
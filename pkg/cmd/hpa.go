@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// HPAOptions contains the options for the hpa command
+type HPAOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	output    string
+	precision int
+}
+
+// hpaReport is the structured output of the hpa command
+type hpaReport struct {
+	HPAs []hpaUsageReport `json:"hpas" yaml:"hpas"`
+}
+
+// hpaUsageReport is one HPA's targets, usage and replicas in structured output
+type hpaUsageReport struct {
+	Namespace       string            `json:"namespace" yaml:"namespace"`
+	Name            string            `json:"name" yaml:"name"`
+	Target          string            `json:"target" yaml:"target"`
+	MinReplicas     int32             `json:"minReplicas" yaml:"minReplicas"`
+	MaxReplicas     int32             `json:"maxReplicas" yaml:"maxReplicas"`
+	CurrentReplicas int32             `json:"currentReplicas" yaml:"currentReplicas"`
+	DesiredReplicas int32             `json:"desiredReplicas" yaml:"desiredReplicas"`
+	State           string            `json:"state" yaml:"state"`
+	Pods            int               `json:"pods" yaml:"pods"`
+	Metrics         []hpaMetricReport `json:"metrics" yaml:"metrics"`
+}
+
+// hpaMetricReport is one resource utilization target in structured output
+type hpaMetricReport struct {
+	Resource       string   `json:"resource" yaml:"resource"`
+	TargetPercent  float64  `json:"targetPercent" yaml:"targetPercent"`
+	RequestPercent *float64 `json:"requestPercent" yaml:"requestPercent"`
+}
+
+// NewHPAOptions creates a new HPAOptions with default values
+func NewHPAOptions(streams genericclioptions.IOStreams) *HPAOptions {
+	return &HPAOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+		output:      "table",
+	}
+}
+
+// NewCmdHPA creates the hpa command
+func NewCmdHPA(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewHPAOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "hpa",
+		Short: "Compare usage with HorizontalPodAutoscaler targets and replica limits",
+		Long: `Show each HorizontalPodAutoscaler's utilization targets next to the current
+Request% of the pods it scales, averaged across replicas the way the HPA does,
+with its min, max, current and desired replicas.
+
+STATE is near-max from 80% of maxReplicas and at-max once the current or
+desired replicas reach it: high usage there won't be fixed by scaling out.
+Targets on other than cpu or memory utilization are not compared.`,
+		Example: `  # Every HPA in the cluster
+  kubectl resource-usage hpa
+
+  # HPAs of one namespace as JSON
+  kubectl resource-usage hpa -n payment -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, json, or yaml")
+	cmd.Flags().IntVar(&o.precision, "precision", 0, fmt.Sprintf("Decimal places of percentages in table output (0-%d)", maxPrecision))
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *HPAOptions) Validate() error {
+	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', or 'yaml')", o.output)
+	}
+	return validatePrecision(o.precision)
+}
+
+// Run lists HPAs, collects the usage of the pods they scale and prints them
+func (o *HPAOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil {
+		namespace = *o.configFlags.Namespace
+	}
+
+	hpaCollector, err := collector.NewHPACollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create hpa collector: %w", err)
+	}
+
+	// HPAs scale on cpu and memory, so skip reading kubelet storage stats
+	client, err := usage.NewClient(restConfig, usage.Options{})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	hpas, err := hpaCollector.GetHPAs(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	podUsages, err := client.CollectPodUsages(ctx, namespace, "")
+	if err != nil {
+		return err
+	}
+
+	return o.writeUsages(o.Out, calculator.CalculateHPAUsages(hpas, podUsages))
+}
+
+// writeUsages writes the HPA usages in the selected output format
+func (o *HPAOptions) writeUsages(w io.Writer, usages []calculator.HPAUsage) (err error) {
+	report := toHPAReport(usages)
+
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer func() {
+			if closeErr := enc.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		return enc.Encode(report)
+	}
+
+	if len(report.HPAs) == 0 {
+		_, err := fmt.Fprintln(w, "No horizontal pod autoscalers found")
+		return err
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tHPA\tTARGET\tRESOURCE\tTARGET%\tREQUEST%\tMIN\tMAX\tCURRENT\tDESIRED\tSTATE")
+	for _, hpa := range report.HPAs {
+		replicas := fmt.Sprintf("%d\t%d\t%d\t%d\t%s", hpa.MinReplicas, hpa.MaxReplicas, hpa.CurrentReplicas, hpa.DesiredReplicas, hpa.State)
+		if len(hpa.Metrics) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t-\t-\t-\t%s\n", hpa.Namespace, hpa.Name, hpa.Target, replicas)
+			continue
+		}
+		for _, m := range hpa.Metrics {
+			target := m.TargetPercent
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				hpa.Namespace, hpa.Name, hpa.Target, m.Resource,
+				output.FormatPercentText(&target, o.precision), output.FormatPercentText(m.RequestPercent, o.precision), replicas)
+		}
+	}
+	return tw.Flush()
+}
+
+// toHPAReport converts HPA usages to the structured report
+func toHPAReport(usages []calculator.HPAUsage) hpaReport {
+	report := hpaReport{HPAs: []hpaUsageReport{}}
+	for _, u := range usages {
+		hpa := hpaUsageReport{
+			Namespace:       u.Namespace,
+			Name:            u.Name,
+			Target:          u.Target,
+			MinReplicas:     u.MinReplicas,
+			MaxReplicas:     u.MaxReplicas,
+			CurrentReplicas: u.CurrentReplicas,
+			DesiredReplicas: u.DesiredReplicas,
+			State:           string(u.State),
+			Pods:            u.Pods,
+			Metrics:         []hpaMetricReport{},
+		}
+		for _, m := range u.Metrics {
+			hpa.Metrics = append(hpa.Metrics, hpaMetricReport{
+				Resource:       string(m.Resource),
+				TargetPercent:  m.TargetPercent,
+				RequestPercent: m.RequestPercent,
+			})
+		}
+		report.HPAs = append(report.HPAs, hpa)
+	}
+	return report
+}
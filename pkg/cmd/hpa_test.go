@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestHPAOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		precision int
+		errMsg    string
+	}{
+		{name: "defaults", output: "table"},
+		{name: "yaml", output: "yaml", precision: 1},
+		{name: "invalid output", output: "csv", errMsg: "invalid output format"},
+		{name: "invalid precision", output: "table", precision: 7, errMsg: "invalid precision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewHPAOptions(genericclioptions.IOStreams{})
+			o.output = tt.output
+			o.precision = tt.precision
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestHPAOptions_WriteUsages(t *testing.T) {
+	requestPercent := 92.5
+	usages := []calculator.HPAUsage{
+		{
+			Namespace: "payment", Name: "api", Target: "Deployment/api",
+			MinReplicas: 2, MaxReplicas: 10, CurrentReplicas: 10, DesiredReplicas: 10, Pods: 10,
+			Metrics: []calculator.HPAMetric{
+				{Resource: corev1.ResourceCPU, TargetPercent: 60, RequestPercent: &requestPercent},
+				{Resource: corev1.ResourceMemory, TargetPercent: 80},
+			},
+			State: calculator.ScalingAtMax,
+		},
+		{
+			Namespace: "payment", Name: "worker", Target: "StatefulSet/worker",
+			MinReplicas: 1, MaxReplicas: 5, CurrentReplicas: 1, DesiredReplicas: 1,
+			State: calculator.ScalingOK,
+		},
+	}
+
+	o := NewHPAOptions(genericclioptions.IOStreams{})
+	var table bytes.Buffer
+	if err := o.writeUsages(&table, usages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 rows, got:\n%s", table.String())
+	}
+	for i, want := range [][]string{
+		{"TARGET%", "REQUEST%", "STATE"},
+		{"Deployment/api", "cpu", "60%", "92%", "at-max"},
+		{"memory", "80%", "N/A"},
+		{"StatefulSet/worker", "-", "ok"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("expected line %d to contain %q, got %q", i, w, lines[i])
+			}
+		}
+	}
+
+	o.precision = 1
+	table.Reset()
+	if err := o.writeUsages(&table, usages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(table.String(), "92.5%") {
+		t.Errorf("expected REQUEST%% with one decimal, got:\n%s", table.String())
+	}
+
+	o.output = "json"
+	var out bytes.Buffer
+	if err := o.writeUsages(&out, usages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report hpaReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(report.HPAs) != 2 || report.HPAs[0].State != "at-max" || len(report.HPAs[0].Metrics) != 2 {
+		t.Fatalf("unexpected report: %+v", report.HPAs)
+	}
+	if cpu := report.HPAs[0].Metrics[0]; cpu.Resource != "cpu" || cpu.TargetPercent != 60 || *cpu.RequestPercent != 92.5 {
+		t.Errorf("unexpected cpu metric: %+v", cpu)
+	}
+	if report.HPAs[1].Metrics == nil {
+		t.Error("expected an empty metrics list, not null")
+	}
+
+	table.Reset()
+	o.output = "table"
+	if err := o.writeUsages(&table, nil); err != nil || !strings.Contains(table.String(), "No horizontal pod autoscalers found") {
+		t.Errorf("expected no HPAs message, got %q (%v)", table.String(), err)
+	}
+}
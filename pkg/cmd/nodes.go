@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// NodesOptions contains the options for the nodes command
+type NodesOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	selector     string
+	output       string
+	extendedOnly bool
+	precision    int
+}
+
+// nodesReport is the structured output of the nodes command
+type nodesReport struct {
+	Nodes []nodeReport `json:"nodes" yaml:"nodes"`
+}
+
+// nodeReport is one node's allocation in structured output
+type nodeReport struct {
+	Node      string               `json:"node" yaml:"node"`
+	Resources []nodeResourceReport `json:"resources" yaml:"resources"`
+}
+
+// nodeResourceReport is one node resource's allocation in structured output
+type nodeResourceReport struct {
+	Name             string   `json:"name" yaml:"name"`
+	Capacity         string   `json:"capacity" yaml:"capacity"`
+	Allocatable      string   `json:"allocatable" yaml:"allocatable"`
+	Requested        string   `json:"requested" yaml:"requested"`
+	Pods             int      `json:"pods" yaml:"pods"`
+	AllocatedPercent *float64 `json:"allocatedPercent" yaml:"allocatedPercent"`
+}
+
+// NewNodesOptions creates a new NodesOptions with default values
+func NewNodesOptions(streams genericclioptions.IOStreams) *NodesOptions {
+	return &NodesOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+		output:      "table",
+	}
+}
+
+// NewCmdNodes creates the nodes command
+func NewCmdNodes(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewNodesOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "Show requested resources against node capacity",
+		Long: `Sum the requests of the pods scheduled on each node and compare them with
+the node's capacity and allocatable. Covers cpu, memory and extended resources
+such as nvidia.com/gpu and hugepages. Nodes and pods are read from the API, so
+it works without metrics-server.`,
+		Example: `  # Allocation of every resource on every node
+  kubectl resource-usage nodes
+
+  # GPU and other extended resources on the GPU node pool
+  kubectl resource-usage nodes -l pool=gpu --extended-only
+
+  # As JSON
+  kubectl resource-usage nodes -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter nodes by label selector (e.g., pool=gpu)")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, json, or yaml")
+	cmd.Flags().BoolVar(&o.extendedOnly, "extended-only", false, "Only show extended resources such as GPUs and hugepages")
+	cmd.Flags().IntVar(&o.precision, "precision", 0, fmt.Sprintf("Decimal places of ALLOC%% in table output (0-%d)", maxPrecision))
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *NodesOptions) Validate() error {
+	if o.selector != "" {
+		if _, err := labels.Parse(o.selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', or 'yaml')", o.output)
+	}
+	return validatePrecision(o.precision)
+}
+
+// Run lists nodes and pods and prints the nodes' allocation
+func (o *NodesOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	nodeCollector, err := collector.NewNodeCollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create node collector: %w", err)
+	}
+
+	podCollector, err := collector.NewPodCollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create pod collector: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	nodes, err := nodeCollector.GetNodes(ctx, o.selector)
+	if err != nil {
+		return fmt.Errorf("failed to get nodes: %w", err)
+	}
+
+	// Requests count against a node whatever the pod's namespace
+	pods, err := podCollector.GetPods(ctx, "", "")
+	if err != nil {
+		return fmt.Errorf("failed to get pods: %w", err)
+	}
+
+	allocations := calculator.CalculateNodeAllocations(nodes.Items, pods.Items)
+	return o.writeAllocations(o.Out, allocations)
+}
+
+// writeAllocations writes the node allocations in the selected output format
+func (o *NodesOptions) writeAllocations(w io.Writer, allocations []calculator.NodeAllocation) (err error) {
+	report := o.toReport(allocations)
+
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer func() {
+			if closeErr := enc.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		return enc.Encode(report)
+	}
+
+	if len(report.Nodes) == 0 {
+		_, err := fmt.Fprintln(w, "No nodes found")
+		return err
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, "NODE\tRESOURCE\tCAPACITY\tALLOCATABLE\tREQUESTED\tALLOC%\tPODS")
+	for _, node := range report.Nodes {
+		for _, r := range node.Resources {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				node.Node, r.Name, r.Capacity, r.Allocatable, r.Requested, output.FormatPercentText(r.AllocatedPercent, o.precision), r.Pods)
+		}
+	}
+	return tw.Flush()
+}
+
+// toReport converts allocations to the structured report, applying --extended-only.
+// Nodes left without resources are dropped.
+func (o *NodesOptions) toReport(allocations []calculator.NodeAllocation) nodesReport {
+	report := nodesReport{Nodes: []nodeReport{}}
+	for _, allocation := range allocations {
+		node := nodeReport{Node: allocation.Node, Resources: []nodeResourceReport{}}
+		for _, ra := range allocation.Resources {
+			if o.extendedOnly && !calculator.IsExtendedResource(ra.Name) {
+				continue
+			}
+			node.Resources = append(node.Resources, nodeResourceReport{
+				Name:             string(ra.Name),
+				Capacity:         ra.Capacity.String(),
+				Allocatable:      ra.Allocatable.String(),
+				Requested:        ra.Requested.String(),
+				Pods:             ra.Pods,
+				AllocatedPercent: ra.AllocatedPercent,
+			})
+		}
+		if len(node.Resources) > 0 {
+			report.Nodes = append(report.Nodes, node)
+		}
+	}
+	return report
+}
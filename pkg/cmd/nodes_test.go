@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestNodesOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		selector  string
+		precision int
+		errMsg    string
+	}{
+		{name: "defaults", output: "table"},
+		{name: "yaml with selector", output: "yaml", selector: "pool=gpu"},
+		{name: "precision", output: "table", precision: 2},
+		{name: "invalid output", output: "wide", errMsg: "invalid output format"},
+		{name: "invalid selector", output: "table", selector: "pool in (", errMsg: "invalid label selector"},
+		{name: "invalid precision", output: "table", precision: -1, errMsg: "invalid precision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewNodesOptions(genericclioptions.IOStreams{})
+			o.output = tt.output
+			o.selector = tt.selector
+			o.precision = tt.precision
+
+			err := o.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestNodesOptions_WriteAllocations(t *testing.T) {
+	percent := 75.25
+	zero := 0.0
+	allocations := []calculator.NodeAllocation{
+		{
+			Node: "gpu-1",
+			Resources: []calculator.ResourceAllocation{
+				{Name: corev1.ResourceCPU, Capacity: resource.MustParse("8"), Allocatable: resource.MustParse("8"), AllocatedPercent: &zero},
+				{Name: "nvidia.com/gpu", Capacity: resource.MustParse("4"), Allocatable: resource.MustParse("4"),
+					Requested: resource.MustParse("3"), Pods: 2, AllocatedPercent: &percent},
+			},
+		},
+		{
+			Node:      "cpu-1",
+			Resources: []calculator.ResourceAllocation{{Name: corev1.ResourceCPU, Capacity: resource.MustParse("4"), Allocatable: resource.MustParse("4")}},
+		},
+	}
+
+	o := NewNodesOptions(genericclioptions.IOStreams{})
+	var table bytes.Buffer
+	if err := o.writeAllocations(&table, allocations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"ALLOCATABLE", "nvidia.com/gpu", "75%", "cpu-1"} {
+		if !strings.Contains(table.String(), want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, table.String())
+		}
+	}
+
+	o.precision = 1
+	table.Reset()
+	if err := o.writeAllocations(&table, allocations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(table.String(), "75.2%") {
+		t.Errorf("expected ALLOC%% with one decimal, got:\n%s", table.String())
+	}
+
+	o.output = "json"
+	o.extendedOnly = true
+	var out bytes.Buffer
+	if err := o.writeAllocations(&out, allocations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report nodesReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(report.Nodes) != 1 || len(report.Nodes[0].Resources) != 1 {
+		t.Fatalf("expected only gpu-1 with its GPU, got %+v", report.Nodes)
+	}
+	if gpu := report.Nodes[0].Resources[0]; gpu.Name != "nvidia.com/gpu" || gpu.Requested != "3" || gpu.Pods != 2 || *gpu.AllocatedPercent != 75.25 {
+		t.Errorf("unexpected GPU allocation: %+v", gpu)
+	}
+}
@@ -1,15 +1,29 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/alert"
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
-	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/config"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cost"
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/tui"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/labels"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
@@ -24,27 +38,110 @@ type ResourceUsageOptions struct {
 	output    string
 	color     string
 	unit      string
+	markers   string
+	palette   string
+	precision int
 
 	// Watch options
-	watch    bool
-	interval time.Duration
+	watch       bool
+	interval    time.Duration
+	interactive bool
 
 	// Filter options
 	above    int
 	below    int
 	noLimits bool
+
+	// Pod status filters
+	qosClass    string
+	phase       string
+	minRestarts int32
+	oomKilled   bool
+
+	excludeNamespaces []string
+
+	// Multi-cluster options
+	contexts    []string
+	allContexts bool
+
+	// assumeLimitRangeDefaults fills missing requests and limits from LimitRange defaults
+	assumeLimitRangeDefaults bool
+
+	// throttling reads CFS throttling and CPU pressure from the kubelet cadvisor endpoint
+	throttling bool
+
+	// storage reads ephemeral storage usage from the kubelet stats summary
+	storage bool
+	// nodeWarning reports unreadable kubelets once per run rather than on every watch tick
+	nodeWarning sync.Once
+
+	// pricingFile holds the rates pod costs are estimated at, loaded into pricing by Complete
+	pricingFile string
+	pricing     *cost.Pricing
+
+	// configFile and profile select the config file defaults applied by Complete
+	configFile string
+	profile    string
+	colors     output.ColorScheme
+
+	// Alert options
+	alertRules    []string
+	alertExec     string
+	alertWebhook  string
+	alertBanner   bool
+	alertCooldown time.Duration
+
+	alerts *watchAlerts
+}
+
+// watchAlerts holds the alert state carried across watch ticks
+type watchAlerts struct {
+	manager *alert.Manager
+	banner  *alert.Banner
+	async   []*alert.AsyncAction // command and webhook actions, fired in the background
+	lastErr error
+}
+
+// alertActionTimeout bounds each alert command or webhook delivery
+const alertActionTimeout = 30 * time.Second
+
+// evaluate checks the alert rules against the sample. Pods of clusters that
+// failed keep their alert state instead of resolving, and failures of
+// background deliveries since the last tick are reported with the tick's own.
+func (wa *watchAlerts) evaluate(ctx context.Context, podUsages []calculator.PodUsage, failed clusterErrors) {
+	unavailable := make([]string, 0, len(failed))
+	for _, ce := range failed {
+		unavailable = append(unavailable, ce.Context)
+	}
+	errs := []error{wa.manager.Evaluate(ctx, podUsages, time.Now(), unavailable...)}
+	for _, a := range wa.async {
+		errs = append(errs, a.Errors())
+	}
+	wa.lastErr = errors.Join(errs...)
+}
+
+// close waits for the background deliveries still queued and returns their failures
+func (wa *watchAlerts) close() error {
+	errs := make([]error, 0, len(wa.async))
+	for _, a := range wa.async {
+		a.Close()
+		errs = append(errs, a.Errors())
+	}
+	return errors.Join(errs...)
 }
 
 // NewResourceUsageOptions creates a new ResourceUsageOptions with default values
 func NewResourceUsageOptions(streams genericclioptions.IOStreams) *ResourceUsageOptions {
 	return &ResourceUsageOptions{
-		configFlags: genericclioptions.NewConfigFlags(true),
-		IOStreams:   streams,
-		output:      "table",
-		color:       "auto",
-		unit:        "auto",
-		above:       -1,
-		below:       -1,
+		configFlags:   genericclioptions.NewConfigFlags(true),
+		IOStreams:     streams,
+		output:        "table",
+		color:         "auto",
+		unit:          "auto",
+		markers:       "emoji",
+		above:         -1,
+		below:         -1,
+		alertCooldown: 30 * time.Second,
 	}
 }
 
@@ -86,9 +183,40 @@ relative to requests and limits, helping SREs quickly identify resource issues.`
   kubectl resource-usage -o yaml
   kubectl resource-usage -o wide
 
+  # Output with kubectl-style templates
+  kubectl resource-usage -o custom-columns=NAME:.pod,MEM:.memory.limitPercent
+  kubectl resource-usage -o jsonpath='{.items[*].pod}'
+  kubectl resource-usage -o go-template='{{range .items}}{{.pod}}{{"\n"}}{{end}}'
+
+  # Markdown table for PRs and incident docs
+  kubectl resource-usage -o markdown --markers text
+
+  # Write a self-contained HTML report
+  kubectl resource-usage -o html > report.html
+
+  # Merge the pods of several clusters, or of every kubeconfig context
+  kubectl resource-usage --contexts prod-eu,prod-us --sort memory
+  kubectl resource-usage --all-contexts -o wide
+
+  # Estimate monthly costs and sort by the cost of unused requests
+  kubectl resource-usage --pricing pricing.yaml --sort idle-cost
+
+  # Use the flags, colors and pricing of a profile in ~/.config/kubectl-resource-usage/config.yaml
+  kubectl resource-usage --profile oncall
+
   # Watch mode with custom interval
   kubectl resource-usage -w
-  kubectl resource-usage --watch --interval 5s`,
+  kubectl resource-usage --watch --interval 5s
+
+  # Interactive terminal UI (arrows to move, / to filter, v to switch views)
+  kubectl resource-usage --interactive
+
+  # Stream one JSON object per pod per sample
+  kubectl resource-usage -w -o ndjson | jq .
+
+  # Alert when a pod stays above 90% of its memory limit for 2 minutes
+  kubectl resource-usage -w --alert 'memory.limitPercent >= 90 for 2m' --alert-webhook http://localhost:8080/hook
+  kubectl resource-usage -w --alert 'cpu.limitPercent > 95' --alert-exec 'notify-send "$ALERT_POD $ALERT_STATE"'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(cmd); err != nil {
 				return err
@@ -105,82 +233,292 @@ relative to requests and limits, helping SREs quickly identify resource issues.`
 
 	// Add custom flags
 	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter by label selector (e.g., app=api)")
-	cmd.Flags().StringVar(&o.sortBy, "sort", "", "Sort by field: cpu or memory")
+	cmd.Flags().StringVar(&o.sortBy, "sort", "", fmt.Sprintf("Sort by field: a resource's Limit%% (%s), restarts, oom (most recent OOM kill), throttled with --throttling, or a monthly cost with --pricing (%s)",
+		strings.Join(calculator.ResourceNames(), ", "), strings.Join(calculator.CostSortFields(), ", ")))
 	cmd.Flags().BoolVar(&o.ascending, "asc", false, "Sort in ascending order (default: descending)")
-	cmd.Flags().StringVarP(&o.output, "output", "o", "table", "Output format: table, json, yaml, or wide")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "table", "Output format: table, json, yaml, wide, ndjson, html, markdown, custom-columns=..., jsonpath=..., or go-template=...")
 	cmd.Flags().StringVar(&o.color, "color", "auto", "Color output: auto, always, or never")
 	cmd.Flags().StringVar(&o.unit, "unit", "auto", "Unit for display: auto, Ki, Mi, Gi, m, or cores")
+	cmd.Flags().StringVar(&o.markers, "markers", "emoji", "Severity markers for markdown output: emoji, text, or none")
+	cmd.Flags().StringVar(&o.palette, "palette", "", "Color palette: default, or colorblind for a blue-orange scheme (default: from the config file)")
+	cmd.Flags().IntVar(&o.precision, "precision", 0, fmt.Sprintf("Decimal places of percentages in table, wide, markdown, html and interactive output (0-%d)", maxPrecision))
 
 	// Watch flags
 	cmd.Flags().BoolVarP(&o.watch, "watch", "w", false, "Watch mode: refresh output periodically")
 	cmd.Flags().DurationVar(&o.interval, "interval", 2*time.Second, "Refresh interval for watch mode")
+	cmd.Flags().BoolVar(&o.interactive, "interactive", false, "Interactive terminal UI with navigation, sorting, views and filtering")
 
 	// Filter flags
 	cmd.Flags().IntVar(&o.above, "above", -1, "Show pods with usage >= N% (uses --sort field, default: memory)")
 	cmd.Flags().IntVar(&o.below, "below", -1, "Show pods with usage <= N% (uses --sort field, default: memory)")
 	cmd.Flags().BoolVar(&o.noLimits, "no-limits", false, "Show pods without limits configured")
-
-	// Add completion subcommand
+	cmd.Flags().StringVar(&o.qosClass, "qos", "", "Show pods in a QoS class: Guaranteed, Burstable, or BestEffort")
+	cmd.Flags().StringVar(&o.phase, "phase", "", "Show pods in a phase: Pending, Running, Succeeded, Failed, or Unknown")
+	cmd.Flags().Int32Var(&o.minRestarts, "min-restarts", 0, "Show pods with at least N container restarts")
+	cmd.Flags().BoolVar(&o.oomKilled, "oom-killed", false, "Show pods with a container whose last termination was OOMKilled")
+	cmd.Flags().StringSliceVar(&o.excludeNamespaces, "exclude-namespaces", nil, "Hide pods in these namespaces (comma-separated)")
+	cmd.Flags().StringSliceVar(&o.contexts, "contexts", nil, "Collect from several kubeconfig contexts concurrently and merge the results (comma-separated)")
+	cmd.Flags().BoolVar(&o.allContexts, "all-contexts", false, "Collect from every kubeconfig context concurrently and merge the results")
+	cmd.Flags().BoolVar(&o.assumeLimitRangeDefaults, "assume-limitrange-defaults", false, "Compute percentages against namespace LimitRange defaults for containers without requests or limits")
+	cmd.Flags().BoolVar(&o.throttling, "throttling", false, "Read CPU throttling and pressure from the kubelet cadvisor endpoint (needs nodes/proxy); adds THROTTLED% next to CPU_LIM%")
+	cmd.Flags().BoolVar(&o.storage, "storage", false, "Read ephemeral storage usage from the kubelet stats summary of every node (needs nodes/proxy); adds EPH columns")
+	cmd.Flags().StringVar(&o.pricingFile, "pricing", "", "YAML file with per-core-hour and per-GiB-hour rates; shows the monthly cost of requests, usage and idle requests")
+	cmd.PersistentFlags().StringVar(&o.configFile, "config", "", "Config file with default flags, colors and pricing (default: ~/.config/kubectl-resource-usage/config.yaml)")
+	cmd.PersistentFlags().StringVar(&o.profile, "profile", "", "Named profile from the config file to apply over its defaults")
+
+	// Alert flags
+	cmd.Flags().StringArrayVar(&o.alertRules, "alert", nil, "Alert rule for watch mode, e.g. 'memory.limitPercent >= 90 for 2m' (repeatable)")
+	cmd.Flags().StringVar(&o.alertExec, "alert-exec", "", "Shell command run when a pod enters or leaves an alert (event JSON on stdin, ALERT_* env vars)")
+	cmd.Flags().StringVar(&o.alertWebhook, "alert-webhook", "", "URL to POST a JSON payload to when a pod enters or leaves an alert")
+	cmd.Flags().BoolVar(&o.alertBanner, "alert-banner", false, "Show a banner when a pod enters or leaves an alert (default if no other action is set)")
+	cmd.Flags().DurationVar(&o.alertCooldown, "alert-cooldown", 30*time.Second, "How long a firing alert's condition must stay false before it resolves")
+
+	// Add subcommands
 	cmd.AddCommand(NewCmdCompletion())
+	cmd.AddCommand(NewCmdServe(streams))
+	cmd.AddCommand(NewCmdCheck(streams))
+	cmd.AddCommand(NewCmdAudit(streams))
+	cmd.AddCommand(NewCmdNodes(streams))
+	cmd.AddCommand(NewCmdCost(streams))
+	cmd.AddCommand(NewCmdChargeback(streams))
+	cmd.AddCommand(NewCmdHPA(streams))
+	cmd.AddCommand(NewCmdVPA(streams))
 
 	return cmd
 }
 
-// Complete fills in any fields not set by flags
+// Complete applies the config file to flags not set on the command line and loads the pricing
 func (o *ResourceUsageOptions) Complete(cmd *cobra.Command) error {
+	profile, err := completeProfile(cmd)
+	if err != nil {
+		return err
+	}
+	if profile.Colors != nil {
+		scheme, err := profile.Colors.Scheme()
+		if err != nil {
+			return err
+		}
+		o.colors = scheme
+	}
+	if o.palette != "" {
+		o.colors.Palette = output.Palette(o.palette)
+	}
+
+	if o.pricingFile != "" {
+		pricing, err := cost.LoadPricing(o.pricingFile)
+		if err != nil {
+			return err
+		}
+		o.pricing = pricing
+	} else {
+		o.pricing = profile.Pricing
+	}
+	return nil
+}
+
+// completeProfile resolves the profile selected by --config and --profile and
+// sets the flags of cmd it holds. Subcommands take their flags from the
+// profile's commands section, under their name.
+func completeProfile(cmd *cobra.Command) (config.Profile, error) {
+	profile, err := loadProfile(flagString(cmd, "config"), flagString(cmd, "profile"))
+	if err != nil {
+		return config.Profile{}, err
+	}
+	for _, name := range profile.CommandNames() {
+		if !hasSubcommand(cmd.Root(), name) {
+			return config.Profile{}, fmt.Errorf("invalid config: unknown command %q", name)
+		}
+	}
+	if cmd.HasParent() {
+		profile = profile.Command(cmd.Name())
+	}
+	if err := applyProfileFlags(cmd.Flags(), profile); err != nil {
+		return config.Profile{}, err
+	}
+	return profile, nil
+}
+
+// loadProfile reads the config file, the default one if configFile is empty,
+// and resolves the named profile
+func loadProfile(configFile, name string) (config.Profile, error) {
+	var cfg *config.Config
+	var err error
+	if configFile != "" {
+		cfg, err = config.Load(configFile)
+	} else {
+		cfg, err = config.LoadDefault()
+	}
+	if err != nil {
+		return config.Profile{}, err
+	}
+	return cfg.Resolve(name)
+}
+
+// flagString returns the value of a string flag, or "" if cmd has no such flag
+func flagString(cmd *cobra.Command, name string) string {
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag.Value.String()
+	}
+	return ""
+}
+
+// hasSubcommand reports whether cmd has a subcommand called name
+func hasSubcommand(cmd *cobra.Command, name string) bool {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfileFlags sets the flags of a profile that were not set on the command line
+func applyProfileFlags(flags *pflag.FlagSet, profile config.Profile) error {
+	for _, name := range profile.FlagNames() {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "config" || name == "profile" {
+			return fmt.Errorf("invalid config: unknown flag %q", name)
+		}
+		if flag.Changed {
+			continue
+		}
+		values := profile.Flags[name]
+		if _, ok := flag.Value.(pflag.SliceValue); !ok && len(values) > 1 {
+			return fmt.Errorf("invalid config value for --%s: a list is only allowed for repeatable flags", name)
+		}
+		for _, value := range values {
+			if err := flags.Set(name, value); err != nil {
+				return fmt.Errorf("invalid config value for --%s: %w", name, err)
+			}
+		}
+	}
 	return nil
 }
 
 // Validate validates the options
 func (o *ResourceUsageOptions) Validate() error {
-	if o.selector != "" {
-		if _, err := labels.Parse(o.selector); err != nil {
-			return fmt.Errorf("invalid label selector: %w", err)
-		}
+	if err := o.query().Validate(); err != nil {
+		return err
+	}
+	if slices.Contains(calculator.CostSortFields(), o.sortBy) && o.pricingFile == "" && o.pricing == nil {
+		return fmt.Errorf("--sort %s requires --pricing", o.sortBy)
 	}
-	if o.sortBy != "" && o.sortBy != "cpu" && o.sortBy != "memory" {
-		return fmt.Errorf("invalid sort field: %s (must be 'cpu' or 'memory')", o.sortBy)
+	if o.sortBy == calculator.SortByThrottled && !o.throttling {
+		return fmt.Errorf("--sort %s requires --throttling", o.sortBy)
 	}
-	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true, "wide": true}
-	if !validOutputs[o.output] {
-		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', 'yaml', or 'wide')", o.output)
+	if o.sortBy == string(corev1.ResourceEphemeralStorage) && !o.storage {
+		return fmt.Errorf("--sort %s requires --storage", o.sortBy)
+	}
+	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true, "wide": true, "ndjson": true, "html": true, "markdown": true}
+	if output.IsTemplateFormat(o.output) {
+		if _, err := output.NewTemplateFormatter(o.output); err != nil {
+			return fmt.Errorf("invalid output format: %w", err)
+		}
+	} else if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', 'yaml', 'wide', 'ndjson', 'html', 'markdown', or one of %v with =<template>)", o.output, output.TemplateFormats())
 	}
 	validColors := map[string]bool{"auto": true, "always": true, "never": true}
 	if !validColors[o.color] {
 		return fmt.Errorf("invalid color mode: %s (must be 'auto', 'always', or 'never')", o.color)
 	}
-	if !output.IsValidUnit(o.unit) {
-		return fmt.Errorf("invalid unit: %s (must be one of: %v)", o.unit, output.ValidUnits())
+	if o.markers != "" && !output.IsValidMarkerStyle(o.markers) {
+		return fmt.Errorf("invalid markers: %s (must be one of: %v)", o.markers, output.ValidMarkerStyles())
 	}
-	if o.above != -1 && (o.above < 0 || o.above > 100) {
-		return fmt.Errorf("invalid --above value: %d (must be between 0 and 100)", o.above)
+	if o.palette != "" && !slices.Contains(output.ValidPalettes(), o.palette) {
+		return fmt.Errorf("invalid palette: %s (must be one of: %v)", o.palette, output.ValidPalettes())
 	}
-	if o.below != -1 && (o.below < 0 || o.below > 100) {
-		return fmt.Errorf("invalid --below value: %d (must be between 0 and 100)", o.below)
+	if !output.IsValidUnit(o.unit) {
+		return fmt.Errorf("invalid unit: %s (must be one of: %v)", o.unit, output.ValidUnits())
 	}
-	if o.above != -1 && o.below != -1 && o.above > o.below {
-		return fmt.Errorf("--above (%d) cannot be greater than --below (%d)", o.above, o.below)
+	if err := validatePrecision(o.precision); err != nil {
+		return err
 	}
-	if o.noLimits && (o.above != -1 || o.below != -1) {
-		return fmt.Errorf("--no-limits cannot be used with --above or --below")
+	if o.watch && (o.output == "json" || o.output == "yaml" || o.output == "html") {
+		return fmt.Errorf("watch mode is not supported with %s output format (use -o ndjson to stream samples)", o.output)
 	}
-	if o.watch && (o.output == "json" || o.output == "yaml") {
-		return fmt.Errorf("watch mode is not supported with %s output format", o.output)
+	if o.interactive && o.output != "table" {
+		return fmt.Errorf("--interactive cannot be used with -o %s", o.output)
 	}
 	if o.interval < time.Second {
 		return fmt.Errorf("interval must be at least 1 second")
 	}
+	if err := o.validateContexts(); err != nil {
+		return err
+	}
+	return o.validateAlerts()
+}
+
+// validateContexts validates the multi-cluster flags
+func (o *ResourceUsageOptions) validateContexts() error {
+	if len(o.contexts) > 0 && o.allContexts {
+		return fmt.Errorf("--contexts and --all-contexts cannot be used together")
+	}
+	if !o.multiCluster() {
+		return nil
+	}
+	if o.configFlags != nil {
+		// These select a single cluster; the other connection flags apply to every context
+		singleCluster := []struct {
+			flag  string
+			value *string
+		}{
+			{"context", o.configFlags.Context},
+			{"cluster", o.configFlags.ClusterName},
+			{"server", o.configFlags.APIServer},
+		}
+		for _, f := range singleCluster {
+			if f.value != nil && *f.value != "" {
+				return fmt.Errorf("--%s cannot be used with --contexts or --all-contexts", f.flag)
+			}
+		}
+	}
+	for _, name := range o.contexts {
+		if name == "" {
+			return fmt.Errorf("invalid --contexts: context names must not be empty")
+		}
+	}
 	return nil
 }
 
-// Run executes the resource-usage command
-func (o *ResourceUsageOptions) Run(ctx context.Context) error {
-	// Create REST config from flags
-	restConfig, err := o.configFlags.ToRESTConfig()
-	if err != nil {
-		return fmt.Errorf("failed to create REST config: %w", err)
+// multiCluster reports whether pods are collected from several kubeconfig contexts
+func (o *ResourceUsageOptions) multiCluster() bool {
+	return len(o.contexts) > 0 || o.allContexts
+}
+
+// validateAlerts validates the alert rules and actions
+func (o *ResourceUsageOptions) validateAlerts() error {
+	if len(o.alertRules) == 0 {
+		if o.alertExec != "" || o.alertWebhook != "" || o.alertBanner {
+			return fmt.Errorf("--alert-exec, --alert-webhook and --alert-banner require --alert")
+		}
+		return nil
+	}
+	if !o.watch {
+		return fmt.Errorf("--alert requires --watch")
+	}
+	for _, r := range o.alertRules {
+		rule, err := alert.ParseRule(r)
+		if err != nil {
+			return err
+		}
+		if rule.Resource == string(corev1.ResourceEphemeralStorage) && !o.storage {
+			return fmt.Errorf("--alert %q requires --storage", r)
+		}
 	}
+	if o.alertWebhook != "" {
+		u, err := url.Parse(o.alertWebhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid --alert-webhook: %s (must be an http or https URL)", o.alertWebhook)
+		}
+	}
+	if o.alertCooldown < 0 {
+		return fmt.Errorf("invalid --alert-cooldown: %s (must not be negative)", o.alertCooldown)
+	}
+	return nil
+}
 
+// Run executes the resource-usage command
+func (o *ResourceUsageOptions) Run(ctx context.Context) error {
 	// Get namespace from config flags (empty string means all namespaces)
 	namespace := ""
 	if o.configFlags.Namespace != nil && *o.configFlags.Namespace != "" {
@@ -188,121 +526,397 @@ func (o *ResourceUsageOptions) Run(ctx context.Context) error {
 	}
 
 	// Create collectors
-	metricsCollector, err := collector.NewMetricsCollector(restConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create metrics collector: %w", err)
-	}
-
-	podCollector, err := collector.NewPodCollector(restConfig)
+	collectors, err := o.newUsageSource()
 	if err != nil {
-		return fmt.Errorf("failed to create pod collector: %w", err)
+		return err
 	}
 
 	// Create formatter options
 	opts := output.FormatterOptions{
 		ColorMode: output.ColorMode(o.color),
 		Unit:      o.unit,
+		Markers:   output.MarkerStyle(o.markers),
+		Summary:   o.reportSummary(namespace),
+		Currency:  o.currency(),
+		Colors:    o.colors,
+		Trends:    o.watch && !output.IsStreamingFormat(o.output),
+		Precision: o.precision,
+	}
+	var formatter output.Formatter
+	if output.IsTemplateFormat(o.output) {
+		formatter, err = output.NewTemplateFormatter(o.output)
+		if err != nil {
+			return fmt.Errorf("failed to create formatter: %w", err)
+		}
+	} else {
+		formatter = output.NewFormatter(o.output, opts)
+	}
+
+	// Interactive mode: terminal UI refreshing every interval
+	if o.interactive {
+		return o.runInteractive(ctx, collectors, namespace, opts)
 	}
-	formatter := output.NewFormatter(o.output, opts)
 
-	// If not watch mode, run once
+	// If not watch mode, run once; clusters that failed are only warned about
 	if !o.watch {
-		return o.runOnce(ctx, metricsCollector, podCollector, namespace, formatter)
+		err := o.runOnce(ctx, o.Out, collectors, namespace, formatter)
+		var failed clusterErrors
+		if errors.As(err, &failed) {
+			o.warnClusterErrors(failed)
+			return nil
+		}
+		return err
 	}
 
 	// Watch mode: loop until context is cancelled
-	return o.runWatch(ctx, metricsCollector, podCollector, namespace, formatter)
+	if len(o.alertRules) > 0 {
+		o.alerts = o.newWatchAlerts()
+		// Deliver the events still queued, such as resolutions, before exiting
+		defer func() {
+			if err := o.alerts.close(); err != nil {
+				_, _ = fmt.Fprintf(o.ErrOut, "Alert error: %v\n", err)
+			}
+		}()
+	}
+	return o.runWatch(ctx, collectors, namespace, formatter)
 }
 
-// runOnce fetches and displays data once
-func (o *ResourceUsageOptions) runOnce(ctx context.Context, metricsCollector *collector.MetricsCollector, podCollector *collector.PodCollector, namespace string, formatter output.Formatter) error {
-	// Add timeout to prevent hanging on slow API responses
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+// newUsageSource creates the collectors of the current context, or of each
+// context given by --contexts or --all-contexts
+func (o *ResourceUsageOptions) newUsageSource() (usageSource, error) {
+	if o.multiCluster() {
+		rawConfig, err := o.configFlags.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		contexts, err := kubeconfigContexts(rawConfig, o.contexts, o.allContexts)
+		if err != nil {
+			return nil, err
+		}
+		return newClusterSet(rawConfig, contexts, contextOverrides(o.configFlags), o.clientOptions()), nil
+	}
 
-	// Fetch pod metrics
-	podMetrics, err := metricsCollector.GetPodMetrics(ctx, namespace)
+	// Create REST config from flags
+	restConfig, err := o.configFlags.ToRESTConfig()
 	if err != nil {
-		return fmt.Errorf("failed to get pod metrics: %w", err)
+		return nil, fmt.Errorf("failed to create REST config: %w", err)
+	}
+	client, err := usage.NewClient(restConfig, o.clientOptions())
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// clientOptions enables the LimitRange, cadvisor, stats and pricing sources requested by flags
+func (o *ResourceUsageOptions) clientOptions() usage.Options {
+	return usage.Options{
+		AssumeLimitRangeDefaults: o.assumeLimitRangeDefaults,
+		Throttling:               o.throttling,
+		Pricing:                  o.pricing,
+		Storage:                  o.storage,
+		Warn:                     o.warnNodes,
+	}
+}
+
+// warnNodes reports the first kubelets that could not be read on stderr
+func (o *ResourceUsageOptions) warnNodes(err error) {
+	o.nodeWarning.Do(func() {
+		_, _ = fmt.Fprintf(o.ErrOut, "Warning: %s\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+	})
+}
+
+// currency returns the symbol costs are shown in, empty without pricing
+func (o *ResourceUsageOptions) currency() string {
+	if o.pricing == nil {
+		return ""
 	}
+	return o.pricing.CurrencySymbol()
+}
 
-	// Fetch pods with label selector
-	pods, err := podCollector.GetPods(ctx, namespace, o.selector)
+// reportSummary describes the cluster, context and namespace being reported on
+func (o *ResourceUsageOptions) reportSummary(namespace string) output.ReportSummary {
+	summary := output.ReportSummary{Namespace: namespace}
+
+	rawConfig, err := o.configFlags.ToRawKubeConfigLoader().RawConfig()
 	if err != nil {
-		return fmt.Errorf("failed to get pods: %w", err)
+		return summary
+	}
+
+	if o.multiCluster() {
+		contexts, err := kubeconfigContexts(rawConfig, o.contexts, o.allContexts)
+		if err != nil {
+			return summary
+		}
+		clusters := make([]string, 0, len(contexts))
+		for _, name := range contexts {
+			clusters = append(clusters, rawConfig.Contexts[name].Cluster)
+		}
+		summary.Context = strings.Join(contexts, ", ")
+		summary.Cluster = strings.Join(clusters, ", ")
+		return summary
 	}
 
-	// Build pod map for quick lookup
-	podMap := make(map[string]int)
-	for i, pod := range pods.Items {
-		key := pod.Namespace + "/" + pod.Name
-		podMap[key] = i
+	summary.Context = rawConfig.CurrentContext
+	if o.configFlags.Context != nil && *o.configFlags.Context != "" {
+		summary.Context = *o.configFlags.Context
+	}
+	if kubeContext, ok := rawConfig.Contexts[summary.Context]; ok {
+		summary.Cluster = kubeContext.Cluster
+	}
+	if o.configFlags.ClusterName != nil && *o.configFlags.ClusterName != "" {
+		summary.Cluster = *o.configFlags.ClusterName
 	}
+	return summary
+}
 
-	// Calculate usage for each pod
-	podUsages := make([]calculator.PodUsage, 0, len(podMetrics.Items))
-	for _, pm := range podMetrics.Items {
-		key := pm.Namespace + "/" + pm.Name
-		podIndex, exists := podMap[key]
-		if !exists {
-			continue
+// newWatchAlerts builds the alert manager and actions from the alert flags.
+// Streams get banners on stderr; redrawn frames show them below the header.
+func (o *ResourceUsageOptions) newWatchAlerts() *watchAlerts {
+	rules := make([]alert.Rule, 0, len(o.alertRules))
+	for _, r := range o.alertRules {
+		rule, _ := alert.ParseRule(r) // validated in Validate
+		rules = append(rules, rule)
+	}
+
+	wa := &watchAlerts{}
+	if o.alertExec != "" {
+		wa.async = append(wa.async, alert.NewAsyncAction(&alert.CommandAction{Command: o.alertExec}, alertActionTimeout))
+	}
+	if o.alertWebhook != "" {
+		wa.async = append(wa.async, alert.NewAsyncAction(alert.NewWebhookAction(o.alertWebhook), alertActionTimeout))
+	}
+	actions := make([]alert.Action, 0, len(wa.async)+1)
+	for _, a := range wa.async {
+		actions = append(actions, a)
+	}
+	if o.alertBanner || len(actions) == 0 {
+		wa.banner = &alert.Banner{}
+		if output.IsStreamingFormat(o.output) {
+			wa.banner.Out = o.ErrOut
 		}
-		podUsage := calculator.CalculatePodUsage(pm, pods.Items[podIndex])
-		podUsages = append(podUsages, podUsage)
+		actions = append(actions, wa.banner)
 	}
 
-	// Apply filters
-	filterField := o.sortBy
-	if filterField == "" {
-		filterField = "memory"
+	wa.manager = alert.NewManager(rules, actions, o.alertCooldown)
+	return wa
+}
+
+// runOnce fetches and displays data once
+func (o *ResourceUsageOptions) runOnce(ctx context.Context, w io.Writer, collectors usageSource, namespace string, formatter output.Formatter) error {
+	// Add timeout to prevent hanging on slow API responses
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Clusters that failed while others answered are reported after the output
+	podUsages, collectErr := collectors.CollectPodUsages(ctx, namespace, o.selector)
+	var failed clusterErrors
+	if collectErr != nil && !errors.As(collectErr, &failed) {
+		return collectErr
 	}
-	filterOpts := calculator.FilterOptions{
-		Above:    o.above,
-		Below:    o.below,
-		NoLimits: o.noLimits,
-		Field:    filterField,
+
+	// Alerts see every pod, so filtering a pod out of the view does not resolve its alert
+	if o.alerts != nil {
+		o.alerts.evaluate(ctx, podUsages, failed)
 	}
-	podUsages = calculator.FilterPodUsages(podUsages, filterOpts)
+	podUsages = o.query().Apply(podUsages)
 
-	// Handle empty results
+	// Handle empty results; streams stay machine-readable and emit nothing
 	if len(podUsages) == 0 {
-		_, _ = fmt.Fprintln(o.Out, "No pods found matching the criteria")
-		return nil
+		if output.IsStreamingFormat(o.output) {
+			return collectErr
+		}
+		_, _ = fmt.Fprintln(w, "No pods found matching the criteria")
+		return collectErr
 	}
 
-	// Sort if requested
-	if o.sortBy != "" {
-		calculator.SortPodUsages(podUsages, o.sortBy, o.ascending)
+	if err := formatter.Format(w, podUsages); err != nil {
+		return err
 	}
+	return collectErr
+}
 
-	return formatter.Format(o.Out, podUsages)
+// warnClusterErrors reports the clusters that failed on stderr
+func (o *ResourceUsageOptions) warnClusterErrors(failed clusterErrors) {
+	for _, ce := range failed {
+		_, _ = fmt.Fprintf(o.ErrOut, "Warning: cluster %s: %v\n", ce.Context, ce.Err)
+	}
 }
 
 // runWatch runs in watch mode with periodic refresh
-func (o *ResourceUsageOptions) runWatch(ctx context.Context, metricsCollector *collector.MetricsCollector, podCollector *collector.PodCollector, namespace string, formatter output.Formatter) error {
+func (o *ResourceUsageOptions) runWatch(ctx context.Context, collectors usageSource, namespace string, formatter output.Formatter) error {
 	ticker := time.NewTicker(o.interval)
 	defer ticker.Stop()
 
-	// Run immediately first time
-	o.clearScreen()
-	if err := o.runOnce(ctx, metricsCollector, podCollector, namespace, formatter); err != nil {
-		_, _ = fmt.Fprintf(o.ErrOut, "Error: %v\n", err)
+	refresh := o.streamRefresh
+	if !output.IsStreamingFormat(o.output) {
+		refresh = o.frameRefresh(output.NewWatchRenderer(o.Out))
 	}
 
+	// Run immediately first time
+	refresh(ctx, collectors, namespace, formatter)
+
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			// Interrupting is how watch mode ends
+			return nil
 		case <-ticker.C:
-			o.clearScreen()
-			if err := o.runOnce(ctx, metricsCollector, podCollector, namespace, formatter); err != nil {
-				_, _ = fmt.Fprintf(o.ErrOut, "Error: %v\n", err)
+			refresh(ctx, collectors, namespace, formatter)
+		}
+	}
+}
+
+// watchRefreshFunc performs a single watch tick
+type watchRefreshFunc func(ctx context.Context, collectors usageSource, namespace string, formatter output.Formatter)
+
+// streamRefresh appends one sample to the stream, reporting errors on stderr
+func (o *ResourceUsageOptions) streamRefresh(ctx context.Context, collectors usageSource, namespace string, formatter output.Formatter) {
+	err := o.runOnce(ctx, o.Out, collectors, namespace, formatter)
+	var failed clusterErrors
+	if errors.As(err, &failed) {
+		o.warnClusterErrors(failed)
+	} else if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "Error: %v\n", err)
+	}
+	if o.alerts != nil && o.alerts.lastErr != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "Alert error: %v\n", o.alerts.lastErr)
+	}
+}
+
+// frameRefresh returns a refresh that builds each frame in memory and redraws
+// it in place. Failed ticks keep the last good frame and show the error below the header.
+func (o *ResourceUsageOptions) frameRefresh(renderer *output.WatchRenderer) watchRefreshFunc {
+	var (
+		errorCount int
+		lastBody   []string
+	)
+	return func(ctx context.Context, collectors usageSource, namespace string, formatter output.Formatter) {
+		var buf bytes.Buffer
+		err := o.runOnce(ctx, &buf, collectors, namespace, formatter)
+		now := time.Now()
+
+		// A frame missing some clusters is still shown, with a warning per cluster
+		var failed clusterErrors
+		partial := errors.As(err, &failed)
+		if err != nil && !partial {
+			errorCount++
+		} else {
+			lastBody = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		}
+
+		lines := []string{o.watchHeader(now, errorCount)}
+		if partial {
+			for _, ce := range failed {
+				lines = append(lines, fmt.Sprintf("Warning: cluster %s: %v", ce.Context, ce.Err))
 			}
+		} else if err != nil {
+			lines = append(lines, "Error: "+err.Error())
+		}
+		lines = append(lines, o.alertLines()...)
+		lines = append(lines, "")
+		lines = append(lines, lastBody...)
+
+		if height := o.terminalHeight(); height > 0 && len(lines) >= height {
+			lines = lines[:height-1]
+		}
+		if err := renderer.Render(lines); err != nil {
+			_, _ = fmt.Fprintf(o.ErrOut, "Error: %v\n", err)
 		}
 	}
 }
 
-// clearScreen clears the terminal screen
-func (o *ResourceUsageOptions) clearScreen() {
-	_, _ = fmt.Fprint(o.Out, "\033[H\033[2J")
+// watchHeader describes the last refresh, the number of failed refreshes and when the next one is due
+func (o *ResourceUsageOptions) watchHeader(now time.Time, errorCount int) string {
+	return fmt.Sprintf("Every %s | last refresh: %s | errors: %d | next refresh: %s",
+		o.interval, now.Format("15:04:05"), errorCount, now.Add(o.interval).Format("15:04:05"))
+}
+
+// alertLines returns the recent alert banners and the last alert action error for the watch frame
+func (o *ResourceUsageOptions) alertLines() []string {
+	if o.alerts == nil {
+		return nil
+	}
+
+	var lines []string
+	if o.alerts.banner != nil {
+		for _, msg := range o.alerts.banner.Messages() {
+			lines = append(lines, "*** "+msg+" ***")
+		}
+	}
+	if o.alerts.lastErr != nil {
+		lines = append(lines, "Alert error: "+strings.ReplaceAll(o.alerts.lastErr.Error(), "\n", "; "))
+	}
+	return lines
+}
+
+// terminalHeight returns the height of the output terminal, or 0 if output is not a terminal
+func (o *ResourceUsageOptions) terminalHeight() int {
+	f, ok := o.Out.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// runInteractive runs the interactive terminal UI until the user quits
+func (o *ResourceUsageOptions) runInteractive(ctx context.Context, collectors usageSource, namespace string, opts output.FormatterOptions) error {
+	in, inOK := o.In.(*os.File)
+	out, outOK := o.Out.(*os.File)
+	if !inOK || !outOK {
+		return fmt.Errorf("interactive mode requires a terminal")
+	}
+
+	model := tui.NewModel(output.NewUnitFormatter(opts.Unit), output.NewColorizerWithScheme(opts.ColorMode, opts.Colors).WithPrecision(opts.Precision))
+	fetch := func(ctx context.Context) ([]calculator.PodUsage, error) {
+		return o.fetchPodUsages(ctx, collectors, namespace)
+	}
+	return tui.Run(ctx, in, out, o.interval, fetch, model)
+}
+
+// fetchPodUsages collects pod usages and applies the filter and sort flags
+func (o *ResourceUsageOptions) fetchPodUsages(ctx context.Context, collectors usageSource, namespace string) ([]calculator.PodUsage, error) {
+	podUsages, err := collectors.CollectPodUsages(ctx, namespace, o.selector)
+	if podUsages == nil {
+		return nil, err
+	}
+	return o.query().Apply(podUsages), err
+}
+
+// query converts the filter and sort flags to a usage query
+func (o *ResourceUsageOptions) query() usage.Query {
+	q := usage.Query{
+		Selector:    o.selector,
+		SortBy:      o.sortBy,
+		Ascending:   o.ascending,
+		NoLimits:    o.noLimits,
+		QOSClass:    o.qosClass,
+		Phase:       o.phase,
+		MinRestarts: o.minRestarts,
+		OOMKilled:   o.oomKilled,
+
+		ExcludeNamespaces: o.excludeNamespaces,
+	}
+	if above := o.above; above != -1 {
+		q.Above = &above
+	}
+	if below := o.below; below != -1 {
+		q.Below = &below
+	}
+	return q
+}
+
+// maxPrecision is the most decimal places --precision accepts
+const maxPrecision = 6
+
+// validatePrecision checks the decimal places of percentages
+func validatePrecision(precision int) error {
+	if precision < 0 || precision > maxPrecision {
+		return fmt.Errorf("invalid precision: %d (must be between 0 and %d)", precision, maxPrecision)
+	}
+	return nil
 }
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/alert"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
@@ -78,7 +83,7 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 				interval: 2 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "--above (80) cannot be greater than --below (50)",
+			errMsg:  "above (80) cannot be greater than below (50)",
 		},
 		{
 			name: "above out of range",
@@ -91,7 +96,7 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 				interval: 2 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "invalid --above value",
+			errMsg:  "invalid above value",
 		},
 		{
 			name: "below out of range",
@@ -104,7 +109,7 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 				interval: 2 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "invalid --below value",
+			errMsg:  "invalid below value",
 		},
 		{
 			name: "no-limits with above",
@@ -118,7 +123,7 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 				interval: 2 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "--no-limits cannot be used with --above or --below",
+			errMsg:  "no limits cannot be used with above or below",
 		},
 		{
 			name: "no-limits with below",
@@ -132,7 +137,7 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 				interval: 2 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "--no-limits cannot be used with --above or --below",
+			errMsg:  "no limits cannot be used with above or below",
 		},
 		{
 			name: "watch mode with json output",
@@ -162,6 +167,19 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "watch mode is not supported with yaml output format",
 		},
+		{
+			name: "watch mode with ndjson output",
+			opts: &ResourceUsageOptions{
+				output:   "ndjson",
+				color:    "auto",
+				unit:     "auto",
+				watch:    true,
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: false,
+		},
 		{
 			name: "interval too short",
 			opts: &ResourceUsageOptions{
@@ -224,6 +242,138 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid custom-columns output",
+			opts: &ResourceUsageOptions{
+				output:   "custom-columns=NAME:.pod,MEM:.memory.limitPercent",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid jsonpath output",
+			opts: &ResourceUsageOptions{
+				output:   "jsonpath={.items[*].pod}",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid jsonpath template",
+			opts: &ResourceUsageOptions{
+				output:   "jsonpath={.items[",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "invalid output format",
+		},
+		{
+			name: "valid markdown output",
+			opts: &ResourceUsageOptions{
+				output:   "markdown",
+				color:    "auto",
+				unit:     "auto",
+				markers:  "text",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid markers",
+			opts: &ResourceUsageOptions{
+				output:   "markdown",
+				color:    "auto",
+				unit:     "auto",
+				markers:  "stars",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "invalid markers",
+		},
+		{
+			name: "valid precision",
+			opts: &ResourceUsageOptions{
+				output:    "table",
+				color:     "auto",
+				unit:      "auto",
+				precision: 2,
+				above:     -1,
+				below:     -1,
+				interval:  2 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid precision",
+			opts: &ResourceUsageOptions{
+				output:    "table",
+				color:     "auto",
+				unit:      "auto",
+				precision: 7,
+				above:     -1,
+				below:     -1,
+				interval:  2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "invalid precision: 7 (must be between 0 and 6)",
+		},
+		{
+			name: "watch mode with html output",
+			opts: &ResourceUsageOptions{
+				output:   "html",
+				color:    "auto",
+				unit:     "auto",
+				watch:    true,
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "watch mode is not supported with html output format",
+		},
+		{
+			name: "interactive with json output",
+			opts: &ResourceUsageOptions{
+				output:      "json",
+				color:       "auto",
+				unit:        "auto",
+				interactive: true,
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "--interactive cannot be used with -o json",
+		},
+		{
+			name: "valid interactive",
+			opts: &ResourceUsageOptions{
+				output:      "table",
+				color:       "auto",
+				unit:        "auto",
+				interactive: true,
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid above and below",
 			opts: &ResourceUsageOptions{
@@ -249,6 +399,282 @@ func TestResourceUsageOptions_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid alert rule with webhook",
+			opts: &ResourceUsageOptions{
+				output:       "table",
+				color:        "auto",
+				unit:         "auto",
+				above:        -1,
+				below:        -1,
+				watch:        true,
+				interval:     2 * time.Second,
+				alertRules:   []string{"memory.limitPercent >= 90 for 2m"},
+				alertWebhook: "http://localhost:8080/hook",
+			},
+			wantErr: false,
+		},
+		{
+			name: "alert without watch",
+			opts: &ResourceUsageOptions{
+				output:     "table",
+				color:      "auto",
+				unit:       "auto",
+				above:      -1,
+				below:      -1,
+				interval:   2 * time.Second,
+				alertRules: []string{"memory.limitPercent >= 90"},
+			},
+			wantErr: true,
+			errMsg:  "--alert requires --watch",
+		},
+		{
+			name: "storage alert without storage",
+			opts: &ResourceUsageOptions{
+				output:     "table",
+				color:      "auto",
+				unit:       "auto",
+				above:      -1,
+				below:      -1,
+				interval:   2 * time.Second,
+				watch:      true,
+				alertRules: []string{"ephemeral-storage.limitPercent >= 90"},
+			},
+			wantErr: true,
+			errMsg:  "requires --storage",
+		},
+		{
+			name: "invalid alert rule",
+			opts: &ResourceUsageOptions{
+				output:     "table",
+				color:      "auto",
+				unit:       "auto",
+				above:      -1,
+				below:      -1,
+				watch:      true,
+				interval:   2 * time.Second,
+				alertRules: []string{"memory.limitPercent about 90"},
+			},
+			wantErr: true,
+			errMsg:  "invalid rule operator",
+		},
+		{
+			name: "invalid alert webhook",
+			opts: &ResourceUsageOptions{
+				output:       "table",
+				color:        "auto",
+				unit:         "auto",
+				above:        -1,
+				below:        -1,
+				watch:        true,
+				interval:     2 * time.Second,
+				alertRules:   []string{"memory.limitPercent >= 90"},
+				alertWebhook: "localhost:8080",
+			},
+			wantErr: true,
+			errMsg:  "invalid --alert-webhook",
+		},
+		{
+			name: "alert action without rule",
+			opts: &ResourceUsageOptions{
+				output:    "table",
+				color:     "auto",
+				unit:      "auto",
+				above:     -1,
+				below:     -1,
+				interval:  2 * time.Second,
+				alertExec: "echo alert",
+			},
+			wantErr: true,
+			errMsg:  "require --alert",
+		},
+		{
+			name: "valid status filters and restart sort",
+			opts: &ResourceUsageOptions{
+				output:      "wide",
+				color:       "auto",
+				unit:        "auto",
+				sortBy:      "restarts",
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+				qosClass:    "burstable",
+				phase:       "Running",
+				minRestarts: 2,
+				oomKilled:   true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid qos class",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+				qosClass: "premium",
+			},
+			wantErr: true,
+			errMsg:  "invalid QoS class",
+		},
+		{
+			name: "invalid phase",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+				phase:    "Crashing",
+			},
+			wantErr: true,
+			errMsg:  "invalid phase",
+		},
+		{
+			name: "negative min restarts",
+			opts: &ResourceUsageOptions{
+				output:      "table",
+				color:       "auto",
+				unit:        "auto",
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+				minRestarts: -1,
+			},
+			wantErr: true,
+			errMsg:  "invalid min restarts",
+		},
+		{
+			name: "cost sort without pricing",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				sortBy:   "idle-cost",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "--sort idle-cost requires --pricing",
+		},
+		{
+			name: "throttled sort without throttling",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				sortBy:   "throttled",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "--sort throttled requires --throttling",
+		},
+		{
+			name: "storage sort without storage",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				sortBy:   "ephemeral-storage",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+			},
+			wantErr: true,
+			errMsg:  "--sort ephemeral-storage requires --storage",
+		},
+		{
+			name: "throttled sort",
+			opts: &ResourceUsageOptions{
+				output:     "table",
+				color:      "auto",
+				unit:       "auto",
+				sortBy:     "throttled",
+				throttling: true,
+				above:      10,
+				below:      -1,
+				interval:   2 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid contexts",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+				contexts: []string{"prod-eu", "prod-us"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "contexts with all-contexts",
+			opts: &ResourceUsageOptions{
+				output:      "table",
+				color:       "auto",
+				unit:        "auto",
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+				contexts:    []string{"prod-eu"},
+				allContexts: true,
+			},
+			wantErr: true,
+			errMsg:  "cannot be used together",
+		},
+		{
+			name: "all-contexts with context",
+			opts: &ResourceUsageOptions{
+				configFlags: &genericclioptions.ConfigFlags{Context: stringPtr("prod-eu")},
+				output:      "table",
+				color:       "auto",
+				unit:        "auto",
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+				allContexts: true,
+			},
+			wantErr: true,
+			errMsg:  "--context cannot be used with --contexts or --all-contexts",
+		},
+		{
+			name: "contexts with server",
+			opts: &ResourceUsageOptions{
+				configFlags: &genericclioptions.ConfigFlags{APIServer: stringPtr("https://10.0.0.1:6443")},
+				output:      "table",
+				color:       "auto",
+				unit:        "auto",
+				above:       -1,
+				below:       -1,
+				interval:    2 * time.Second,
+				contexts:    []string{"prod-eu"},
+			},
+			wantErr: true,
+			errMsg:  "--server cannot be used with --contexts or --all-contexts",
+		},
+		{
+			name: "empty context name",
+			opts: &ResourceUsageOptions{
+				output:   "table",
+				color:    "auto",
+				unit:     "auto",
+				above:    -1,
+				below:    -1,
+				interval: 2 * time.Second,
+				contexts: []string{"prod-eu", ""},
+			},
+			wantErr: true,
+			errMsg:  "invalid --contexts",
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,4 +712,54 @@ func TestNewResourceUsageOptions(t *testing.T) {
 	if opts.below != -1 {
 		t.Errorf("expected below -1, got %d", opts.below)
 	}
+	if opts.markers != "emoji" {
+		t.Errorf("expected markers 'emoji', got %q", opts.markers)
+	}
+}
+
+func TestWatchHeader(t *testing.T) {
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	opts := NewResourceUsageOptions(streams)
+	opts.interval = 5 * time.Second
+
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)
+	got := opts.watchHeader(now, 3)
+	want := "Every 5s | last refresh: 15:04:05 | errors: 3 | next refresh: 15:04:10"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// slowAction records events after a delay, like a webhook that takes a while
+type slowAction struct {
+	mu     sync.Mutex
+	events []alert.Event
+}
+
+func (a *slowAction) Fire(_ context.Context, event alert.Event) error {
+	time.Sleep(20 * time.Millisecond)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+func TestWatchAlertsCloseDeliversQueuedEvents(t *testing.T) {
+	rule, _ := alert.ParseRule("memory.limitPercent > 80")
+	action := &slowAction{}
+	async := alert.NewAsyncAction(action, time.Second)
+	wa := &watchAlerts{manager: alert.NewManager([]alert.Rule{rule}, []alert.Action{async}, 0), async: []*alert.AsyncAction{async}}
+
+	percent := 90.0
+	pod := calculator.PodUsage{Namespace: "default", Name: "api", Resources: calculator.ResourceUsages{
+		corev1.ResourceMemory: {LimitPercent: &percent},
+	}}
+	wa.evaluate(context.Background(), []calculator.PodUsage{pod}, nil)
+	if err := wa.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(action.events) != 1 || action.events[0].State != alert.StateFiring {
+		t.Errorf("expected the queued firing event to be delivered on close, got %+v", action.events)
+	}
 }
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/exporter"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// ServeOptions contains the options for the serve command
+type ServeOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	listen   string
+	interval time.Duration
+	selector string
+	storage  bool
+}
+
+// NewServeOptions creates a new ServeOptions with default values
+func NewServeOptions(streams genericclioptions.IOStreams) *ServeOptions {
+	return &ServeOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+		listen:      ":9090",
+		interval:    30 * time.Second,
+	}
+}
+
+// NewCmdServe creates the serve command
+func NewCmdServe(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewServeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Expose pod usage percentages as Prometheus metrics",
+		Long: `Periodically collect pod resource usage and expose it as Prometheus gauges.
+Usage, requests, limits and their ratios are labeled with namespace, pod,
+container and node, so Limit% can be alerted on without recording rules.`,
+		Example: `  # Serve metrics for all namespaces on :9090/metrics
+  kubectl resource-usage serve
+
+  # Serve metrics for one namespace, collecting every 15s
+  kubectl resource-usage serve -n payment --listen :8080 --interval 15s`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVar(&o.listen, "listen", o.listen, "Address to serve metrics on")
+	cmd.Flags().DurationVar(&o.interval, "interval", o.interval, "Collection interval")
+	cmd.Flags().StringVarP(&o.selector, "selector", "l", "", "Filter by label selector (e.g., app=api)")
+	cmd.Flags().BoolVar(&o.storage, "storage", false, "Read ephemeral storage usage from the kubelet stats summary of every node (needs nodes/proxy)")
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *ServeOptions) Validate() error {
+	if o.listen == "" {
+		return fmt.Errorf("--listen must not be empty")
+	}
+	if o.selector != "" {
+		if _, err := labels.Parse(o.selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	if o.interval < time.Second {
+		return fmt.Errorf("interval must be at least 1 second")
+	}
+	return nil
+}
+
+// Run serves metrics until the context is cancelled
+func (o *ServeOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil && *o.configFlags.Namespace != "" {
+		namespace = *o.configFlags.Namespace
+	}
+
+	client, err := usage.NewClient(restConfig, usage.Options{
+		Storage: o.storage,
+		Warn: func(err error) {
+			_, _ = fmt.Fprintf(o.ErrOut, "Warning: %s\n", strings.ReplaceAll(err.Error(), "\n", "; "))
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	exp := exporter.NewExporter()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+
+	server := &http.Server{
+		Addr:              o.listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go o.collectLoop(ctx, exp, func(ctx context.Context) error {
+		podUsages, err := client.CollectPodUsages(ctx, namespace, o.selector)
+		if err != nil {
+			return err
+		}
+		exp.Update(podUsages)
+		return nil
+	})
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	_, _ = fmt.Fprintf(o.ErrOut, "Serving metrics on %s/metrics\n", o.listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	return nil
+}
+
+// collectLoop runs collect immediately and then on every interval until ctx is cancelled
+func (o *ServeOptions) collectLoop(ctx context.Context, exp *exporter.Exporter, collect func(context.Context) error) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		o.collectOnce(ctx, exp, collect)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectOnce runs a single collection with a timeout and records its outcome
+func (o *ServeOptions) collectOnce(ctx context.Context, exp *exporter.Exporter, collect func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := collect(ctx)
+	exp.RecordCollection(time.Since(start), err)
+	if err != nil {
+		_, _ = fmt.Fprintf(o.ErrOut, "Error: %v\n", err)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/exporter"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestServeOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *ServeOptions
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid default options",
+			opts:    NewServeOptions(genericclioptions.IOStreams{}),
+			wantErr: false,
+		},
+		{
+			name:    "empty listen address",
+			opts:    &ServeOptions{listen: "", interval: 30 * time.Second},
+			wantErr: true,
+			errMsg:  "--listen must not be empty",
+		},
+		{
+			name:    "invalid label selector",
+			opts:    &ServeOptions{listen: ":9090", selector: "app in (", interval: 30 * time.Second},
+			wantErr: true,
+			errMsg:  "invalid label selector",
+		},
+		{
+			name:    "interval too short",
+			opts:    &ServeOptions{listen: ":9090", interval: 100 * time.Millisecond},
+			wantErr: true,
+			errMsg:  "interval must be at least 1 second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && tt.errMsg != "" && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestServeOptions_CollectOnceReportsErrors(t *testing.T) {
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	o := NewServeOptions(streams)
+
+	o.collectOnce(context.Background(), exporter.NewExporter(), func(context.Context) error {
+		return errors.New("metrics API not available")
+	})
+
+	if !strings.Contains(errOut.String(), "metrics API not available") {
+		t.Errorf("expected collection error on stderr, got %q", errOut.String())
+	}
+}
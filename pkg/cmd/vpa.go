@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/usage"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// VPAOptions contains the options for the vpa command
+type VPAOptions struct {
+	configFlags *genericclioptions.ConfigFlags
+	genericclioptions.IOStreams
+
+	output    string
+	drifted   bool
+	precision int
+}
+
+// vpaReport is the structured output of the vpa command
+type vpaReport struct {
+	VPAs []vpaUsageReport `json:"vpas" yaml:"vpas"`
+}
+
+// vpaUsageReport is one VPA's recommendations in structured output
+type vpaUsageReport struct {
+	Namespace  string               `json:"namespace" yaml:"namespace"`
+	Name       string               `json:"name" yaml:"name"`
+	Target     string               `json:"target" yaml:"target"`
+	UpdateMode string               `json:"updateMode" yaml:"updateMode"`
+	Containers []vpaContainerReport `json:"containers" yaml:"containers"`
+}
+
+// vpaContainerReport is one container's recommendation in structured output
+type vpaContainerReport struct {
+	Container string              `json:"container" yaml:"container"`
+	Pods      int                 `json:"pods" yaml:"pods"`
+	Resources []vpaResourceReport `json:"resources" yaml:"resources"`
+}
+
+// vpaResourceReport is one recommended resource next to the requests and usage in structured output
+type vpaResourceReport struct {
+	Resource       string   `json:"resource" yaml:"resource"`
+	Requests       string   `json:"requests,omitempty" yaml:"requests,omitempty"`
+	Usage          string   `json:"usage,omitempty" yaml:"usage,omitempty"`
+	LowerBound     string   `json:"lowerBound,omitempty" yaml:"lowerBound,omitempty"`
+	Target         string   `json:"target" yaml:"target"`
+	UpperBound     string   `json:"upperBound,omitempty" yaml:"upperBound,omitempty"`
+	UncappedTarget string   `json:"uncappedTarget,omitempty" yaml:"uncappedTarget,omitempty"`
+	DriftPercent   *float64 `json:"driftPercent" yaml:"driftPercent"`
+	Drift          string   `json:"drift" yaml:"drift"`
+}
+
+// NewVPAOptions creates a new VPAOptions with default values
+func NewVPAOptions(streams genericclioptions.IOStreams) *VPAOptions {
+	return &VPAOptions{
+		configFlags: genericclioptions.NewConfigFlags(true),
+		IOStreams:   streams,
+		output:      "table",
+	}
+}
+
+// NewCmdVPA creates the vpa command
+func NewCmdVPA(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewVPAOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "vpa",
+		Short: "Compare requests and usage with VerticalPodAutoscaler recommendations",
+		Long: `Show each VerticalPodAutoscaler's recommendation per container (lower bound,
+target, upper bound and uncapped target) next to the container's current
+requests and usage, averaged across the pods of its target. Useful for VPAs
+in "Off" mode, whose recommendations are never applied.
+
+DRIFT% is how far the requests are from the target. STATE is under or over
+when the requests fall outside the recommended bounds, the range VPA itself
+leaves alone. VerticalPodAutoscalers are read through the dynamic client;
+clusters without the VPA custom resource have none.`,
+		Example: `  # Every VPA recommendation in the cluster
+  kubectl resource-usage vpa
+
+  # Only the containers whose requests are outside the recommended bounds
+  kubectl resource-usage vpa --drifted
+
+  # Recommendations of one namespace as JSON
+  kubectl resource-usage vpa -n payment -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := completeProfile(cmd); err != nil {
+				return err
+			}
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run(cmd.Context())
+		},
+	}
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, "Output format: table, json, or yaml")
+	cmd.Flags().BoolVar(&o.drifted, "drifted", false, "Only show resources whose requests are outside the recommended bounds or unset")
+	cmd.Flags().IntVar(&o.precision, "precision", 0, fmt.Sprintf("Decimal places of DRIFT%% in table output (0-%d)", maxPrecision))
+
+	return cmd
+}
+
+// Validate validates the options
+func (o *VPAOptions) Validate() error {
+	validOutputs := map[string]bool{"table": true, "json": true, "yaml": true}
+	if !validOutputs[o.output] {
+		return fmt.Errorf("invalid output format: %s (must be 'table', 'json', or 'yaml')", o.output)
+	}
+	return validatePrecision(o.precision)
+}
+
+// Run lists VPAs, collects the usage of the pods they control and prints them
+func (o *VPAOptions) Run(ctx context.Context) error {
+	restConfig, err := o.configFlags.ToRESTConfig()
+	if err != nil {
+		return fmt.Errorf("failed to create REST config: %w", err)
+	}
+
+	namespace := ""
+	if o.configFlags.Namespace != nil {
+		namespace = *o.configFlags.Namespace
+	}
+
+	vpaCollector, err := collector.NewVPACollector(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create vpa collector: %w", err)
+	}
+
+	// VPA recommends cpu and memory, so skip reading kubelet storage stats
+	client, err := usage.NewClient(restConfig, usage.Options{})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	vpas, err := vpaCollector.GetVPAs(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	podUsages, err := client.CollectPodUsages(ctx, namespace, "")
+	if err != nil {
+		return err
+	}
+
+	return o.writeUsages(o.Out, calculator.CalculateVPAUsages(toCalculatorVPAs(vpas), podUsages))
+}
+
+// toCalculatorVPAs converts the fetched VerticalPodAutoscalers to calculator input
+func toCalculatorVPAs(vpas []collector.VerticalPodAutoscaler) []calculator.VPA {
+	result := make([]calculator.VPA, 0, len(vpas))
+	for _, v := range vpas {
+		vpa := calculator.VPA{Namespace: v.Namespace, Name: v.Name, UpdateMode: v.UpdateMode()}
+		if v.Spec.TargetRef != nil {
+			vpa.Target = v.Spec.TargetRef.Kind + "/" + v.Spec.TargetRef.Name
+		}
+		for _, rec := range v.Recommendations() {
+			vpa.Recommendations = append(vpa.Recommendations, calculator.ContainerRecommendation{
+				Container:      rec.ContainerName,
+				Target:         rec.Target,
+				LowerBound:     rec.LowerBound,
+				UpperBound:     rec.UpperBound,
+				UncappedTarget: rec.UncappedTarget,
+			})
+		}
+		result = append(result, vpa)
+	}
+	return result
+}
+
+// writeUsages writes the VPA usages in the selected output format
+func (o *VPAOptions) writeUsages(w io.Writer, usages []calculator.VPAUsage) (err error) {
+	usages = o.filterUsages(usages)
+
+	switch o.output {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(toVPAReport(usages))
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer func() {
+			if closeErr := enc.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+		return enc.Encode(toVPAReport(usages))
+	}
+
+	if len(usages) == 0 {
+		message := "No vertical pod autoscalers found"
+		if o.drifted {
+			message = "No requests drift from their recommendations"
+		}
+		_, err := fmt.Fprintln(w, message)
+		return err
+	}
+
+	unitFormatter := output.NewUnitFormatter("auto")
+	tw := printers.GetNewTabWriter(w)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tVPA\tTARGET\tMODE\tCONTAINER\tRESOURCE\tREQUEST\tUSAGE\tLOWER\tRECOMMENDED\tUPPER\tUNCAPPED\tDRIFT%\tSTATE")
+	for _, vpa := range usages {
+		if len(vpa.Containers) == 0 {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t-\t-\t-\t-\t-\t-\t-\t-\t-\t-\n", vpa.Namespace, vpa.Name, vpa.Target, vpa.UpdateMode)
+			continue
+		}
+		for _, c := range vpa.Containers {
+			for _, r := range c.Resources {
+				unit := calculator.DefinitionFor(r.Resource).Unit
+				target := r.Target
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					vpa.Namespace, vpa.Name, vpa.Target, vpa.UpdateMode, c.Container, r.Resource,
+					unitFormatter.FormatQuantityOrNA(unit, r.Requests), unitFormatter.FormatQuantityOrNA(unit, r.Usage),
+					unitFormatter.FormatQuantityOrNA(unit, r.LowerBound), unitFormatter.FormatQuantityOrNA(unit, &target),
+					unitFormatter.FormatQuantityOrNA(unit, r.UpperBound), unitFormatter.FormatQuantityOrNA(unit, r.UncappedTarget),
+					formatDrift(r.DriftPercent, o.precision), r.Drift)
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// formatDrift formats a drift percentage with its sign, N/A if unknown
+func formatDrift(p *float64, precision int) string {
+	text := output.FormatPercentText(p, precision)
+	if p == nil || *p <= 0 || text == output.FormatPercentText(new(float64), precision) {
+		return text
+	}
+	return "+" + text
+}
+
+// filterUsages applies --drifted, keeping only the resources whose requests
+// drift from the recommendation and dropping VPAs left without any
+func (o *VPAOptions) filterUsages(usages []calculator.VPAUsage) []calculator.VPAUsage {
+	if !o.drifted {
+		return usages
+	}
+	var result []calculator.VPAUsage
+	for _, u := range usages {
+		var containers []calculator.VPAContainerUsage
+		for _, c := range u.Containers {
+			var resources []calculator.VPAResourceUsage
+			for _, r := range c.Resources {
+				if r.Drift != calculator.DriftOK {
+					resources = append(resources, r)
+				}
+			}
+			if len(resources) > 0 {
+				c.Resources = resources
+				containers = append(containers, c)
+			}
+		}
+		if len(containers) > 0 {
+			u.Containers = containers
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// toVPAReport converts VPA usages to the structured report
+func toVPAReport(usages []calculator.VPAUsage) vpaReport {
+	report := vpaReport{VPAs: []vpaUsageReport{}}
+	for _, u := range usages {
+		vpa := vpaUsageReport{Namespace: u.Namespace, Name: u.Name, Target: u.Target, UpdateMode: u.UpdateMode, Containers: []vpaContainerReport{}}
+		for _, c := range u.Containers {
+			container := vpaContainerReport{Container: c.Container, Pods: c.Pods, Resources: []vpaResourceReport{}}
+			for _, r := range c.Resources {
+				container.Resources = append(container.Resources, vpaResourceReport{
+					Resource:       string(r.Resource),
+					Requests:       quantityString(r.Requests),
+					Usage:          quantityString(r.Usage),
+					LowerBound:     quantityString(r.LowerBound),
+					Target:         r.Target.String(),
+					UpperBound:     quantityString(r.UpperBound),
+					UncappedTarget: quantityString(r.UncappedTarget),
+					DriftPercent:   r.DriftPercent,
+					Drift:          string(r.Drift),
+				})
+			}
+			vpa.Containers = append(vpa.Containers, container)
+		}
+		report.VPAs = append(report.VPAs, vpa)
+	}
+	return report
+}
+
+// quantityString returns a quantity's canonical string, empty if it is nil
+func quantityString(q *resource.Quantity) string {
+	if q == nil {
+		return ""
+	}
+	return q.String()
+}
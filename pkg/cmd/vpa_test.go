@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestToCalculatorVPAs(t *testing.T) {
+	vpa := collector.VerticalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payment"},
+		Spec:       collector.VPASpec{TargetRef: &collector.VPATargetRef{Kind: "Deployment", Name: "api"}},
+	}
+	vpas := toCalculatorVPAs([]collector.VerticalPodAutoscaler{vpa, {ObjectMeta: metav1.ObjectMeta{Name: "orphan"}}})
+	if len(vpas) != 2 || vpas[0].Target != "Deployment/api" || vpas[0].UpdateMode != "Auto" || vpas[1].Target != "" {
+		t.Errorf("unexpected VPAs: %+v", vpas)
+	}
+}
+
+func TestVPAOptions_WriteUsages(t *testing.T) {
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	under, over := -60.0, 300.0
+	usages := []calculator.VPAUsage{
+		{
+			Namespace: "payment", Name: "api", Target: "Deployment/api", UpdateMode: "Off",
+			Containers: []calculator.VPAContainerUsage{{
+				Container: "app",
+				Pods:      2,
+				Resources: []calculator.VPAResourceUsage{
+					{Resource: corev1.ResourceCPU, Requests: quantity("100m"), Usage: quantity("150m"), Target: resource.MustParse("250m"),
+						LowerBound: quantity("200m"), UpperBound: quantity("400m"), DriftPercent: &under, Drift: calculator.DriftUnder},
+					{Resource: corev1.ResourceMemory, Requests: quantity("1Gi"), Target: resource.MustParse("256Mi"),
+						UpperBound: quantity("384Mi"), DriftPercent: &over, Drift: calculator.DriftOver},
+				},
+			}},
+		},
+		{
+			Namespace: "payment", Name: "web", Target: "Deployment/web", UpdateMode: "Auto",
+			Containers: []calculator.VPAContainerUsage{{
+				Container: "web",
+				Resources: []calculator.VPAResourceUsage{{Resource: corev1.ResourceCPU, Requests: quantity("250m"), Target: resource.MustParse("250m"), Drift: calculator.DriftOK}},
+			}},
+		},
+		{Namespace: "payment", Name: "worker", Target: "StatefulSet/worker", UpdateMode: "Auto"},
+	}
+
+	o := NewVPAOptions(genericclioptions.IOStreams{})
+	var table bytes.Buffer
+	if err := o.writeUsages(&table, usages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 rows, got:\n%s", table.String())
+	}
+	for i, want := range [][]string{
+		{"RECOMMENDED", "UNCAPPED", "DRIFT%", "STATE"},
+		{"Off", "app", "cpu", "100m", "150m", "200m", "250m", "400m", "-60%", "under"},
+		{"memory", "1Gi", "256Mi", "384Mi", "+300%", "over"},
+		{"web", "ok"},
+		{"StatefulSet/worker", "-"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(lines[i], w) {
+				t.Errorf("expected line %d to contain %q, got %q", i, w, lines[i])
+			}
+		}
+	}
+
+	o.drifted = true
+	o.output = "json"
+	var out bytes.Buffer
+	if err := o.writeUsages(&out, usages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report vpaReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(report.VPAs) != 1 || report.VPAs[0].Name != "api" || len(report.VPAs[0].Containers[0].Resources) != 2 {
+		t.Fatalf("expected only the drifted api resources, got %+v", report.VPAs)
+	}
+	if memory := report.VPAs[0].Containers[0].Resources[1]; memory.Requests != "1Gi" || memory.Usage != "" || memory.Drift != "over" || *memory.DriftPercent != 300 {
+		t.Errorf("unexpected memory resource: %+v", memory)
+	}
+
+	o.output = "table"
+	table.Reset()
+	if err := o.writeUsages(&table, usages[1:]); err != nil || !strings.Contains(table.String(), "No requests drift") {
+		t.Errorf("expected no drift message, got %q (%v)", table.String(), err)
+	}
+}
+
+func TestFormatDrift(t *testing.T) {
+	positive, negative, tiny := 25.0, -12.5, 0.004
+	tests := []struct {
+		percent *float64
+		want    string
+	}{
+		{&positive, "+25%"},
+		{&negative, "-12%"},
+		{&tiny, "0%"},
+		{nil, "N/A"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDrift(tt.percent, 0); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
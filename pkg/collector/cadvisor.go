@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// cadvisor metrics read by CadvisorCollector
+const (
+	metricCFSPeriods          = "container_cpu_cfs_periods_total"
+	metricCFSThrottledPeriods = "container_cpu_cfs_throttled_periods_total"
+	metricCPUPressureWaiting  = "container_pressure_cpu_waiting_seconds_total"
+	metricStartTime           = "container_start_time_seconds"
+)
+
+// ContainerCPUStats are the cumulative CPU throttling and pressure counters
+// of a container reported by cadvisor
+type ContainerCPUStats struct {
+	Periods          int64    // CFS periods, 0 without a CPU limit
+	ThrottledPeriods int64    // periods in which the container used up its quota
+	WaitingSeconds   *float64 // PSI time tasks waited for CPU, nil if the node doesn't report it
+	RunningSeconds   float64  // time since the container started, 0 if unknown
+}
+
+// PodCPUStats are the CPU counters of a pod's containers keyed by container name
+type PodCPUStats map[string]ContainerCPUStats
+
+// CadvisorCollector fetches the kubelet cadvisor metrics of nodes through the API server proxy
+type CadvisorCollector struct {
+	metrics func(ctx context.Context, node string) ([]byte, error)
+	now     func() time.Time
+}
+
+// NewCadvisorCollector creates a new CadvisorCollector
+func NewCadvisorCollector(config *rest.Config) (*CadvisorCollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &CadvisorCollector{
+		metrics: func(ctx context.Context, node string) ([]byte, error) {
+			return client.CoreV1().RESTClient().Get().
+				Resource("nodes").Name(node).
+				SubResource("proxy").Suffix("metrics", "cadvisor").
+				DoRaw(ctx)
+		},
+		now: time.Now,
+	}, nil
+}
+
+// GetPodCPUStats fetches the CPU throttling and pressure counters of the pods
+// on the given nodes, keyed by namespace/name. Nodes whose metrics cannot be
+// fetched are skipped and reported in the returned error alongside the other results.
+func (c *CadvisorCollector) GetPodCPUStats(ctx context.Context, nodes []string) (map[string]PodCPUStats, error) {
+	return fetchNodes(ctx, nodes, c.nodeCPUStats)
+}
+
+// nodeCPUStats fetches and parses one node's cadvisor metrics
+func (c *CadvisorCollector) nodeCPUStats(ctx context.Context, node string) (map[string]PodCPUStats, error) {
+	data, err := c.metrics(ctx, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cadvisor metrics of node %s: %w", node, err)
+	}
+	stats, err := parseCPUStats(data, c.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cadvisor metrics of node %s: %w", node, err)
+	}
+	return stats, nil
+}
+
+// containerCPUStats are one container's counters while parsing
+type containerCPUStats struct {
+	periods, throttled int64
+	waiting            *float64
+	start              float64
+}
+
+// parseCPUStats groups the container CPU counters in cadvisor's Prometheus
+// text output by pod. The pod-level cgroup and pause container series are
+// skipped so containers are not counted twice.
+func parseCPUStats(data []byte, now time.Time) (map[string]PodCPUStats, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make(map[[3]string]*containerCPUStats)
+	visit := func(name string, set func(s *containerCPUStats, v float64)) {
+		family, ok := families[name]
+		if !ok {
+			return
+		}
+		for _, m := range family.GetMetric() {
+			labels := labelValues(m)
+			if labels["container"] == "" || labels["container"] == "POD" || labels["pod"] == "" {
+				continue
+			}
+			key := [3]string{labels["namespace"], labels["pod"], labels["container"]}
+			if containers[key] == nil {
+				containers[key] = &containerCPUStats{}
+			}
+			set(containers[key], sampleValue(m))
+		}
+	}
+	visit(metricCFSPeriods, func(s *containerCPUStats, v float64) { s.periods = int64(v) })
+	visit(metricCFSThrottledPeriods, func(s *containerCPUStats, v float64) { s.throttled = int64(v) })
+	visit(metricCPUPressureWaiting, func(s *containerCPUStats, v float64) { s.waiting = &v })
+	visit(metricStartTime, func(s *containerCPUStats, v float64) { s.start = v })
+
+	result := make(map[string]PodCPUStats)
+	for key, s := range containers {
+		podKey := key[0] + "/" + key[1]
+		if result[podKey] == nil {
+			result[podKey] = make(PodCPUStats)
+		}
+		stats := ContainerCPUStats{Periods: s.periods, ThrottledPeriods: s.throttled, WaitingSeconds: s.waiting}
+		if s.start > 0 {
+			stats.RunningSeconds = max(float64(now.UnixNano())/1e9-s.start, 0)
+		}
+		result[podKey][key[2]] = stats
+	}
+	return result, nil
+}
+
+// labelValues returns a sample's labels by name
+func labelValues(m *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(m.GetLabel()))
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+// sampleValue returns the value of a counter, gauge or untyped sample
+func sampleValue(m *dto.Metric) float64 {
+	switch {
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue()
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
@@ -2,12 +2,23 @@ package collector
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
 )
@@ -187,3 +198,286 @@ func TestMetricsCollector_GetPodMetrics_WithMetrics(t *testing.T) {
 	}
 	t.Logf("got %d metrics for default namespace", len(metrics.Items))
 }
+
+func TestNodeCollector_GetNodes(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+	)
+	collector := &NodeCollector{client: fakeClient}
+
+	nodes, err := collector.GetNodes(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes.Items) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(nodes.Items))
+	}
+}
+
+func TestNamespaceCollector_GetNamespaces(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payment", Labels: map[string]string{"team": "payments"}}},
+	)
+	collector := &NamespaceCollector{client: fakeClient}
+
+	namespaces, err := collector.GetNamespaces(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaces) != 2 {
+		t.Errorf("expected 2 namespaces, got %d", len(namespaces))
+	}
+
+	namespaces, err = collector.GetNamespaces(context.Background(), "payment")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0].Labels["team"] != "payments" {
+		t.Errorf("expected the payment namespace, got %+v", namespaces)
+	}
+
+	if _, err := collector.GetNamespaces(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for a missing namespace")
+	}
+}
+
+func TestLimitRangeCollector_GetLimitRanges(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "default"}},
+		&corev1.LimitRange{ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "payment"}},
+	)
+	collector := &LimitRangeCollector{client: fakeClient}
+
+	tests := []struct {
+		namespace string
+		want      int
+	}{
+		{"", 2},
+		{"payment", 1},
+		{"kube-system", 0},
+	}
+	for _, tt := range tests {
+		limitRanges, err := collector.GetLimitRanges(context.Background(), tt.namespace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(limitRanges.Items) != tt.want {
+			t.Errorf("namespace %q: expected %d limit ranges, got %d", tt.namespace, tt.want, len(limitRanges.Items))
+		}
+	}
+}
+
+func TestHPACollector_GetHPAs(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"}},
+		&autoscalingv2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "payment"}},
+	)
+	collector := &HPACollector{client: fakeClient}
+
+	tests := []struct {
+		namespace string
+		want      int
+	}{
+		{"", 2},
+		{"payment", 1},
+		{"kube-system", 0},
+	}
+	for _, tt := range tests {
+		hpas, err := collector.GetHPAs(context.Background(), tt.namespace)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hpas) != tt.want {
+			t.Errorf("namespace %q: expected %d HPAs, got %d", tt.namespace, tt.want, len(hpas))
+		}
+	}
+}
+
+func TestVPACollector_GetVPAs(t *testing.T) {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "api", "namespace": "payment"},
+		"spec": map[string]interface{}{
+			"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "api"},
+			"updatePolicy": map[string]interface{}{"updateMode": "Off"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{
+						"containerName": "app",
+						"target":        map[string]interface{}{"cpu": "250m", "memory": "256Mi"},
+						"lowerBound":    map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+					},
+				},
+			},
+		},
+	}}
+	unrecommended := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling.k8s.io/v1",
+		"kind":       "VerticalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "worker", "namespace": "default"},
+	}}
+	fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{VPAResource: "VerticalPodAutoscalerList"}, vpa, unrecommended)
+	collector := &VPACollector{client: fakeClient}
+
+	vpas, err := collector.GetVPAs(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vpas) != 2 {
+		t.Fatalf("expected 2 VPAs, got %d", len(vpas))
+	}
+
+	vpas, err = collector.GetVPAs(context.Background(), "payment")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vpas) != 1 || vpas[0].Spec.TargetRef == nil || vpas[0].Spec.TargetRef.Kind != "Deployment" || vpas[0].UpdateMode() != "Off" {
+		t.Fatalf("unexpected VPAs: %+v", vpas)
+	}
+	recommendations := vpas[0].Recommendations()
+	if len(recommendations) != 1 || recommendations[0].ContainerName != "app" {
+		t.Fatalf("unexpected recommendations: %+v", recommendations)
+	}
+	if target := recommendations[0].Target[corev1.ResourceCPU]; target.MilliValue() != 250 {
+		t.Errorf("expected a cpu target of 250m, got %s", target.String())
+	}
+	if recommendations[0].UpperBound != nil {
+		t.Errorf("expected no upper bound, got %v", recommendations[0].UpperBound)
+	}
+
+	vpas, err = collector.GetVPAs(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vpas) != 1 || vpas[0].UpdateMode() != "Auto" || vpas[0].Recommendations() != nil {
+		t.Errorf("expected the default mode and no recommendations, got %+v", vpas)
+	}
+}
+
+func TestStatsCollector_GetPodStorageStats(t *testing.T) {
+	summaries := map[string]string{
+		"node-1": `{"pods": [
+			{"podRef": {"name": "api", "namespace": "default"},
+			 "containers": [{"name": "app", "rootfs": {"usedBytes": 1000}, "logs": {"usedBytes": 24}}],
+			 "ephemeral-storage": {"usedBytes": 4096}},
+			{"podRef": {"name": "no-stats", "namespace": "default"}}
+		]}`,
+	}
+	collector := &StatsCollector{
+		summary: func(_ context.Context, node string) ([]byte, error) {
+			data, ok := summaries[node]
+			if !ok {
+				return nil, fmt.Errorf("forbidden")
+			}
+			return []byte(data), nil
+		},
+	}
+
+	stats, err := collector.GetPodStorageStats(context.Background(), []string{"node-1", "node-2"})
+	if err == nil || !strings.Contains(err.Error(), "node-2") {
+		t.Errorf("expected error for node-2, got %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for 1 pod, got %d", len(stats))
+	}
+	api := stats["default/api"]
+	if api.UsedBytes != 4096 {
+		t.Errorf("expected 4096 used bytes, got %d", api.UsedBytes)
+	}
+	if api.Containers["app"] != 1024 {
+		t.Errorf("expected 1024 container bytes, got %d", api.Containers["app"])
+	}
+}
+
+func TestFetchNodesTimeout(t *testing.T) {
+	fetch := func(ctx context.Context, node string) (map[string]int, error) {
+		if node == "slow" {
+			<-ctx.Done()
+			return nil, fmt.Errorf("node %s: %w", node, ctx.Err())
+		}
+		return map[string]int{"default/" + node: 1}, nil
+	}
+
+	result, err := fetchNodesWithTimeout(context.Background(), []string{"fast", "slow"}, 10*time.Millisecond, fetch)
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("expected the slow node to time out, got %v", err)
+	}
+	if len(result) != 1 || result["default/fast"] != 1 {
+		t.Errorf("expected the fast node's pods, got %v", result)
+	}
+}
+
+// testCadvisorMetrics is cadvisor output for a pod with two containers, its
+// pod-level cgroup and pause container, and a pod on a node without PSI
+const testCadvisorMetrics = `# HELP container_cpu_cfs_periods_total Number of elapsed enforcement period intervals.
+# TYPE container_cpu_cfs_periods_total counter
+container_cpu_cfs_periods_total{container="app",namespace="default",pod="api"} 900
+container_cpu_cfs_periods_total{container="sidecar",namespace="default",pod="api"} 100
+container_cpu_cfs_periods_total{container="",namespace="default",pod="api"} 1000
+container_cpu_cfs_periods_total{container="worker",namespace="batch",pod="job"} 50
+# HELP container_cpu_cfs_throttled_periods_total Number of throttled period intervals.
+# TYPE container_cpu_cfs_throttled_periods_total counter
+container_cpu_cfs_throttled_periods_total{container="app",namespace="default",pod="api"} 240
+container_cpu_cfs_throttled_periods_total{container="sidecar",namespace="default",pod="api"} 10
+container_cpu_cfs_throttled_periods_total{container="",namespace="default",pod="api"} 250
+container_cpu_cfs_throttled_periods_total{container="worker",namespace="batch",pod="job"} 0
+# HELP container_pressure_cpu_waiting_seconds_total Total time duration tasks in the container have waited due to CPU congestion.
+# TYPE container_pressure_cpu_waiting_seconds_total counter
+container_pressure_cpu_waiting_seconds_total{container="app",namespace="default",pod="api"} 30
+container_pressure_cpu_waiting_seconds_total{container="sidecar",namespace="default",pod="api"} 10
+container_pressure_cpu_waiting_seconds_total{container="POD",namespace="default",pod="api"} 500
+# HELP container_start_time_seconds Start time of the container since unix epoch in seconds.
+# TYPE container_start_time_seconds gauge
+container_start_time_seconds{container="app",namespace="default",pod="api"} 1.7040672e+09
+container_start_time_seconds{container="sidecar",namespace="default",pod="api"} 1.7040672e+09
+container_start_time_seconds{container="worker",namespace="batch",pod="job"} 1.7040672e+09
+`
+
+func TestCadvisorCollector_GetPodCPUStats(t *testing.T) {
+	// A local stand-in for the API server proxying the kubelet cadvisor endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/node-1/proxy/metrics/cadvisor" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = fmt.Fprint(w, testCadvisorMetrics)
+	}))
+	defer server.Close()
+
+	collector, err := NewCadvisorCollector(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 100s after the containers started
+	collector.now = func() time.Time { return time.Unix(1704067300, 0) }
+
+	stats, err := collector.GetPodCPUStats(context.Background(), []string{"node-1", "node-2"})
+	if err == nil || !strings.Contains(err.Error(), "node-2") {
+		t.Errorf("expected error for node-2, got %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected stats for 2 pods, got %+v", stats)
+	}
+
+	api := stats["default/api"]
+	if len(api) != 2 {
+		t.Fatalf("expected app and sidecar without the pod cgroup and pause container, got %+v", api)
+	}
+	if app := api["app"]; app.Periods != 900 || app.ThrottledPeriods != 240 || app.WaitingSeconds == nil || *app.WaitingSeconds != 30 || app.RunningSeconds != 100 {
+		t.Errorf("expected app counters with 30s waiting over 100s running, got %+v", app)
+	}
+	if sidecar := api["sidecar"]; sidecar.Periods != 100 || sidecar.ThrottledPeriods != 10 {
+		t.Errorf("unexpected sidecar counters: %+v", sidecar)
+	}
+
+	job := stats["batch/job"]["worker"]
+	if job.Periods != 50 || job.ThrottledPeriods != 0 || job.WaitingSeconds != nil {
+		t.Errorf("expected periods without pressure, got %+v", job)
+	}
+}
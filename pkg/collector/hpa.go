@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// HPACollector fetches HorizontalPodAutoscaler objects from the Kubernetes API
+type HPACollector struct {
+	client kubernetes.Interface
+}
+
+// NewHPACollector creates a new HPACollector
+func NewHPACollector(config *rest.Config) (*HPACollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &HPACollector{
+		client: client,
+	}, nil
+}
+
+// GetHPAs fetches the autoscaling/v2 HorizontalPodAutoscalers of the specified namespace
+// If namespace is empty, it fetches HorizontalPodAutoscalers from all namespaces
+func (c *HPACollector) GetHPAs(ctx context.Context, namespace string) ([]autoscalingv2.HorizontalPodAutoscaler, error) {
+	hpas, err := c.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+
+	return hpas.Items, nil
+}
@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// LimitRangeCollector fetches LimitRange objects from the Kubernetes API
+type LimitRangeCollector struct {
+	client kubernetes.Interface
+}
+
+// NewLimitRangeCollector creates a new LimitRangeCollector
+func NewLimitRangeCollector(config *rest.Config) (*LimitRangeCollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &LimitRangeCollector{
+		client: client,
+	}, nil
+}
+
+// GetLimitRanges fetches LimitRanges for the specified namespace
+// If namespace is empty, it fetches LimitRanges from all namespaces
+func (c *LimitRangeCollector) GetLimitRanges(ctx context.Context, namespace string) (*corev1.LimitRangeList, error) {
+	limitRanges, err := c.client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit ranges: %w", err)
+	}
+
+	return limitRanges, nil
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NamespaceCollector fetches Namespace objects from the Kubernetes API
+type NamespaceCollector struct {
+	client kubernetes.Interface
+}
+
+// NewNamespaceCollector creates a new NamespaceCollector
+func NewNamespaceCollector(config *rest.Config) (*NamespaceCollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &NamespaceCollector{
+		client: client,
+	}, nil
+}
+
+// GetNamespaces fetches the specified namespace, or every namespace if it is empty
+func (c *NamespaceCollector) GetNamespaces(ctx context.Context, namespace string) ([]corev1.Namespace, error) {
+	if namespace != "" {
+		ns, err := c.client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace: %w", err)
+		}
+		return []corev1.Namespace{*ns}, nil
+	}
+
+	namespaces, err := c.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	return namespaces.Items, nil
+}
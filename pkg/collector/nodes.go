@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NodeCollector fetches Node objects from the Kubernetes API
+type NodeCollector struct {
+	client kubernetes.Interface
+}
+
+// NewNodeCollector creates a new NodeCollector
+func NewNodeCollector(config *rest.Config) (*NodeCollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &NodeCollector{
+		client: client,
+	}, nil
+}
+
+// GetNodes fetches the nodes in the cluster matching the label selector
+// If selector is empty, it fetches all nodes
+func (c *NodeCollector) GetNodes(ctx context.Context, selector string) (*corev1.NodeList, error) {
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// maxConcurrentSummaries bounds the kubelet requests in flight
+const maxConcurrentSummaries = 10
+
+// nodeRequestTimeout bounds each kubelet request, so a few slow or
+// unreachable nodes cannot use up the time of the whole collection
+const nodeRequestTimeout = 5 * time.Second
+
+// PodStorageStats is a pod's ephemeral storage usage reported by the kubelet
+type PodStorageStats struct {
+	UsedBytes  int64            // pod total: container rootfs, logs and emptyDir volumes
+	Containers map[string]int64 // rootfs plus logs usage per container
+}
+
+// StatsCollector fetches the kubelet stats summary of nodes through the API server proxy
+type StatsCollector struct {
+	summary func(ctx context.Context, node string) ([]byte, error)
+}
+
+// NewStatsCollector creates a new StatsCollector
+func NewStatsCollector(config *rest.Config) (*StatsCollector, error) {
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	return &StatsCollector{
+		summary: func(ctx context.Context, node string) ([]byte, error) {
+			return client.CoreV1().RESTClient().Get().
+				Resource("nodes").Name(node).
+				SubResource("proxy").Suffix("stats", "summary").
+				DoRaw(ctx)
+		},
+	}, nil
+}
+
+// GetPodStorageStats fetches the ephemeral storage usage of the pods on the
+// given nodes, keyed by namespace/name. Nodes whose summary cannot be fetched
+// are skipped and reported in the returned error alongside the other results.
+func (c *StatsCollector) GetPodStorageStats(ctx context.Context, nodes []string) (map[string]PodStorageStats, error) {
+	return fetchNodes(ctx, nodes, c.nodeStorageStats)
+}
+
+// fetchNodes calls fetch for every node concurrently, each with its own
+// timeout, and merges the pods it returns. Nodes that fail are skipped and
+// reported in the returned error.
+func fetchNodes[T any](ctx context.Context, nodes []string, fetch func(ctx context.Context, node string) (map[string]T, error)) (map[string]T, error) {
+	return fetchNodesWithTimeout(ctx, nodes, nodeRequestTimeout, fetch)
+}
+
+// fetchNodesWithTimeout is fetchNodes with the per-node timeout given
+func fetchNodesWithTimeout[T any](ctx context.Context, nodes []string, timeout time.Duration, fetch func(ctx context.Context, node string) (map[string]T, error)) (map[string]T, error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   []error
+		result = make(map[string]T)
+		sem    = make(chan struct{}, maxConcurrentSummaries)
+	)
+
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			var pods map[string]T
+			var err error
+			select {
+			case sem <- struct{}{}:
+				nodeCtx, cancel := context.WithTimeout(ctx, timeout)
+				pods, err = fetch(nodeCtx, node)
+				cancel()
+				<-sem
+			case <-ctx.Done():
+				err = fmt.Errorf("skipped node %s: %w", node, ctx.Err())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			for key, p := range pods {
+				result[key] = p
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	return result, errors.Join(errs...)
+}
+
+// nodeStorageStats fetches and parses one node's stats summary
+func (c *StatsCollector) nodeStorageStats(ctx context.Context, node string) (map[string]PodStorageStats, error) {
+	data, err := c.summary(ctx, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats summary of node %s: %w", node, err)
+	}
+	stats, err := parseStorageStats(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stats summary of node %s: %w", node, err)
+	}
+	return stats, nil
+}
+
+// statsSummary is the subset of the kubelet /stats/summary response used here
+type statsSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Containers []struct {
+			Name   string   `json:"name"`
+			Rootfs *fsStats `json:"rootfs"`
+			Logs   *fsStats `json:"logs"`
+		} `json:"containers"`
+		EphemeralStorage *fsStats `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+// fsStats is a filesystem usage entry of the stats summary
+type fsStats struct {
+	UsedBytes *int64 `json:"usedBytes"`
+}
+
+// used returns the used bytes and whether they were reported
+func (s *fsStats) used() (int64, bool) {
+	if s == nil || s.UsedBytes == nil {
+		return 0, false
+	}
+	return *s.UsedBytes, true
+}
+
+// parseStorageStats extracts pod ephemeral storage usage from a stats summary
+func parseStorageStats(data []byte) (map[string]PodStorageStats, error) {
+	var summary statsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]PodStorageStats, len(summary.Pods))
+	for _, pod := range summary.Pods {
+		used, ok := pod.EphemeralStorage.used()
+		if !ok {
+			continue
+		}
+		stats := PodStorageStats{
+			UsedBytes:  used,
+			Containers: make(map[string]int64, len(pod.Containers)),
+		}
+		for _, c := range pod.Containers {
+			rootfs, hasRootfs := c.Rootfs.used()
+			logs, hasLogs := c.Logs.used()
+			if hasRootfs || hasLogs {
+				stats.Containers[c.Name] = rootfs + logs
+			}
+		}
+		result[pod.PodRef.Namespace+"/"+pod.PodRef.Name] = stats
+	}
+	return result, nil
+}
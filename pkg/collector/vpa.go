@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// VPAResource is the VerticalPodAutoscaler custom resource
+var VPAResource = schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+
+// VerticalPodAutoscaler holds the fields of a VerticalPodAutoscaler this
+// plugin reads, decoded from the custom resource without depending on the
+// VPA API module
+type VerticalPodAutoscaler struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VPASpec   `json:"spec"`
+	Status            VPAStatus `json:"status"`
+}
+
+// VPASpec is the part of a VerticalPodAutoscaler's spec this plugin reads
+type VPASpec struct {
+	TargetRef    *VPATargetRef `json:"targetRef"`
+	UpdatePolicy *struct {
+		UpdateMode string `json:"updateMode"`
+	} `json:"updatePolicy"`
+}
+
+// VPATargetRef is the workload a VerticalPodAutoscaler controls
+type VPATargetRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// VPAStatus is the part of a VerticalPodAutoscaler's status this plugin reads
+type VPAStatus struct {
+	Recommendation *struct {
+		ContainerRecommendations []VPAContainerRecommendation `json:"containerRecommendations"`
+	} `json:"recommendation"`
+}
+
+// VPAContainerRecommendation is the recommended resources of one container
+type VPAContainerRecommendation struct {
+	ContainerName  string              `json:"containerName"`
+	Target         corev1.ResourceList `json:"target"`
+	LowerBound     corev1.ResourceList `json:"lowerBound"`
+	UpperBound     corev1.ResourceList `json:"upperBound"`
+	UncappedTarget corev1.ResourceList `json:"uncappedTarget"`
+}
+
+// UpdateMode returns the VerticalPodAutoscaler's update mode, Auto if unset
+func (v VerticalPodAutoscaler) UpdateMode() string {
+	if v.Spec.UpdatePolicy == nil || v.Spec.UpdatePolicy.UpdateMode == "" {
+		return "Auto"
+	}
+	return v.Spec.UpdatePolicy.UpdateMode
+}
+
+// Recommendations returns the container recommendations, empty until the recommender has run
+func (v VerticalPodAutoscaler) Recommendations() []VPAContainerRecommendation {
+	if v.Status.Recommendation == nil {
+		return nil
+	}
+	return v.Status.Recommendation.ContainerRecommendations
+}
+
+// VPACollector fetches VerticalPodAutoscaler objects through the dynamic client
+type VPACollector struct {
+	client dynamic.Interface
+}
+
+// NewVPACollector creates a new VPACollector
+func NewVPACollector(config *rest.Config) (*VPACollector, error) {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	return &VPACollector{
+		client: client,
+	}, nil
+}
+
+// GetVPAs fetches the VerticalPodAutoscalers of the specified namespace
+// If namespace is empty, it fetches VerticalPodAutoscalers from all namespaces.
+// Clusters without the VPA custom resource have none.
+func (c *VPACollector) GetVPAs(ctx context.Context, namespace string) ([]VerticalPodAutoscaler, error) {
+	list, err := c.client.Resource(VPAResource).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vertical pod autoscalers: %w", err)
+	}
+
+	vpas := make([]VerticalPodAutoscaler, 0, len(list.Items))
+	for _, item := range list.Items {
+		var vpa VerticalPodAutoscaler
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &vpa); err != nil {
+			return nil, fmt.Errorf("failed to decode vertical pod autoscaler %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+		}
+		vpas = append(vpas, vpa)
+	}
+	return vpas, nil
+}
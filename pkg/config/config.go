@@ -0,0 +1,326 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cost"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the defaults applied to every run and the named profiles
+// selected with --profile, which override the defaults
+type Config struct {
+	Defaults Profile            `yaml:"defaults"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile sets default flag values, color thresholds and pricing
+type Profile struct {
+	// Flags maps flag names, without dashes, to their values; lists set
+	// repeatable and comma-separated flags
+	Flags  map[string]FlagValue `yaml:"flags"`
+	Colors *Colors              `yaml:"colors"`
+	// Pricing holds inline rates, used when no --pricing file is given
+	Pricing *cost.Pricing `yaml:"pricing"`
+	// Commands maps subcommand names, such as "chargeback", to the flags
+	// set when running them
+	Commands map[string]map[string]FlagValue `yaml:"commands"`
+}
+
+// Colors holds the thresholds of the severity bands percentages are colored
+// by, overridable per field such as "memory.limitPercent", and the colors used
+type Colors struct {
+	Thresholds `yaml:",inline"`
+	Fields     map[string]Thresholds `yaml:"fields"`
+	Palette    string                `yaml:"palette"` // default or colorblind
+	Depth      string                `yaml:"depth"`   // auto, 16, 256 or truecolor
+}
+
+// Thresholds sets some of the percentages separating the severity bands;
+// the others keep their defaults
+type Thresholds struct {
+	Warning  *int `yaml:"warning"`  // medium at or above
+	Critical *int `yaml:"critical"` // high at or above
+	Waste    *int `yaml:"waste"`    // waste below, 0 to disable
+	Over     *int `yaml:"over"`     // over above
+}
+
+// Scheme converts the colors to the scheme formatters color percentages with.
+// Field thresholds fall back to the top-level ones, then to the defaults.
+func (c *Colors) Scheme() (output.ColorScheme, error) {
+	scheme := output.ColorScheme{
+		Thresholds: c.Thresholds.apply(output.DefaultThresholds),
+		Palette:    output.Palette(c.Palette),
+	}
+	if err := validateThresholds(scheme.Thresholds); err != nil {
+		return output.ColorScheme{}, err
+	}
+	if c.Palette != "" && !contains(output.ValidPalettes(), c.Palette) {
+		return output.ColorScheme{}, fmt.Errorf("invalid palette: %s (must be one of: %v)", c.Palette, output.ValidPalettes())
+	}
+	if c.Depth != "" && !contains(output.ValidColorDepths(), c.Depth) {
+		return output.ColorScheme{}, fmt.Errorf("invalid depth: %s (must be one of: %v)", c.Depth, output.ValidColorDepths())
+	}
+	if c.Depth != "auto" {
+		scheme.Depth = output.ColorDepth(c.Depth)
+	}
+
+	names := make([]string, 0, len(c.Fields))
+	for name := range c.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field, err := output.ParseField(name)
+		if err != nil {
+			return output.ColorScheme{}, fmt.Errorf("invalid colors field: %w", err)
+		}
+		thresholds := c.Fields[name].apply(scheme.Thresholds)
+		if err := validateThresholds(thresholds); err != nil {
+			return output.ColorScheme{}, fmt.Errorf("field %s: %w", name, err)
+		}
+		if scheme.Fields == nil {
+			scheme.Fields = make(map[output.Field]output.Thresholds)
+		}
+		scheme.Fields[field] = thresholds
+	}
+	return scheme, nil
+}
+
+// apply returns base with the thresholds that are set replaced
+func (t Thresholds) apply(base output.Thresholds) output.Thresholds {
+	if t.Warning != nil {
+		base.Warning = *t.Warning
+	}
+	if t.Critical != nil {
+		base.Critical = *t.Critical
+	}
+	if t.Waste != nil {
+		base.Waste = *t.Waste
+	}
+	if t.Over != nil {
+		base.Over = *t.Over
+	}
+	return base
+}
+
+// validateThresholds checks that the severity bands are in order
+func validateThresholds(t output.Thresholds) error {
+	if t.Waste < 0 || t.Waste > t.Warning || t.Warning > t.Critical || t.Critical > t.Over {
+		return fmt.Errorf("invalid colors: waste %d, warning %d, critical %d, over %d (must be 0 <= waste <= warning <= critical <= over)",
+			t.Waste, t.Warning, t.Critical, t.Over)
+	}
+	return nil
+}
+
+// contains reports whether values contains s
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FlagValue is one or more values of a flag
+type FlagValue []string
+
+// UnmarshalYAML accepts a scalar or a list of scalars
+func (v *FlagValue) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*v = FlagValue{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		values := make(FlagValue, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: flag values must be scalars", item.Line)
+			}
+			values = append(values, item.Value)
+		}
+		*v = values
+		return nil
+	}
+	return fmt.Errorf("line %d: flag value must be a scalar or a list", node.Line)
+}
+
+// DefaultPath returns the config file location, under $XDG_CONFIG_HOME or ~/.config
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kubectl-resource-usage", "config.yaml"), nil
+}
+
+// Load reads and validates a config file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// LoadDefault reads the config file at DefaultPath, or returns an empty
+// config if there is none
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return cfg, err
+}
+
+// Parse parses and validates a YAML config
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the color thresholds and pricing of the defaults and every profile
+func (c *Config) Validate() error {
+	if err := c.Defaults.validate(); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	for _, name := range c.ProfileNames() {
+		if err := c.Profiles[name].validate(); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the profile's colors and pricing
+func (p Profile) validate() error {
+	if p.Colors != nil {
+		if _, err := p.Colors.Scheme(); err != nil {
+			return err
+		}
+	}
+	if p.Pricing != nil {
+		if err := p.Pricing.Validate(); err != nil {
+			return fmt.Errorf("invalid pricing: %w", err)
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of the profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the defaults overridden by the named profile, or the
+// defaults alone if name is empty. A profile's flags override the defaults
+// one by one; its colors and pricing replace them.
+func (c *Config) Resolve(name string) (Profile, error) {
+	if name == "" {
+		return c.Defaults, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found in config (available: %v)", name, c.ProfileNames())
+	}
+
+	resolved := Profile{
+		Flags:   mergeFlags(c.Defaults.Flags, profile.Flags),
+		Colors:  c.Defaults.Colors,
+		Pricing: c.Defaults.Pricing,
+	}
+	for command, flags := range c.Defaults.Commands {
+		resolved.setCommand(command, mergeFlags(flags, profile.Commands[command]))
+	}
+	for command, flags := range profile.Commands {
+		if _, ok := c.Defaults.Commands[command]; !ok {
+			resolved.setCommand(command, flags)
+		}
+	}
+	if profile.Colors != nil {
+		resolved.Colors = profile.Colors
+	}
+	if profile.Pricing != nil {
+		resolved.Pricing = profile.Pricing
+	}
+	return resolved, nil
+}
+
+// mergeFlags returns base with the flags of override replacing its own
+func mergeFlags(base, override map[string]FlagValue) map[string]FlagValue {
+	merged := make(map[string]FlagValue, len(base)+len(override))
+	for flag, value := range base {
+		merged[flag] = value
+	}
+	for flag, value := range override {
+		merged[flag] = value
+	}
+	return merged
+}
+
+// setCommand sets the flags of a subcommand
+func (p *Profile) setCommand(name string, flags map[string]FlagValue) {
+	if p.Commands == nil {
+		p.Commands = make(map[string]map[string]FlagValue)
+	}
+	p.Commands[name] = flags
+}
+
+// Command returns the profile a subcommand runs with: its flags from the
+// commands section, and the profile's colors and pricing
+func (p Profile) Command(name string) Profile {
+	return Profile{Flags: p.Commands[name], Colors: p.Colors, Pricing: p.Pricing}
+}
+
+// CommandNames returns the names of the subcommands the profile sets flags for, sorted
+func (p Profile) CommandNames() []string {
+	names := make([]string, 0, len(p.Commands))
+	for name := range p.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FlagNames returns the names of the flags the profile sets, sorted
+func (p Profile) FlagNames() []string {
+	names := make([]string, 0, len(p.Flags))
+	for name := range p.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
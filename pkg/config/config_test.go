@@ -0,0 +1,226 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testConfig = `
+defaults:
+  flags:
+    unit: Mi
+    exclude-namespaces: [kube-system, monitoring]
+  colors:
+    warning: 60
+    critical: 85
+profiles:
+  oncall:
+    flags:
+      sort: memory
+      above: 80
+  finops:
+    flags:
+      unit: Gi
+      sort: idle-cost
+    colors:
+      warning: 0
+      critical: 10
+    pricing:
+      currency: "€"
+      cpuCoreHour: 0.03
+      memoryGiBHour: 0.004
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"finops", "oncall"}) {
+		t.Errorf("unexpected profiles: %v", got)
+	}
+	if got := cfg.Defaults.Flags["exclude-namespaces"]; !reflect.DeepEqual(got, FlagValue{"kube-system", "monitoring"}) {
+		t.Errorf("expected a list value, got %v", got)
+	}
+	if got := cfg.Profiles["oncall"].Flags["above"]; !reflect.DeepEqual(got, FlagValue{"80"}) {
+		t.Errorf("expected a scalar value, got %v", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		errMsg string
+	}{
+		{"unknown field", "default:\n  flags: {}\n", "field default not found"},
+		{"nested flag value", "defaults:\n  flags:\n    sort: {field: cpu}\n", "must be a scalar or a list"},
+		{"critical below warning", "profiles:\n  oncall:\n    colors: {warning: 90, critical: 80}\n", "profile oncall: invalid colors"},
+		{"field below waste", "defaults:\n  colors:\n    fields:\n      cpu.requestPercent: {waste: 60}\n", "field cpu.requestPercent: invalid colors"},
+		{"unknown field resource", "defaults:\n  colors:\n    fields:\n      disk.limitPercent: {critical: 90}\n", "invalid colors field: disk.limitPercent"},
+		{"unknown field metric", "defaults:\n  colors:\n    fields:\n      memory.usage: {critical: 90}\n", "metric must be"},
+		{"unknown palette", "defaults:\n  colors: {palette: rainbow}\n", "invalid palette"},
+		{"unknown depth", "defaults:\n  colors: {depth: 8}\n", "invalid depth"},
+		{"negative rate", "defaults:\n  pricing: {cpuCoreHour: -1}\n", "defaults: invalid pricing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defaults, err := cfg.Resolve("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.Pricing != nil || *defaults.Colors.Critical != 85 || len(defaults.Flags) != 2 {
+		t.Errorf("unexpected defaults: %+v", defaults)
+	}
+
+	oncall, err := cfg.Resolve("oncall")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := oncall.FlagNames(); !reflect.DeepEqual(got, []string{"above", "exclude-namespaces", "sort", "unit"}) {
+		t.Errorf("expected profile flags merged over the defaults, got %v", got)
+	}
+	if *oncall.Colors.Critical != 85 {
+		t.Errorf("expected the default colors, got %+v", oncall.Colors)
+	}
+
+	finops, err := cfg.Resolve("finops")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if finops.Flags["unit"][0] != "Gi" || *finops.Colors.Critical != 10 || finops.Pricing == nil || finops.Pricing.CurrencySymbol() != "€" {
+		t.Errorf("expected the profile to override the defaults, got %+v", finops)
+	}
+
+	if _, err := cfg.Resolve("unknown"); err == nil || !strings.Contains(err.Error(), `profile "unknown" not found`) {
+		t.Errorf("expected an unknown profile error, got %v", err)
+	}
+}
+
+func TestResolveCommands(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  commands:
+    chargeback:
+      group-by: label:team
+      output: csv
+    check:
+      fail-on: [memory.limits missing]
+profiles:
+  finops:
+    commands:
+      chargeback:
+        output: markdown
+      nodes:
+        sort: cpu
+    pricing:
+      cpuCoreHour: 0.03
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	finops, err := cfg.Resolve("finops")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := finops.CommandNames(); !reflect.DeepEqual(got, []string{"chargeback", "check", "nodes"}) {
+		t.Errorf("expected the commands of the defaults and the profile, got %v", got)
+	}
+	chargeback := finops.Command("chargeback")
+	if chargeback.Flags["group-by"][0] != "label:team" || chargeback.Flags["output"][0] != "markdown" {
+		t.Errorf("expected command flags merged over the defaults, got %v", chargeback.Flags)
+	}
+	if chargeback.Pricing == nil || chargeback.Commands != nil {
+		t.Errorf("expected the profile pricing and no commands, got %+v", chargeback)
+	}
+	if got := finops.Command("audit").FlagNames(); len(got) != 0 {
+		t.Errorf("expected no flags for audit, got %v", got)
+	}
+}
+
+func TestColorsScheme(t *testing.T) {
+	cfg, err := Parse([]byte(`
+defaults:
+  colors:
+    critical: 85
+    palette: colorblind
+    depth: truecolor
+    fields:
+      memory.limitPercent: {critical: 90}
+      cpu.requestPercent: {waste: 10}
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scheme, err := cfg.Defaults.Colors.Scheme()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := output.ColorScheme{
+		Thresholds: output.Thresholds{Warning: 50, Critical: 85, Over: 100},
+		Fields: map[output.Field]output.Thresholds{
+			{Resource: corev1.ResourceMemory, Metric: output.MetricLimitPercent}: {Warning: 50, Critical: 90, Over: 100},
+			{Resource: corev1.ResourceCPU, Metric: output.MetricRequestPercent}:  {Warning: 50, Critical: 85, Waste: 10, Over: 100},
+		},
+		Palette: output.PaletteColorblind,
+		Depth:   output.ColorDepthTrueColor,
+	}
+	if !reflect.DeepEqual(scheme, want) {
+		t.Errorf("expected %+v, got %+v", want, scheme)
+	}
+}
+
+func TestLoadDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := LoadDefault()
+	if err != nil {
+		t.Fatalf("expected a missing config file to be ignored, got %v", err)
+	}
+	if len(cfg.Profiles) != 0 || len(cfg.Defaults.Flags) != 0 {
+		t.Errorf("expected an empty config, got %+v", cfg)
+	}
+
+	path := filepath.Join(dir, "kubectl-resource-usage", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadDefault()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Profiles) != 2 {
+		t.Errorf("expected the config file at %s to be read, got %+v", path, cfg)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected an explicit missing config file to fail")
+	}
+}
@@ -0,0 +1,126 @@
+package cost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCurrency is the currency symbol used when the pricing doesn't set one
+const DefaultCurrency = "$"
+
+// Pricing holds the hourly rates used to estimate costs. Node rules override
+// the default rates for nodes whose labels match, such as an instance type
+// or spot capacity; the first matching rule wins.
+type Pricing struct {
+	Currency      string        `yaml:"currency"`
+	CPUCoreHour   float64       `yaml:"cpuCoreHour"`
+	MemoryGiBHour float64       `yaml:"memoryGiBHour"`
+	Nodes         []NodePricing `yaml:"nodes"`
+}
+
+// NodePricing overrides the rates of nodes carrying all of its labels.
+// A rate left unset keeps the default.
+type NodePricing struct {
+	Labels        map[string]string `yaml:"labels"`
+	CPUCoreHour   *float64          `yaml:"cpuCoreHour"`
+	MemoryGiBHour *float64          `yaml:"memoryGiBHour"`
+}
+
+// LoadPricing reads and validates a pricing file
+func LoadPricing(path string) (*Pricing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file: %w", err)
+	}
+	pricing, err := ParsePricing(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pricing file %s: %w", path, err)
+	}
+	return pricing, nil
+}
+
+// ParsePricing parses and validates a YAML pricing configuration
+func ParsePricing(data []byte) (*Pricing, error) {
+	var pricing Pricing
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&pricing); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := pricing.Validate(); err != nil {
+		return nil, err
+	}
+	return &pricing, nil
+}
+
+// Validate checks that rates are not negative and node rules have labels
+func (p *Pricing) Validate() error {
+	if p.CPUCoreHour < 0 || p.MemoryGiBHour < 0 {
+		return fmt.Errorf("invalid rate: rates must not be negative")
+	}
+	for i, node := range p.Nodes {
+		if len(node.Labels) == 0 {
+			return fmt.Errorf("invalid node rule %d: labels must not be empty", i+1)
+		}
+		if (node.CPUCoreHour != nil && *node.CPUCoreHour < 0) || (node.MemoryGiBHour != nil && *node.MemoryGiBHour < 0) {
+			return fmt.Errorf("invalid node rule %d: rates must not be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// CurrencySymbol returns the currency costs are shown in
+func (p *Pricing) CurrencySymbol() string {
+	if p.Currency == "" {
+		return DefaultCurrency
+	}
+	return p.Currency
+}
+
+// NeedsNodeLabels reports whether the rates depend on node labels
+func (p *Pricing) NeedsNodeLabels() bool {
+	return len(p.Nodes) > 0
+}
+
+// RatesFor returns the rates of a node with the given labels
+func (p *Pricing) RatesFor(nodeLabels map[string]string) calculator.Rates {
+	rates := calculator.Rates{CPUCoreHour: p.CPUCoreHour, MemoryGiBHour: p.MemoryGiBHour}
+	for _, node := range p.Nodes {
+		if !matchesLabels(nodeLabels, node.Labels) {
+			continue
+		}
+		if node.CPUCoreHour != nil {
+			rates.CPUCoreHour = *node.CPUCoreHour
+		}
+		if node.MemoryGiBHour != nil {
+			rates.MemoryGiBHour = *node.MemoryGiBHour
+		}
+		break
+	}
+	return rates
+}
+
+// Apply sets the estimated cost of each pod at the rates of its node.
+// nodeLabels maps node names to their labels; pods on unknown nodes get the default rates.
+func Apply(pods []calculator.PodUsage, p *Pricing, nodeLabels map[string]map[string]string) {
+	for i := range pods {
+		cost := calculator.EstimateCost(pods[i], p.RatesFor(nodeLabels[pods[i].Node]))
+		pods[i].Cost = &cost
+	}
+}
+
+// matchesLabels reports whether labels contain every key and value of want
+func matchesLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
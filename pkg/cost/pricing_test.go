@@ -0,0 +1,124 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const testPricing = `
+currency: "€"
+cpuCoreHour: 0.04
+memoryGiBHour: 0.005
+nodes:
+  - labels:
+      karpenter.sh/capacity-type: spot
+    cpuCoreHour: 0.012
+  - labels:
+      node.kubernetes.io/instance-type: r5.large
+    cpuCoreHour: 0.03
+    memoryGiBHour: 0.004
+`
+
+func TestParsePricing(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "valid", input: testPricing},
+		{name: "empty", input: ""},
+		{name: "unknown field", input: "cpuPerHour: 0.04", wantErr: "field cpuPerHour not found"},
+		{name: "negative rate", input: "cpuCoreHour: -1", wantErr: "must not be negative"},
+		{name: "node rule without labels", input: "nodes:\n  - cpuCoreHour: 0.01", wantErr: "labels must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePricing([]byte(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRatesFor(t *testing.T) {
+	pricing, err := ParsePricing([]byte(testPricing))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   calculator.Rates
+	}{
+		{name: "default", labels: map[string]string{"node.kubernetes.io/instance-type": "m5.large"}, want: calculator.Rates{CPUCoreHour: 0.04, MemoryGiBHour: 0.005}},
+		{name: "spot keeps default memory", labels: map[string]string{"karpenter.sh/capacity-type": "spot"}, want: calculator.Rates{CPUCoreHour: 0.012, MemoryGiBHour: 0.005}},
+		{name: "instance type", labels: map[string]string{"node.kubernetes.io/instance-type": "r5.large"}, want: calculator.Rates{CPUCoreHour: 0.03, MemoryGiBHour: 0.004}},
+		{
+			name:   "first match wins",
+			labels: map[string]string{"karpenter.sh/capacity-type": "spot", "node.kubernetes.io/instance-type": "r5.large"},
+			want:   calculator.Rates{CPUCoreHour: 0.012, MemoryGiBHour: 0.005},
+		},
+		{name: "unknown node", labels: nil, want: calculator.Rates{CPUCoreHour: 0.04, MemoryGiBHour: 0.005}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pricing.RatesFor(tt.labels); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if pricing.CurrencySymbol() != "€" || (&Pricing{}).CurrencySymbol() != DefaultCurrency {
+		t.Errorf("unexpected currency symbols")
+	}
+}
+
+func TestApply(t *testing.T) {
+	pricing, err := ParsePricing([]byte(testPricing))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests := resource.MustParse("1")
+	pods := []calculator.PodUsage{
+		{Name: "on-demand", Node: "node-1", Resources: calculator.ResourceUsages{corev1.ResourceCPU: {Requests: &requests}}},
+		{Name: "spot", Node: "node-2", Resources: calculator.ResourceUsages{corev1.ResourceCPU: {Requests: &requests}}},
+	}
+	nodeLabels := map[string]map[string]string{"node-2": {"karpenter.sh/capacity-type": "spot"}}
+
+	Apply(pods, pricing, nodeLabels)
+	if pods[0].Cost == nil || pods[1].Cost == nil {
+		t.Fatalf("expected every pod to be priced, got %+v", pods)
+	}
+	if pods[1].Cost.Requests >= pods[0].Cost.Requests {
+		t.Errorf("expected the spot pod to be cheaper, got %.2f and %.2f", pods[1].Cost.Requests, pods[0].Cost.Requests)
+	}
+}
+
+func TestLoadPricing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(path, []byte("cpuCoreHour: -1"), 0o600); err != nil {
+		t.Fatalf("failed to write pricing: %v", err)
+	}
+	if _, err := LoadPricing(path); err == nil || !strings.Contains(err.Error(), "invalid pricing file") {
+		t.Errorf("expected invalid pricing file error, got %v", err)
+	}
+	if _, err := LoadPricing(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// metricPrefix is the common prefix of all exported usage metrics
+const metricPrefix = "kube_pod_resource_usage_"
+
+// usageLabels are the labels attached to every per-container usage metric
+var usageLabels = []string{"namespace", "pod", "container", "node", "resource", "unit"}
+
+// Exporter exposes the latest collected pod usages as Prometheus metrics
+type Exporter struct {
+	registry *prometheus.Registry
+
+	requestRatio *prometheus.Desc
+	limitRatio   *prometheus.Desc
+	usage        *prometheus.Desc
+	requests     *prometheus.Desc
+	limits       *prometheus.Desc
+
+	collectionDuration prometheus.Gauge
+	collectionErrors   prometheus.Counter
+	collections        prometheus.Counter
+	lastSuccess        prometheus.Gauge
+
+	mu        sync.RWMutex
+	podUsages []calculator.PodUsage
+}
+
+// NewExporter creates a new Exporter with its own registry
+func NewExporter() *Exporter {
+	e := &Exporter{
+		registry: prometheus.NewRegistry(),
+		requestRatio: prometheus.NewDesc(metricPrefix+"request_ratio",
+			"Container resource usage divided by its requests.", usageLabels, nil),
+		limitRatio: prometheus.NewDesc(metricPrefix+"limit_ratio",
+			"Container resource usage divided by its limits.", usageLabels, nil),
+		usage: prometheus.NewDesc(metricPrefix+"usage",
+			"Container resource usage reported by the Metrics API.", usageLabels, nil),
+		requests: prometheus.NewDesc(metricPrefix+"requests",
+			"Container resource requests from the pod spec.", usageLabels, nil),
+		limits: prometheus.NewDesc(metricPrefix+"limits",
+			"Container resource limits from the pod spec.", usageLabels, nil),
+		collectionDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: metricPrefix + "collection_duration_seconds",
+			Help: "Duration of the last collection from the Kubernetes API.",
+		}),
+		collectionErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: metricPrefix + "collection_errors_total",
+			Help: "Number of collections that failed.",
+		}),
+		collections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: metricPrefix + "collections_total",
+			Help: "Number of collections attempted.",
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: metricPrefix + "last_success_timestamp_seconds",
+			Help: "Unix time of the last successful collection.",
+		}),
+	}
+
+	e.registry.MustRegister(e, e.collectionDuration, e.collectionErrors, e.collections, e.lastSuccess)
+	return e
+}
+
+// Update replaces the exported pod usages with the latest collection
+func (e *Exporter) Update(podUsages []calculator.PodUsage) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.podUsages = podUsages
+}
+
+// RecordCollection records the duration and outcome of a collection.
+// The previous pod usages stay exported when a collection fails.
+func (e *Exporter) RecordCollection(duration time.Duration, err error) {
+	e.collections.Inc()
+	e.collectionDuration.Set(duration.Seconds())
+	if err != nil {
+		e.collectionErrors.Inc()
+		return
+	}
+	e.lastSuccess.SetToCurrentTime()
+}
+
+// Handler returns the HTTP handler serving the metrics
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Describe implements prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.requestRatio
+	ch <- e.limitRatio
+	ch <- e.usage
+	ch <- e.requests
+	ch <- e.limits
+}
+
+// Collect implements prometheus.Collector
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	resources := calculator.ShownResources(e.podUsages)
+	for _, pu := range e.podUsages {
+		for _, cu := range pu.Containers {
+			for _, def := range resources {
+				e.collectResource(ch, pu, cu.Name, string(def.Name), unitLabels[def.Unit], cu.Resources[def.Name])
+			}
+		}
+	}
+}
+
+// unitLabels are the unit label values of each unit family
+var unitLabels = map[calculator.UnitFamily]string{
+	calculator.UnitFamilyCPU:   "core",
+	calculator.UnitFamilyBytes: "byte",
+	calculator.UnitFamilyCount: "unit",
+}
+
+// collectResource emits the metrics of one resource of a container. The usage
+// and ratios are skipped when no usage was reported.
+func (e *Exporter) collectResource(ch chan<- prometheus.Metric, pu calculator.PodUsage, container, resourceName, unit string, ru calculator.ResourceUsage) {
+	labels := []string{pu.Namespace, pu.Name, container, pu.Node, resourceName, unit}
+
+	if !ru.UsageUnavailable {
+		usage := ru.Usage.AsApproximateFloat64()
+		ch <- prometheus.MustNewConstMetric(e.usage, prometheus.GaugeValue, usage, labels...)
+	}
+
+	if ru.Requests != nil {
+		ch <- prometheus.MustNewConstMetric(e.requests, prometheus.GaugeValue, ru.Requests.AsApproximateFloat64(), labels...)
+		if ru.RequestPercent != nil && !ru.UsageUnavailable {
+			ch <- prometheus.MustNewConstMetric(e.requestRatio, prometheus.GaugeValue, *ru.RequestPercent/100, labels...)
+		}
+	}
+	if ru.Limits != nil {
+		ch <- prometheus.MustNewConstMetric(e.limits, prometheus.GaugeValue, ru.Limits.AsApproximateFloat64(), labels...)
+		if ru.LimitPercent != nil && !ru.UsageUnavailable {
+			ch <- prometheus.MustNewConstMetric(e.limitRatio, prometheus.GaugeValue, *ru.LimitPercent/100, labels...)
+		}
+	}
+}
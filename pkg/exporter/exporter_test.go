@@ -0,0 +1,123 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestExporterMetrics(t *testing.T) {
+	exp := NewExporter()
+	exp.Update([]calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "api",
+			Node:      "node-1",
+			Containers: []calculator.ContainerUsage{
+				{
+					Name: "app",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU: {
+							Usage:          resource.MustParse("250m"),
+							Requests:       quantityPtr("500m"),
+							Limits:         quantityPtr("1"),
+							RequestPercent: floatPtr(50),
+							LimitPercent:   floatPtr(25),
+						},
+						corev1.ResourceMemory: {
+							Usage:        resource.MustParse("192Mi"),
+							Limits:       quantityPtr("256Mi"),
+							LimitPercent: floatPtr(75),
+						},
+					},
+				},
+			},
+		},
+	})
+	exp.RecordCollection(2*time.Second, nil)
+	exp.RecordCollection(time.Second, errors.New("metrics API not available"))
+
+	server := httptest.NewServer(exp.Handler())
+	defer server.Close()
+
+	body := scrape(t, server.URL)
+
+	expected := []string{
+		`kube_pod_resource_usage_request_ratio{container="app",namespace="default",node="node-1",pod="api",resource="cpu",unit="core"} 0.5`,
+		`kube_pod_resource_usage_limit_ratio{container="app",namespace="default",node="node-1",pod="api",resource="cpu",unit="core"} 0.25`,
+		`kube_pod_resource_usage_limit_ratio{container="app",namespace="default",node="node-1",pod="api",resource="memory",unit="byte"} 0.75`,
+		`kube_pod_resource_usage_usage{container="app",namespace="default",node="node-1",pod="api",resource="memory",unit="byte"} 2.01326592e+08`,
+		`kube_pod_resource_usage_requests{container="app",namespace="default",node="node-1",pod="api",resource="cpu",unit="core"} 0.5`,
+		`kube_pod_resource_usage_limits{container="app",namespace="default",node="node-1",pod="api",resource="memory",unit="byte"} 2.68435456e+08`,
+		`kube_pod_resource_usage_collection_duration_seconds 1`,
+		`kube_pod_resource_usage_collection_errors_total 1`,
+		`kube_pod_resource_usage_collections_total 2`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected metrics to contain %q", line)
+		}
+	}
+
+	// No memory requests are set, so no memory request ratio is exported
+	if strings.Contains(body, `kube_pod_resource_usage_request_ratio{container="app",namespace="default",node="node-1",pod="api",resource="memory"`) {
+		t.Error("expected no memory request ratio without memory requests")
+	}
+}
+
+func TestExporterUpdateReplacesPods(t *testing.T) {
+	exp := NewExporter()
+	exp.Update([]calculator.PodUsage{
+		{Namespace: "default", Name: "old", Containers: []calculator.ContainerUsage{{Name: "app"}}},
+	})
+	exp.Update([]calculator.PodUsage{
+		{Namespace: "default", Name: "new", Containers: []calculator.ContainerUsage{{Name: "app"}}},
+	})
+
+	server := httptest.NewServer(exp.Handler())
+	defer server.Close()
+
+	body := scrape(t, server.URL)
+	if strings.Contains(body, `pod="old"`) {
+		t.Error("expected pods from previous collections to be dropped")
+	}
+	if !strings.Contains(body, `pod="new"`) {
+		t.Error("expected pods from the latest collection to be exported")
+	}
+}
+
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+func quantityPtr(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -3,24 +3,197 @@ package output
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
 	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ANSI color codes
 const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
+	colorReset   = "\033[0m"
+	colorBold    = "\033[1m"
+	colorRed     = "\033[31m"
+	colorGreen   = "\033[32m"
+	colorYellow  = "\033[33m"
+	colorBlue    = "\033[34m"
+	colorMagenta = "\033[35m"
+	colorCyan    = "\033[36m"
 )
 
-// Usage threshold percentages for color coding
+// Trend arrows shown next to percentages in watch mode
 const (
-	highUsageThreshold   = 80
-	mediumUsageThreshold = 50
+	trendUp   = "↑"
+	trendDown = "↓"
 )
 
+// Percentage metrics of a resource, named as in alert rules
+const (
+	MetricRequestPercent = "requestPercent"
+	MetricLimitPercent   = "limitPercent"
+)
+
+// Field identifies a percentage column, e.g. memory limitPercent
+type Field struct {
+	Resource corev1.ResourceName
+	Metric   string // MetricRequestPercent or MetricLimitPercent
+}
+
+// String returns the field as "<resource>.<metric>", e.g. "memory.limitPercent"
+func (f Field) String() string {
+	return string(f.Resource) + "." + f.Metric
+}
+
+// ParseField parses a field in the form returned by String, e.g. "memory.limitPercent"
+func ParseField(s string) (Field, error) {
+	// Resource names may contain dots (nvidia.com/gpu), metrics don't
+	dot := strings.LastIndex(s, ".")
+	if _, ok := calculator.LookupResource(s[:max(dot, 0)]); dot < 0 || !ok {
+		return Field{}, fmt.Errorf("%s (must be <resource>.<metric> with resource one of: %s)",
+			s, strings.Join(calculator.ResourceNames(), ", "))
+	}
+	metric := s[dot+1:]
+	if metric != MetricRequestPercent && metric != MetricLimitPercent {
+		return Field{}, fmt.Errorf("%s (metric must be %s or %s)", s, MetricRequestPercent, MetricLimitPercent)
+	}
+	return Field{Resource: corev1.ResourceName(s[:dot]), Metric: metric}, nil
+}
+
+// Severity is the band a percentage falls in
+type Severity string
+
+const (
+	SeverityNA     Severity = "na"     // percentage unavailable
+	SeverityWaste  Severity = "waste"  // below the waste threshold: mostly unused
+	SeverityLow    Severity = "low"    // below the warning threshold
+	SeverityMedium Severity = "medium" // at or above the warning threshold
+	SeverityHigh   Severity = "high"   // at or above the critical threshold
+	SeverityOver   Severity = "over"   // above the over threshold, 100% by default
+)
+
+// Thresholds are the percentages separating the severity bands
+type Thresholds struct {
+	Warning  int // medium at or above
+	Critical int // high at or above
+	Waste    int // waste below, 0 to disable
+	Over     int // over above, 100 if not positive
+}
+
+// DefaultThresholds are used when no thresholds are configured
+var DefaultThresholds = Thresholds{Warning: 50, Critical: 80, Over: 100}
+
+// orDefault returns t, or DefaultThresholds if t is unset
+func (t Thresholds) orDefault() Thresholds {
+	if t == (Thresholds{}) {
+		return DefaultThresholds
+	}
+	return t
+}
+
+// Severity returns the band of a percentage
+func (t Thresholds) Severity(p *float64) Severity {
+	t = t.orDefault()
+	over := t.Over
+	if over <= 0 {
+		over = 100
+	}
+	switch {
+	case p == nil:
+		return SeverityNA
+	case *p > float64(over):
+		return SeverityOver
+	case *p >= float64(t.Critical):
+		return SeverityHigh
+	case *p >= float64(t.Warning):
+		return SeverityMedium
+	case *p < float64(t.Waste):
+		return SeverityWaste
+	default:
+		return SeverityLow
+	}
+}
+
+// Palette selects the colors of the severity bands
+type Palette string
+
+const (
+	PaletteDefault    Palette = "default"    // green, yellow, red
+	PaletteColorblind Palette = "colorblind" // Okabe-Ito blue, orange, vermillion
+)
+
+// ValidPalettes returns the list of valid palette options
+func ValidPalettes() []string {
+	return []string{string(PaletteDefault), string(PaletteColorblind)}
+}
+
+// ColorDepth is the number of colors the terminal supports
+type ColorDepth string
+
+const (
+	ColorDepthAuto      ColorDepth = ""          // detect from COLORTERM and TERM
+	ColorDepth16        ColorDepth = "16"        // basic ANSI colors
+	ColorDepth256       ColorDepth = "256"       // xterm 256-color palette
+	ColorDepthTrueColor ColorDepth = "truecolor" // 24-bit RGB
+)
+
+// ValidColorDepths returns the list of valid color depth options
+func ValidColorDepths() []string {
+	return []string{"auto", string(ColorDepth16), string(ColorDepth256), string(ColorDepthTrueColor)}
+}
+
+// rgb is a 24-bit color
+type rgb struct{ r, g, b uint8 }
+
+// paletteColor is a severity's color as a basic ANSI code and as RGB
+type paletteColor struct {
+	ansi string
+	rgb  rgb
+}
+
+// palettes maps each palette to the colors of its severity bands
+var palettes = map[Palette]map[Severity]paletteColor{
+	PaletteDefault: {
+		SeverityWaste:  {colorCyan, rgb{0x00, 0xaf, 0xd7}},
+		SeverityLow:    {colorGreen, rgb{0x00, 0xaf, 0x00}},
+		SeverityMedium: {colorYellow, rgb{0xd7, 0xaf, 0x00}},
+		SeverityHigh:   {colorRed, rgb{0xd7, 0x00, 0x00}},
+		SeverityOver:   {colorMagenta, rgb{0xd7, 0x00, 0xd7}},
+	},
+	PaletteColorblind: {
+		SeverityWaste:  {colorCyan, rgb{0x56, 0xb4, 0xe9}},
+		SeverityLow:    {colorBlue, rgb{0x00, 0x72, 0xb2}},
+		SeverityMedium: {colorYellow, rgb{0xe6, 0x9f, 0x00}},
+		SeverityHigh:   {colorRed, rgb{0xd5, 0x5e, 0x00}},
+		SeverityOver:   {colorMagenta, rgb{0xcc, 0x79, 0xa7}},
+	},
+}
+
+// ColorScheme selects the thresholds and colors percentages are shown with
+type ColorScheme struct {
+	Thresholds Thresholds           // applied to fields without an override
+	Fields     map[Field]Thresholds // per-field overrides, e.g. a higher memory limitPercent critical
+	Palette    Palette              // PaletteDefault if empty
+	Depth      ColorDepth           // detected from the environment if empty
+}
+
+// Severity returns the band of a field's percentage
+func (s ColorScheme) Severity(field Field, p *float64) Severity {
+	if t, ok := s.Fields[field]; ok {
+		return t.Severity(p)
+	}
+	return s.Thresholds.Severity(p)
+}
+
+// palette returns the scheme's palette, PaletteDefault if unknown
+func (s ColorScheme) palette() Palette {
+	if _, ok := palettes[s.Palette]; ok {
+		return s.Palette
+	}
+	return PaletteDefault
+}
+
 // ColorMode represents the color output mode
 type ColorMode string
 
@@ -32,11 +205,20 @@ const (
 
 // Colorizer handles colorizing output based on usage percentage
 type Colorizer struct {
-	enabled bool
+	enabled   bool
+	scheme    ColorScheme
+	precision int // decimal places of percentages
 }
 
-// NewColorizer creates a new Colorizer based on the color mode
+// NewColorizer creates a new Colorizer based on the color mode, using DefaultThresholds
 func NewColorizer(mode ColorMode) *Colorizer {
+	return NewColorizerWithScheme(mode, ColorScheme{})
+}
+
+// NewColorizerWithScheme creates a new Colorizer that colors percentages with
+// the given scheme. In auto mode colors are enabled on a terminal unless the
+// NO_COLOR environment variable is set.
+func NewColorizerWithScheme(mode ColorMode, scheme ColorScheme) *Colorizer {
 	var enabled bool
 	switch mode {
 	case ColorModeAlways:
@@ -44,9 +226,18 @@ func NewColorizer(mode ColorMode) *Colorizer {
 	case ColorModeNever:
 		enabled = false
 	default:
-		enabled = isTerminal()
+		enabled = os.Getenv("NO_COLOR") == "" && isTerminal()
+	}
+	if scheme.Depth == ColorDepthAuto {
+		scheme.Depth = detectColorDepth()
 	}
-	return &Colorizer{enabled: enabled}
+	return &Colorizer{enabled: enabled, scheme: scheme}
+}
+
+// WithPrecision sets the decimal places percentages are shown with and returns c
+func (c *Colorizer) WithPrecision(precision int) *Colorizer {
+	c.precision = precision
+	return c
 }
 
 // isTerminal checks if stdout is a terminal
@@ -54,33 +245,118 @@ func isTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
-// FormatPercent formats percentage with color based on value
-// High usage (>=80%) -> Red
-// Medium usage (50-79%) -> Yellow
-// Low usage (<50%) -> Green
-// N/A -> No color
-func (c *Colorizer) FormatPercent(p *int, width int) string {
+// detectColorDepth returns the color depth advertised by COLORTERM and TERM
+func detectColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return ColorDepth256
+	}
+	return ColorDepth16
+}
+
+// FormatPercent formats a field's percentage colored by its severity band:
+// waste, low (green), medium (yellow), high (red) or over; N/A has no color
+func (c *Colorizer) FormatPercent(field Field, p *float64, width int) string {
 	if p == nil {
 		return fmt.Sprintf("%-*s", width, "N/A")
 	}
 
-	percentStr := fmt.Sprintf("%d%%", *p)
+	percentStr := FormatPercentText(p, c.precision)
 
 	if !c.enabled {
 		return fmt.Sprintf("%-*s", width, percentStr)
 	}
 
-	var color string
-	switch {
-	case *p >= highUsageThreshold:
-		color = colorRed
-	case *p >= mediumUsageThreshold:
-		color = colorYellow
+	return fmt.Sprintf("%s%-*s%s", c.color(field, p), width, percentStr, colorReset)
+}
+
+// FormatPercentTrend formats percentage like FormatPercent, followed by an
+// arrow showing the trend since prev; the cell is highlighted if it changed.
+// Changes too small to show at the Colorizer's precision are ignored.
+func (c *Colorizer) FormatPercentTrend(field Field, p, prev *float64, width int) string {
+	if p == nil {
+		return c.HighlightIf(prev != nil, fmt.Sprintf("%-*s", width, "N/A"))
+	}
+
+	text := FormatPercentText(p, c.precision)
+	changed := prev == nil || text != FormatPercentText(prev, c.precision)
+	arrow := ""
+	if prev != nil && changed {
+		arrow = trendDown
+		if *p > *prev {
+			arrow = trendUp
+		}
+	}
+	percentStr := text + arrow
+
+	if !c.enabled {
+		return fmt.Sprintf("%-*s", width, percentStr)
+	}
+
+	cell := fmt.Sprintf("%s%-*s%s", c.color(field, p), width, percentStr, colorReset)
+	return c.HighlightIf(changed, cell)
+}
+
+// FormatPercentText formats a percentage with precision decimal places,
+// without padding or color, or N/A if it is missing. Digits beyond the
+// precision are cut rather than rounded, so 79.99% never shows as 80%.
+func FormatPercentText(p *float64, precision int) string {
+	if p == nil {
+		return "N/A"
+	}
+	whole, frac, _ := strings.Cut(strconv.FormatFloat(*p, 'f', -1, 64), ".")
+	if precision <= 0 {
+		return whole + "%"
+	}
+	frac += strings.Repeat("0", precision)
+	return whole + "." + frac[:precision] + "%"
+}
+
+// color returns the escape sequence coloring a field's percentage at the Colorizer's color depth
+func (c *Colorizer) color(field Field, p *float64) string {
+	pc := palettes[c.scheme.palette()][c.scheme.Severity(field, p)]
+	switch c.scheme.Depth {
+	case ColorDepthTrueColor:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", pc.rgb.r, pc.rgb.g, pc.rgb.b)
+	case ColorDepth256:
+		return fmt.Sprintf("\033[38;5;%dm", ansi256(pc.rgb))
 	default:
-		color = colorGreen
+		return pc.ansi
 	}
+}
 
-	return fmt.Sprintf("%s%-*s%s", color, width, percentStr, colorReset)
+// ansi256 returns the nearest color in the 6x6x6 cube of the xterm 256-color palette
+func ansi256(c rgb) int {
+	level := func(v uint8) int {
+		levels := []int{0, 95, 135, 175, 215, 255}
+		best := 0
+		for i, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	return 16 + 36*level(c.r) + 6*level(c.g) + level(c.b)
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// HighlightIf renders s in bold if changed is true and colorization is enabled
+func (c *Colorizer) HighlightIf(changed bool, s string) string {
+	if !changed || !c.enabled {
+		return s
+	}
+	return colorBold + s + colorReset
 }
 
 // Enabled returns whether colorization is enabled
@@ -2,20 +2,31 @@ package output
 
 // Table format column widths
 const (
+	tableColCluster   = 14
 	tableColNamespace = 14
 	tableColPod       = 40
-	tableColCPUUsage  = 11
+	tableColUsage     = 11
 	tableColPercent   = 10
-	tableColMemUsage  = 11
+	tableColCost      = 10
 	tableColNode      = 15
 )
 
 // Wide format column widths
 const (
-	wideColNamespace = 12
-	wideColPod       = 30
-	wideColUsage     = 9
-	wideColReqLim    = 9
-	wideColPercent   = 8
-	wideColNode      = 12
+	wideColCluster     = 12
+	wideColNamespace   = 12
+	wideColPod         = 30
+	wideColUsage       = 9
+	wideColReqLim      = 9
+	wideColPercent     = 8
+	wideColThrottling  = 10
+	wideColCost        = 10
+	wideColNode        = 12
+	wideColQOS         = 10
+	wideColPhase       = 9
+	wideColRestarts    = 8
+	wideColTermination = 22
 )
+
+// assumedMarker suffixes wide-table values that include LimitRange defaults
+const assumedMarker = "*"
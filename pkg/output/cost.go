@@ -0,0 +1,47 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// costColumns are the monthly cost columns shown when pricing is configured
+var costColumns = []string{"COST/MO", "USAGE/MO", "IDLE/MO"}
+
+// FormatCost formats a monthly cost with its currency symbol, e.g. "$12.34"
+func FormatCost(currency string, value float64) string {
+	if currency == "" {
+		currency = "$"
+	}
+	return fmt.Sprintf("%s%.2f", currency, value)
+}
+
+// formatCosts formats the requests, usage and idle cost, or N/A without a cost
+func formatCosts(currency string, c *calculator.Cost) []string {
+	if c == nil {
+		return []string{"N/A", "N/A", "N/A"}
+	}
+	return []string{FormatCost(currency, c.Requests), FormatCost(currency, c.Usage), FormatCost(currency, c.Idle)}
+}
+
+// hasCosts reports whether any pod usage has an estimated cost
+func hasCosts(podUsages []calculator.PodUsage) bool {
+	for _, pu := range podUsages {
+		if pu.Cost != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// totalCost sums the estimated costs of the pod usages
+func totalCost(podUsages []calculator.PodUsage) calculator.Cost {
+	var total calculator.Cost
+	for _, pu := range podUsages {
+		if pu.Cost != nil {
+			total.Add(*pu.Cost)
+		}
+	}
+	return total
+}
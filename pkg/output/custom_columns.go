@@ -0,0 +1,166 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// noneValue is printed for columns that resolve to nothing, as kubectl does
+const noneValue = "<none>"
+
+// relaxedJSONPathRegexp matches a jsonpath expression with optional braces and leading dot
+var relaxedJSONPathRegexp = regexp.MustCompile(`^\{?(\.?[^{}]+)\}?$`)
+
+// customColumn is a single HEADER:expression pair
+type customColumn struct {
+	header string
+	parser *jsonpath.JSONPath
+}
+
+// CustomColumnsFormatter formats output as kubectl-style custom columns
+type CustomColumnsFormatter struct {
+	columns []customColumn
+}
+
+// NewCustomColumnsFormatter creates a CustomColumnsFormatter from a spec such as
+// NAME:.pod,MEM:.memory.limitPercent. For custom-columns-file the spec is read
+// from a file whose first line holds the headers and second line the expressions.
+func NewCustomColumnsFormatter(format, spec string) (*CustomColumnsFormatter, error) {
+	if format == "custom-columns-file" {
+		data, err := os.ReadFile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("error reading custom columns file %s: %w", spec, err)
+		}
+		spec, err = columnsFileToSpec(string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parts := strings.Split(spec, ",")
+	columns := make([]customColumn, 0, len(parts))
+	for _, part := range parts {
+		header, expr, found := strings.Cut(part, ":")
+		if !found || header == "" || expr == "" {
+			return nil, fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", part)
+		}
+		relaxed, err := relaxedJSONPathExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		parser := jsonpath.New(header).AllowMissingKeys(true)
+		if err := parser.Parse(relaxed); err != nil {
+			return nil, fmt.Errorf("error parsing custom column %s: %w", header, err)
+		}
+		columns = append(columns, customColumn{header: header, parser: parser})
+	}
+
+	return &CustomColumnsFormatter{columns: columns}, nil
+}
+
+// Format writes pod usages as custom columns
+func (f *CustomColumnsFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	tw := printers.GetNewTabWriter(w)
+
+	headers := make([]string, 0, len(f.columns))
+	for _, column := range f.columns {
+		headers = append(headers, column.header)
+	}
+	if _, err := fmt.Fprintln(tw, strings.Join(headers, "\t")); err != nil {
+		return err
+	}
+
+	for _, item := range toStructuredOutput(podUsages).Items {
+		obj, err := toJSONObject(item)
+		if err != nil {
+			return err
+		}
+		values := make([]string, 0, len(f.columns))
+		for _, column := range f.columns {
+			value, err := column.evaluate(obj)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if _, err := fmt.Fprintln(tw, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// evaluate resolves the column expression against a single item
+func (c customColumn) evaluate(obj interface{}) (string, error) {
+	results, err := c.parser.FindResults(obj)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating custom column %s: %w", c.header, err)
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return noneValue, nil
+	}
+
+	values := make([]string, 0, len(results[0]))
+	for _, result := range results[0] {
+		if !result.IsValid() || (result.Kind() == reflect.Interface && result.IsNil()) {
+			values = append(values, noneValue)
+			continue
+		}
+		values = append(values, fmt.Sprintf("%v", result.Interface()))
+	}
+	return strings.Join(values, ","), nil
+}
+
+// relaxedJSONPathExpression accepts .pod, pod, {.pod} and returns {.pod}
+func relaxedJSONPathExpression(expr string) (string, error) {
+	matches := relaxedJSONPathRegexp.FindStringSubmatch(expr)
+	if matches == nil {
+		return "", fmt.Errorf("unexpected path string, expected a 'name1.name2' or '.name1.name2' or '{name1.name2}' or '{.name1.name2}'")
+	}
+	path := matches[1]
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}", nil
+}
+
+// columnsFileToSpec converts a custom-columns file (headers line, expressions line) to a spec string
+func columnsFileToSpec(data string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) != 2 {
+		return "", fmt.Errorf("custom columns file must contain exactly two lines: headers and expressions")
+	}
+	headers := strings.Fields(lines[0])
+	exprs := strings.Fields(lines[1])
+	if len(headers) != len(exprs) {
+		return "", fmt.Errorf("custom columns file has %d headers but %d expressions", len(headers), len(exprs))
+	}
+	parts := make([]string, 0, len(headers))
+	for i := range headers {
+		parts = append(parts, headers[i]+":"+exprs[i])
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// toJSONObject round-trips a value through JSON so jsonpath sees json field names
+func toJSONObject(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
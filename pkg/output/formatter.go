@@ -2,8 +2,12 @@ package output
 
 import (
 	"io"
+	"math"
+	"time"
 
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Formatter is the interface for output formatters
@@ -15,22 +19,38 @@ type Formatter interface {
 type FormatterOptions struct {
 	ColorMode ColorMode
 	Unit      string
+	Markers   MarkerStyle
+	Summary   ReportSummary
+	Currency  string      // symbol estimated costs are shown in, "$" if empty
+	Colors    ColorScheme // thresholds and palette percentages are colored with
+	Trends    bool        // track the previous sample to show trend arrows (watch mode)
+	Precision int         // decimal places of percentages in table, wide, markdown and HTML output
 }
 
 // NewFormatter creates a formatter based on the format type
 func NewFormatter(format string, opts FormatterOptions) Formatter {
-	colorizer := NewColorizer(opts.ColorMode)
+	colorizer := NewColorizerWithScheme(opts.ColorMode, opts.Colors).WithPrecision(opts.Precision)
 	unitFormatter := NewUnitFormatter(opts.Unit)
+	var trends *TrendTracker
+	if opts.Trends {
+		trends = NewTrendTracker()
+	}
 
 	switch format {
 	case "json":
 		return &JSONFormatter{}
 	case "yaml":
 		return &YAMLFormatter{}
+	case "ndjson":
+		return &NDJSONFormatter{}
+	case "html":
+		return &HTMLFormatter{unitFormatter: unitFormatter, currency: opts.Currency, colors: opts.Colors, precision: opts.Precision}
+	case "markdown":
+		return &MarkdownFormatter{unitFormatter: unitFormatter, markers: opts.Markers, summary: opts.Summary, currency: opts.Currency, colors: opts.Colors, precision: opts.Precision}
 	case "wide":
-		return &WideFormatter{colorizer: colorizer, unitFormatter: unitFormatter}
+		return &WideFormatter{colorizer: colorizer, unitFormatter: unitFormatter, trends: trends, currency: opts.Currency}
 	default:
-		return &TableFormatter{colorizer: colorizer, unitFormatter: unitFormatter}
+		return &TableFormatter{colorizer: colorizer, unitFormatter: unitFormatter, trends: trends, currency: opts.Currency}
 	}
 }
 
@@ -41,20 +61,59 @@ type StructuredOutput struct {
 
 // StructuredPodUsage represents a pod's resource usage in structured format
 type StructuredPodUsage struct {
-	Namespace string                  `json:"namespace" yaml:"namespace"`
-	Pod       string                  `json:"pod" yaml:"pod"`
-	Node      string                  `json:"node" yaml:"node"`
-	CPU       StructuredResourceUsage `json:"cpu" yaml:"cpu"`
-	Memory    StructuredResourceUsage `json:"memory" yaml:"memory"`
+	Cluster          string                   `json:"cluster,omitempty" yaml:"cluster,omitempty"` // kubeconfig context, set with --contexts
+	Namespace        string                   `json:"namespace" yaml:"namespace"`
+	Pod              string                   `json:"pod" yaml:"pod"`
+	Node             string                   `json:"node" yaml:"node"`
+	CPU              StructuredResourceUsage  `json:"cpu" yaml:"cpu"`
+	Memory           StructuredResourceUsage  `json:"memory" yaml:"memory"`
+	EphemeralStorage *StructuredResourceUsage `json:"ephemeralStorage,omitempty" yaml:"ephemeralStorage,omitempty"` // set when storage was collected
+
+	// Extended holds the other resources, such as GPUs and hugepages, by name
+	Extended map[string]StructuredResourceUsage `json:"extended,omitempty" yaml:"extended,omitempty"`
+
+	Phase           string                 `json:"phase" yaml:"phase"`
+	QOSClass        string                 `json:"qosClass" yaml:"qosClass"`
+	Restarts        int32                  `json:"restarts" yaml:"restarts"`
+	LastTermination *StructuredTermination `json:"lastTermination" yaml:"lastTermination"`
+	LastOOMKill     *StructuredTermination `json:"lastOOMKill" yaml:"lastOOMKill"`
+
+	// Cost is the estimated monthly cost, present when pricing is configured
+	Cost *StructuredCost `json:"cost,omitempty" yaml:"cost,omitempty"`
+
+	// Throttling is the CPU throttling, present when it was collected with --throttling
+	Throttling *StructuredThrottling `json:"throttling,omitempty" yaml:"throttling,omitempty"`
 }
 
-// StructuredResourceUsage represents CPU or Memory usage in structured format
+// StructuredCost represents an estimated monthly cost in structured format,
+// in the pricing's currency and rounded to cents
+type StructuredCost struct {
+	Requests float64 `json:"requests" yaml:"requests"`
+	Usage    float64 `json:"usage" yaml:"usage"`
+	Idle     float64 `json:"idle" yaml:"idle"`
+}
+
+// StructuredTermination represents a container termination in structured format
+type StructuredTermination struct {
+	Container  string `json:"container" yaml:"container"`
+	Reason     string `json:"reason" yaml:"reason"`
+	ExitCode   int32  `json:"exitCode" yaml:"exitCode"`
+	FinishedAt string `json:"finishedAt" yaml:"finishedAt"`
+}
+
+// StructuredResourceUsage represents CPU, memory or ephemeral storage usage in structured format
 type StructuredResourceUsage struct {
-	Usage          string  `json:"usage" yaml:"usage"`
-	Requests       *string `json:"requests" yaml:"requests"`
-	Limits         *string `json:"limits" yaml:"limits"`
-	RequestPercent *int    `json:"requestPercent" yaml:"requestPercent"`
-	LimitPercent   *int    `json:"limitPercent" yaml:"limitPercent"`
+	Usage          string   `json:"usage,omitempty" yaml:"usage,omitempty"` // empty if no usage was reported
+	Requests       *string  `json:"requests" yaml:"requests"`
+	Limits         *string  `json:"limits" yaml:"limits"`
+	RequestPercent *float64 `json:"requestPercent" yaml:"requestPercent"` // unrounded
+	LimitPercent   *float64 `json:"limitPercent" yaml:"limitPercent"`     // unrounded
+	RequestSource  string   `json:"requestSource" yaml:"requestSource"`
+	LimitSource    string   `json:"limitSource" yaml:"limitSource"`
+
+	// DefaultRequests and DefaultLimits are the values with the LimitRange defaults filled in
+	DefaultRequests *string `json:"defaultRequests,omitempty" yaml:"defaultRequests,omitempty"`
+	DefaultLimits   *string `json:"defaultLimits,omitempty" yaml:"defaultLimits,omitempty"`
 }
 
 // toStructuredOutput converts pod usages to structured output format
@@ -63,13 +122,32 @@ func toStructuredOutput(podUsages []calculator.PodUsage) StructuredOutput {
 		Items: make([]StructuredPodUsage, 0, len(podUsages)),
 	}
 
+	storage := false
+	for _, def := range calculator.ShownResources(podUsages) {
+		storage = storage || def.Name == corev1.ResourceEphemeralStorage
+	}
+
 	for _, pu := range podUsages {
 		structuredPod := StructuredPodUsage{
+			Cluster:   pu.Cluster,
 			Namespace: pu.Namespace,
 			Pod:       pu.Name,
 			Node:      pu.Node,
-			CPU:       toStructuredResourceUsage(pu.CPU),
-			Memory:    toStructuredResourceUsage(pu.Memory),
+			CPU:       toStructuredResourceUsage(pu.Resources[corev1.ResourceCPU]),
+			Memory:    toStructuredResourceUsage(pu.Resources[corev1.ResourceMemory]),
+			Extended:  toStructuredExtended(pu.Resources),
+
+			Phase:           string(pu.Status.Phase),
+			QOSClass:        string(pu.Status.QOSClass),
+			Restarts:        pu.Status.Restarts,
+			LastTermination: toStructuredTermination(pu.Status.LastTermination),
+			LastOOMKill:     toStructuredTermination(pu.Status.LastOOMKill),
+			Cost:            ToStructuredCost(pu.Cost),
+			Throttling:      toStructuredThrottling(pu.Throttling),
+		}
+		if storage {
+			eph := toStructuredResourceUsage(pu.Resources[corev1.ResourceEphemeralStorage])
+			structuredPod.EphemeralStorage = &eph
 		}
 		output.Items = append(output.Items, structuredPod)
 	}
@@ -77,12 +155,30 @@ func toStructuredOutput(podUsages []calculator.PodUsage) StructuredOutput {
 	return output
 }
 
+// ToStructuredCost converts an estimated cost to StructuredCost, nil if there is none
+func ToStructuredCost(c *calculator.Cost) *StructuredCost {
+	if c == nil {
+		return nil
+	}
+	return &StructuredCost{Requests: roundCents(c.Requests), Usage: roundCents(c.Usage), Idle: roundCents(c.Idle)}
+}
+
+// roundCents rounds a cost to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
 // toStructuredResourceUsage converts ResourceUsage to StructuredResourceUsage
 func toStructuredResourceUsage(ru calculator.ResourceUsage) StructuredResourceUsage {
 	result := StructuredResourceUsage{
-		Usage:          ru.Usage.String(),
 		RequestPercent: ru.RequestPercent,
 		LimitPercent:   ru.LimitPercent,
+		RequestSource:  string(valueSource(ru.RequestSource, ru.Requests)),
+		LimitSource:    string(valueSource(ru.LimitSource, ru.Limits)),
+	}
+
+	if !ru.UsageUnavailable {
+		result.Usage = ru.Usage.String()
 	}
 
 	if ru.Requests != nil {
@@ -95,5 +191,66 @@ func toStructuredResourceUsage(ru calculator.ResourceUsage) StructuredResourceUs
 		result.Limits = &s
 	}
 
+	if ru.DefaultRequests != nil {
+		s := ru.DefaultRequests.String()
+		result.DefaultRequests = &s
+	}
+
+	if ru.DefaultLimits != nil {
+		s := ru.DefaultLimits.String()
+		result.DefaultLimits = &s
+	}
+
+	return result
+}
+
+// structuredFields are the resources with their own field in StructuredPodUsage
+var structuredFields = map[corev1.ResourceName]bool{
+	corev1.ResourceCPU:              true,
+	corev1.ResourceMemory:           true,
+	corev1.ResourceEphemeralStorage: true,
+}
+
+// toStructuredExtended converts the usages of resources without their own
+// field, keyed by resource name, or returns nil if there are none
+func toStructuredExtended(resources calculator.ResourceUsages) map[string]StructuredResourceUsage {
+	var result map[string]StructuredResourceUsage
+	for name, ru := range resources {
+		if structuredFields[name] {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]StructuredResourceUsage)
+		}
+		result[string(name)] = toStructuredResourceUsage(ru)
+	}
 	return result
 }
+
+// toStructuredTermination converts a Termination to StructuredTermination, keeping nil as nil
+func toStructuredTermination(t *calculator.Termination) *StructuredTermination {
+	if t == nil {
+		return nil
+	}
+	result := &StructuredTermination{
+		Container: t.Container,
+		Reason:    t.Reason,
+		ExitCode:  t.ExitCode,
+	}
+	if !t.FinishedAt.IsZero() {
+		result.FinishedAt = t.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return result
+}
+
+// valueSource returns where a value came from, inferring it for usages built
+// without a source: a set value is explicit, a missing one is none
+func valueSource(source calculator.ValueSource, q *resource.Quantity) calculator.ValueSource {
+	if source != "" {
+		return source
+	}
+	if q != nil {
+		return calculator.SourceExplicit
+	}
+	return calculator.SourceNone
+}
@@ -0,0 +1,268 @@
+package output
+
+import (
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// svgBarWidth is the width of the inline SVG bars in pixels; 100% fills the bar
+const svgBarWidth = 120
+
+// HTMLFormatter formats output as a self-contained HTML report with no external assets
+type HTMLFormatter struct {
+	unitFormatter *UnitFormatter
+	currency      string
+	colors        ColorScheme
+	precision     int // decimal places of percentages
+	now           func() time.Time
+}
+
+// htmlReport is the data passed to the HTML template
+type htmlReport struct {
+	Generated  string
+	Resources  []calculator.ResourceDefinition
+	Bar        calculator.ResourceDefinition // resource whose Limit% is drawn as a bar in rollups
+	Pods       []calculator.PodUsage
+	Clustered  bool    // pods come from several clusters and get a CLUSTER column
+	Priced     bool    // pods have estimated costs and get monthly cost columns
+	Palette    Palette // body class selecting the severity colors
+	Namespaces []calculator.GroupUsage
+	Workloads  []calculator.GroupUsage
+	Nodes      []calculator.GroupUsage
+}
+
+// htmlCost is a cost cell's text and sort value
+type htmlCost struct {
+	Text  string
+	Value float64
+}
+
+// htmlCells is the data of a row's resource cells
+type htmlCells struct {
+	Definitions []calculator.ResourceDefinition
+	Resources   calculator.ResourceUsages
+}
+
+// htmlBar is the data of a rollup's Limit% bar
+type htmlBar struct {
+	Resource corev1.ResourceName
+	Percent  *float64
+}
+
+// htmlRollup is the data of a rollup table
+type htmlRollup struct {
+	Report htmlReport
+	Groups []calculator.GroupUsage
+}
+
+// Format writes pod usages as an HTML report
+func (f *HTMLFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	now := time.Now
+	if f.now != nil {
+		now = f.now
+	}
+
+	report := htmlReport{
+		Generated:  now().UTC().Format(time.RFC3339),
+		Resources:  calculator.ShownResources(podUsages),
+		Bar:        calculator.DefinitionFor(corev1.ResourceMemory),
+		Pods:       podUsages,
+		Clustered:  hasClusters(podUsages),
+		Priced:     hasCosts(podUsages),
+		Palette:    f.colors.palette(),
+		Namespaces: calculator.RollupByNamespace(podUsages),
+		Workloads:  calculator.RollupByWorkload(podUsages),
+		Nodes:      calculator.RollupByNode(podUsages),
+	}
+	return f.template().Execute(w, report)
+}
+
+// template builds the report template with unit-aware helper functions
+func (f *HTMLFormatter) template() *template.Template {
+	funcs := template.FuncMap{
+		"usage": func(def calculator.ResourceDefinition, ru calculator.ResourceUsage) string {
+			return f.unitFormatter.FormatUsage(def.Unit, ru)
+		},
+		"usageValue": usageSortValue,
+		"cells": func(report htmlReport, resources calculator.ResourceUsages) htmlCells {
+			return htmlCells{Definitions: report.Resources, Resources: resources}
+		},
+		"rollup": func(report htmlReport, groups []calculator.GroupUsage) htmlRollup {
+			return htmlRollup{Report: report, Groups: groups}
+		},
+		"costColumns": func() []string { return costColumns },
+		"costs": func(c *calculator.Cost) []htmlCost {
+			if c == nil {
+				return []htmlCost{{"N/A", -1}, {"N/A", -1}, {"N/A", -1}}
+			}
+			return []htmlCost{
+				{FormatCost(f.currency, c.Requests), c.Requests},
+				{FormatCost(f.currency, c.Usage), c.Usage},
+				{FormatCost(f.currency, c.Idle), c.Idle},
+			}
+		},
+		"percent": func(p *float64) string {
+			return FormatPercentText(p, f.precision)
+		},
+		"percentValue": percentSortValue,
+		"percentClass": func(resource corev1.ResourceName, metric string, p *float64) Severity {
+			return f.colors.Severity(Field{resource, metric}, p)
+		},
+		"bar":      percentBar,
+		"barWidth": func() int { return svgBarWidth },
+		"barOf": func(resource corev1.ResourceName, p *float64) htmlBar {
+			return htmlBar{Resource: resource, Percent: p}
+		},
+	}
+	return template.Must(template.New("report").Funcs(funcs).Parse(htmlTemplate))
+}
+
+// percentSortValue returns the value used to sort a percentage column; N/A sorts lowest
+func percentSortValue(p *float64) float64 {
+	if p == nil {
+		return -1
+	}
+	return *p
+}
+
+// usageSortValue returns the sort key of a usage in its unit family, sorting N/A before any usage
+func usageSortValue(def calculator.ResourceDefinition, ru calculator.ResourceUsage) int64 {
+	switch {
+	case ru.UsageUnavailable:
+		return -1
+	case def.Unit == calculator.UnitFamilyCPU:
+		return ru.Usage.MilliValue()
+	default:
+		return ru.Usage.Value()
+	}
+}
+
+// percentBar returns the width in pixels of the SVG bar for a percentage, capped at 100%
+func percentBar(p *float64) int {
+	if p == nil || *p <= 0 {
+		return 0
+	}
+	if *p >= 100 {
+		return svgBarWidth
+	}
+	return int(*p * svgBarWidth / 100)
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Resource Usage Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.5em; }
+h2 { font-size: 1.2em; margin-top: 2em; }
+table { border-collapse: collapse; font-size: 0.9em; }
+th, td { padding: 4px 10px; border-bottom: 1px solid #d0d7de; text-align: left; white-space: nowrap; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th.sorted-asc::after { content: " \25B2"; }
+th.sorted-desc::after { content: " \25BC"; }
+td.over { color: #8250df; font-weight: bold; }
+td.high { color: #cf222e; font-weight: bold; }
+td.medium { color: #9a6700; }
+td.low { color: #1a7f37; }
+td.waste { color: #0969da; }
+td.na { color: #6e7781; }
+rect.over { fill: #8250df; }
+rect.high { fill: #cf222e; }
+rect.medium { fill: #d4a72c; }
+rect.low { fill: #2da44e; }
+rect.waste { fill: #54aeff; }
+body.colorblind td.over { color: #cc79a7; }
+body.colorblind td.high { color: #d55e00; }
+body.colorblind td.medium { color: #b07800; }
+body.colorblind td.low { color: #0072b2; }
+body.colorblind td.waste { color: #3a8fc4; }
+body.colorblind rect.over { fill: #cc79a7; }
+body.colorblind rect.high { fill: #d55e00; }
+body.colorblind rect.medium { fill: #e69f00; }
+body.colorblind rect.low { fill: #0072b2; }
+body.colorblind rect.waste { fill: #56b4e9; }
+rect.track { fill: #eaeef2; }
+#filter { margin: 1em 0; padding: 4px 8px; width: 24em; }
+.meta { color: #57606a; }
+</style>
+</head>
+<body class="{{.Palette}}">
+<h1>Resource Usage Report</h1>
+<p class="meta">Generated {{.Generated}} &middot; {{len .Pods}} pods &middot; {{len .Namespaces}} namespaces &middot; {{len .Workloads}} workloads &middot; {{len .Nodes}} nodes</p>
+{{define "bar"}}<svg width="{{barWidth}}" height="10" role="img"><rect class="track" width="{{barWidth}}" height="10"></rect><rect class="{{percentClass .Resource "limitPercent" .Percent}}" width="{{bar .Percent}}" height="10"></rect></svg>{{end}}
+{{define "header"}}{{range .}}<th>{{.Column}}_USAGE</th><th>{{.Column}}_REQ%</th><th>{{.Column}}_LIM%</th>{{end}}{{end}}
+{{define "cells"}}{{$resources := .Resources}}{{range .Definitions}}{{$ru := index $resources .Name}}
+<td data-value="{{usageValue . $ru}}">{{usage . $ru}}</td>
+<td class="{{percentClass .Name "requestPercent" $ru.RequestPercent}}" data-value="{{percentValue $ru.RequestPercent}}">{{percent $ru.RequestPercent}}</td>
+<td class="{{percentClass .Name "limitPercent" $ru.LimitPercent}}" data-value="{{percentValue $ru.LimitPercent}}">{{percent $ru.LimitPercent}}</td>{{end}}{{end}}
+{{define "costHeader"}}{{if .}}{{range costColumns}}<th>{{.}}</th>{{end}}{{end}}{{end}}
+{{define "costs"}}{{range costs .}}
+<td data-value="{{.Value}}">{{.Text}}</td>{{end}}{{end}}
+{{define "rollup"}}{{$report := .Report}}
+<table class="sortable">
+<thead><tr><th>NAME</th><th>PODS</th>{{template "header" $report.Resources}}{{template "costHeader" $report.Priced}}<th>{{$report.Bar.Column}}_LIM% BAR</th></tr></thead>
+<tbody>
+{{range .Groups}}<tr>
+<td>{{if .Name}}{{.Name}}{{else}}&lt;none&gt;{{end}}</td>
+<td data-value="{{.Pods}}">{{.Pods}}</td>{{template "cells" (cells $report .Resources)}}{{if $report.Priced}}{{template "costs" .Cost}}{{end}}
+{{$bar := index .Resources $report.Bar.Name}}<td data-value="{{percentValue $bar.LimitPercent}}">{{template "bar" (barOf $report.Bar.Name $bar.LimitPercent)}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+<h2>Pods</h2>
+<input id="filter" type="search" placeholder="Filter by namespace, pod or node">
+<table id="pods" class="sortable">
+<thead><tr>{{if .Clustered}}<th>CLUSTER</th>{{end}}<th>NAMESPACE</th><th>POD</th>{{template "header" .Resources}}{{template "costHeader" .Priced}}<th>NODE</th></tr></thead>
+<tbody>
+{{range .Pods}}<tr>
+{{if $.Clustered}}<td>{{.Cluster}}</td>
+{{end}}<td>{{.Namespace}}</td>
+<td>{{.Name}}</td>{{template "cells" (cells $ .Resources)}}{{if $.Priced}}{{template "costs" .Cost}}{{end}}
+<td>{{.Node}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<h2>Namespaces</h2>
+{{template "rollup" (rollup . .Namespaces)}}
+<h2>Workloads</h2>
+{{template "rollup" (rollup . .Workloads)}}
+<h2>Nodes</h2>
+{{template "rollup" (rollup . .Nodes)}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function (table) {
+  table.querySelectorAll("th").forEach(function (th, col) {
+    th.addEventListener("click", function () {
+      var asc = !th.classList.contains("sorted-asc");
+      table.querySelectorAll("th").forEach(function (h) { h.classList.remove("sorted-asc", "sorted-desc"); });
+      th.classList.add(asc ? "sorted-asc" : "sorted-desc");
+      var tbody = table.tBodies[0];
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function (a, b) {
+        var ca = a.cells[col], cb = b.cells[col];
+        var va = ca.dataset.value, vb = cb.dataset.value;
+        var cmp = (va !== undefined && vb !== undefined)
+          ? Number(va) - Number(vb)
+          : ca.textContent.localeCompare(cb.textContent);
+        return asc ? cmp : -cmp;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+    });
+  });
+});
+document.getElementById("filter").addEventListener("input", function (e) {
+  var needle = e.target.value.toLowerCase();
+  Array.prototype.forEach.call(document.getElementById("pods").tBodies[0].rows, function (row) {
+    row.style.display = row.textContent.toLowerCase().indexOf(needle) === -1 ? "none" : "";
+  });
+});
+</script>
+</body>
+</html>
+`
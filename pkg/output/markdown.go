@@ -0,0 +1,180 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// MarkerStyle represents how severity is marked in plain-text output
+type MarkerStyle string
+
+const (
+	MarkerStyleEmoji MarkerStyle = "emoji"
+	MarkerStyleText  MarkerStyle = "text"
+	MarkerStyleNone  MarkerStyle = "none"
+)
+
+// ValidMarkerStyles returns the list of valid marker style options
+func ValidMarkerStyles() []string {
+	return []string{"emoji", "text", "none"}
+}
+
+// IsValidMarkerStyle checks if the given marker style is valid
+func IsValidMarkerStyle(m string) bool {
+	for _, valid := range ValidMarkerStyles() {
+		if m == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportSummary describes where the data in a report came from
+type ReportSummary struct {
+	Cluster   string
+	Context   string
+	Namespace string
+}
+
+// MarkdownFormatter formats output as a GitHub-flavored markdown table
+// that pastes cleanly into PRs, tickets and incident docs
+type MarkdownFormatter struct {
+	unitFormatter *UnitFormatter
+	markers       MarkerStyle
+	summary       ReportSummary
+	currency      string
+	colors        ColorScheme
+	precision     int // decimal places of percentages
+	now           func() time.Time
+}
+
+// Format writes pod usages as markdown
+func (f *MarkdownFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	now := time.Now
+	if f.now != nil {
+		now = f.now
+	}
+
+	namespace := f.summary.Namespace
+	if namespace == "" {
+		namespace = "all namespaces"
+	}
+
+	var b strings.Builder
+	b.WriteString("### Resource Usage\n\n")
+	fmt.Fprintf(&b, "- **Cluster:** %s\n", orNone(f.summary.Cluster))
+	fmt.Fprintf(&b, "- **Context:** %s\n", orNone(f.summary.Context))
+	fmt.Fprintf(&b, "- **Namespace:** %s\n", escapeMarkdown(namespace))
+	fmt.Fprintf(&b, "- **Timestamp:** %s\n", now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **Pods:** %d\n", len(podUsages))
+	priced := hasCosts(podUsages)
+	if priced {
+		total := totalCost(podUsages)
+		fmt.Fprintf(&b, "- **Monthly cost:** %s requested, %s used, %s idle\n",
+			FormatCost(f.currency, total.Requests), FormatCost(f.currency, total.Usage), FormatCost(f.currency, total.Idle))
+	}
+	b.WriteString("\n")
+
+	resources := calculator.ShownResources(podUsages)
+	clustered := hasClusters(podUsages)
+	if clustered {
+		b.WriteString("| CLUSTER ")
+	}
+	b.WriteString("| NAMESPACE | POD |")
+	for _, def := range resources {
+		fmt.Fprintf(&b, " %[1]s_USAGE | %[1]s_REQ%% | %[1]s_LIM%% |", def.Column)
+	}
+	if priced {
+		for _, column := range costColumns {
+			fmt.Fprintf(&b, " %s |", column)
+		}
+	}
+	b.WriteString(" NODE |\n")
+	if clustered {
+		b.WriteString("|---")
+	}
+	b.WriteString("|---|---|")
+	b.WriteString(strings.Repeat("--:|", 3*len(resources)))
+	if priced {
+		b.WriteString(strings.Repeat("--:|", len(costColumns)))
+	}
+	b.WriteString("---|\n")
+	for _, pu := range podUsages {
+		if clustered {
+			fmt.Fprintf(&b, "| %s ", escapeMarkdown(pu.Cluster))
+		}
+		fmt.Fprintf(&b, "| %s | %s |", escapeMarkdown(pu.Namespace), escapeMarkdown(pu.Name))
+		for _, def := range resources {
+			ru := pu.Resources[def.Name]
+			fmt.Fprintf(&b, " %s | %s | %s |",
+				f.unitFormatter.FormatUsage(def.Unit, ru),
+				f.formatPercent(Field{def.Name, MetricRequestPercent}, ru.RequestPercent),
+				f.formatPercent(Field{def.Name, MetricLimitPercent}, ru.LimitPercent))
+		}
+		if priced {
+			for _, value := range formatCosts(f.currency, pu.Cost) {
+				fmt.Fprintf(&b, " %s |", value)
+			}
+		}
+		fmt.Fprintf(&b, " %s |\n", escapeMarkdown(pu.Node))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatPercent formats a field's percentage with a marker for its severity band
+func (f *MarkdownFormatter) formatPercent(field Field, p *float64) string {
+	text := FormatPercentText(p, f.precision)
+	if p == nil {
+		return text
+	}
+
+	switch f.markers {
+	case MarkerStyleEmoji:
+		switch f.colors.Severity(field, p) {
+		case SeverityOver:
+			return "🟣 " + text
+		case SeverityHigh:
+			return "🔴 " + text
+		case SeverityMedium:
+			return "🟡 " + text
+		case SeverityWaste:
+			return "🔵 " + text
+		default:
+			return "🟢 " + text
+		}
+	case MarkerStyleText:
+		switch f.colors.Severity(field, p) {
+		case SeverityOver:
+			return "**" + text + " OVER**"
+		case SeverityHigh:
+			return "**" + text + " HIGH**"
+		case SeverityMedium:
+			return text + " WARN"
+		case SeverityWaste:
+			return text + " WASTE"
+		default:
+			return text
+		}
+	default:
+		return text
+	}
+}
+
+// escapeMarkdown escapes characters that would break a markdown table cell
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// orNone returns s escaped for markdown, or a placeholder if it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "_unknown_"
+	}
+	return escapeMarkdown(s)
+}
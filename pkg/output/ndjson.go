@@ -0,0 +1,44 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// NDJSONRecord is a single pod sample written as one line of NDJSON
+type NDJSONRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	StructuredPodUsage
+}
+
+// NDJSONFormatter formats output as newline-delimited JSON, one compact
+// object per pod per sample, so watch output can be piped to jq or a file
+type NDJSONFormatter struct {
+	now func() time.Time
+}
+
+// Format writes one JSON line per pod usage, all sharing the sample timestamp
+func (f *NDJSONFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	now := time.Now
+	if f.now != nil {
+		now = f.now
+	}
+	timestamp := now().UTC()
+
+	encoder := json.NewEncoder(w)
+	for _, item := range toStructuredOutput(podUsages).Items {
+		if err := encoder.Encode(NDJSONRecord{Timestamp: timestamp, StructuredPodUsage: item}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsStreamingFormat checks if the output format is a line-oriented stream
+// that must not be interleaved with screen control sequences
+func IsStreamingFormat(format string) bool {
+	return format == "ndjson"
+}
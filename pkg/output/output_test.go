@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -16,19 +18,21 @@ func TestTableFormatter(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				Requests:       resourcePtr(resource.MustParse("200m")),
-				Limits:         resourcePtr(resource.MustParse("500m")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(20),
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				Requests:       resourcePtr(resource.MustParse("256Mi")),
-				Limits:         resourcePtr(resource.MustParse("512Mi")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(25),
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       resourcePtr(resource.MustParse("200m")),
+					Limits:         resourcePtr(resource.MustParse("500m")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(20),
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					Limits:         resourcePtr(resource.MustParse("512Mi")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(25),
+				},
 			},
 		},
 	}
@@ -68,15 +72,17 @@ func TestTableFormatterWithNA(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				RequestPercent: nil,
-				LimitPercent:   nil,
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				RequestPercent: nil,
-				LimitPercent:   nil,
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
 			},
 		},
 	}
@@ -100,19 +106,21 @@ func TestJSONFormatter(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				Requests:       resourcePtr(resource.MustParse("200m")),
-				Limits:         resourcePtr(resource.MustParse("500m")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(20),
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				Requests:       resourcePtr(resource.MustParse("256Mi")),
-				Limits:         resourcePtr(resource.MustParse("512Mi")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(25),
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       resourcePtr(resource.MustParse("300m")),
+					Limits:         resourcePtr(resource.MustParse("500m")),
+					RequestPercent: floatPtr(100.0 / 3),
+					LimitPercent:   floatPtr(20),
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					Limits:         resourcePtr(resource.MustParse("512Mi")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(25),
+				},
 			},
 		},
 	}
@@ -143,8 +151,8 @@ func TestJSONFormatter(t *testing.T) {
 	if item.Pod != "test-pod" {
 		t.Errorf("expected pod 'test-pod', got '%s'", item.Pod)
 	}
-	if item.CPU.RequestPercent == nil || *item.CPU.RequestPercent != 50 {
-		t.Errorf("expected CPU request percent 50, got %v", item.CPU.RequestPercent)
+	if item.CPU.RequestPercent == nil || *item.CPU.RequestPercent != 100.0/3 {
+		t.Errorf("expected CPU request percent at full precision, got %v", item.CPU.RequestPercent)
 	}
 }
 
@@ -154,15 +162,17 @@ func TestJSONFormatterWithNil(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				RequestPercent: nil,
-				LimitPercent:   nil,
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				RequestPercent: nil,
-				LimitPercent:   nil,
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
 			},
 		},
 	}
@@ -196,19 +206,21 @@ func TestYAMLFormatter(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				Requests:       resourcePtr(resource.MustParse("200m")),
-				Limits:         resourcePtr(resource.MustParse("500m")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(20),
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				Requests:       resourcePtr(resource.MustParse("256Mi")),
-				Limits:         resourcePtr(resource.MustParse("512Mi")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(25),
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       resourcePtr(resource.MustParse("200m")),
+					Limits:         resourcePtr(resource.MustParse("500m")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(20),
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					Limits:         resourcePtr(resource.MustParse("512Mi")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(25),
+				},
 			},
 		},
 	}
@@ -240,19 +252,21 @@ func TestWideFormatter(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				Requests:       resourcePtr(resource.MustParse("200m")),
-				Limits:         resourcePtr(resource.MustParse("500m")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(20),
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				Requests:       resourcePtr(resource.MustParse("256Mi")),
-				Limits:         resourcePtr(resource.MustParse("512Mi")),
-				RequestPercent: intPtr(50),
-				LimitPercent:   intPtr(25),
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       resourcePtr(resource.MustParse("200m")),
+					Limits:         resourcePtr(resource.MustParse("500m")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(20),
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					Limits:         resourcePtr(resource.MustParse("512Mi")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(25),
+				},
 			},
 		},
 	}
@@ -301,19 +315,21 @@ func TestWideFormatterWithNA(t *testing.T) {
 			Namespace: "default",
 			Name:      "test-pod",
 			Node:      "node-1",
-			CPU: calculator.ResourceUsage{
-				Usage:          resource.MustParse("100m"),
-				Requests:       nil,
-				Limits:         nil,
-				RequestPercent: nil,
-				LimitPercent:   nil,
-			},
-			Memory: calculator.ResourceUsage{
-				Usage:          resource.MustParse("128Mi"),
-				Requests:       nil,
-				Limits:         nil,
-				RequestPercent: nil,
-				LimitPercent:   nil,
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       nil,
+					Limits:         nil,
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       nil,
+					Limits:         nil,
+					RequestPercent: nil,
+					LimitPercent:   nil,
+				},
 			},
 		},
 	}
@@ -331,6 +347,364 @@ func TestWideFormatterWithNA(t *testing.T) {
 	}
 }
 
+func TestWideFormatterLimitRangeSource(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "legacy-pod",
+			Node:      "node-1",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:         resource.MustParse("100m"),
+					RequestSource: calculator.SourceNone,
+					LimitSource:   calculator.SourceNone,
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					RequestPercent: floatPtr(50),
+					RequestSource:  calculator.SourceLimitRange,
+					LimitSource:    calculator.SourceNone,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := &WideFormatter{colorizer: NewColorizer(ColorModeNever), unitFormatter: NewUnitFormatter("auto")}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "256Mi*") {
+		t.Errorf("expected assumed memory request to be marked, got:\n%s", output)
+	}
+	if !strings.Contains(output, "includes namespace LimitRange defaults") {
+		t.Errorf("expected LimitRange note, got:\n%s", output)
+	}
+
+	var structured bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&structured, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(structured.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	mem := result.Items[0].Memory
+	if mem.RequestSource != "limitrange" || mem.LimitSource != "none" {
+		t.Errorf("expected memory sources limitrange/none, got %s/%s", mem.RequestSource, mem.LimitSource)
+	}
+}
+
+func TestWideFormatterPodStatus(t *testing.T) {
+	finishedAt := time.Now().Add(-5 * time.Minute)
+	oom := &calculator.Termination{Container: "app", Reason: calculator.ReasonOOMKilled, ExitCode: 137, FinishedAt: finishedAt}
+	podUsages := []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "crashy-pod",
+			Node:      "node-1",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("100m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("128Mi")},
+			},
+			Status: calculator.PodStatus{
+				Phase:           "Running",
+				QOSClass:        "Burstable",
+				Restarts:        2,
+				LastTermination: oom,
+				LastOOMKill:     oom,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := &WideFormatter{colorizer: NewColorizer(ColorModeNever), unitFormatter: NewUnitFormatter("auto")}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"QOS", "PHASE", "RESTARTS", "LAST_TERMINATION", "Burstable", "Running", "OOMKilled (5m ago)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	var structured bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&structured, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(structured.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	item := result.Items[0]
+	if item.QOSClass != "Burstable" || item.Phase != "Running" || item.Restarts != 2 {
+		t.Errorf("unexpected status fields: %+v", item)
+	}
+	if item.LastOOMKill == nil || item.LastOOMKill.Reason != "OOMKilled" || item.LastOOMKill.FinishedAt != finishedAt.UTC().Format(time.RFC3339) {
+		t.Errorf("unexpected last OOM kill: %+v", item.LastOOMKill)
+	}
+}
+
+func TestFormattersEphemeralStorage(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "writer",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("100m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("128Mi")},
+				corev1.ResourceEphemeralStorage: {
+					Usage:        resource.MustParse("512Mi"),
+					Limits:       resourcePtr(resource.MustParse("1Gi")),
+					LimitPercent: floatPtr(50),
+				},
+			},
+		},
+		{
+			Namespace: "default",
+			Name:      "no-stats",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:              {Usage: resource.MustParse("100m")},
+				corev1.ResourceMemory:           {Usage: resource.MustParse("128Mi")},
+				corev1.ResourceEphemeralStorage: {UsageUnavailable: true},
+			},
+		},
+	}
+
+	for _, format := range []string{"table", "wide", "markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewFormatter(format, FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"})
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			output := buf.String()
+			for _, want := range []string{"EPH_USAGE", "512Mi", "50%"} {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if got := result.Items[0].EphemeralStorage; got.Usage != "512Mi" || got.LimitPercent == nil || *got.LimitPercent != 50 {
+		t.Errorf("unexpected ephemeral storage: %+v", got)
+	}
+	if got := result.Items[1].EphemeralStorage; got.Usage != "" || got.LimitPercent != nil {
+		t.Errorf("expected no ephemeral storage usage, got %+v", got)
+	}
+}
+
+func TestFormattersExtendedResources(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{
+			Namespace: "ml",
+			Name:      "trainer",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("2")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("4Gi")},
+				"nvidia.com/gpu":      {Requests: resourcePtr(resource.MustParse("2")), Limits: resourcePtr(resource.MustParse("2")), UsageUnavailable: true},
+				"hugepages-2Mi":       {Limits: resourcePtr(resource.MustParse("1Gi")), UsageUnavailable: true},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := &WideFormatter{colorizer: NewColorizer(ColorModeNever), unitFormatter: NewUnitFormatter("auto")}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "hugepages-2Mi=1Gi,nvidia.com/gpu=2") {
+		t.Errorf("expected extended resources in wide output, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&JSONFormatter{}).Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	gpu, ok := result.Items[0].Extended["nvidia.com/gpu"]
+	if !ok || gpu.Requests == nil || *gpu.Requests != "2" || gpu.Usage != "" {
+		t.Errorf("unexpected GPU usage: %+v", gpu)
+	}
+}
+
+func TestFormattersClusterColumn(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{Cluster: "prod-eu", Namespace: "default", Name: "api"},
+		{Cluster: "prod-us", Namespace: "default", Name: "api"},
+	}
+
+	for _, format := range []string{"table", "wide", "markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewFormatter(format, FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"})
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range []string{"CLUSTER", "prod-eu", "prod-us"} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+
+			buf.Reset()
+			single := []calculator.PodUsage{{Namespace: "default", Name: "api"}}
+			if err := formatter.Format(&buf, single); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Contains(buf.String(), "CLUSTER") {
+				t.Errorf("expected no CLUSTER column for a single cluster, got:\n%s", buf.String())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if result.Items[1].Cluster != "prod-us" {
+		t.Errorf("expected cluster prod-us, got %q", result.Items[1].Cluster)
+	}
+}
+
+func TestFormattersCost(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{Namespace: "default", Name: "api", Workload: "Deployment/api", Cost: &calculator.Cost{Requests: 120.5, Usage: 20.25, Idle: 100.25}},
+		{Namespace: "default", Name: "batch"},
+	}
+
+	for _, format := range []string{"table", "wide", "markdown", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewFormatter(format, FormatterOptions{ColorMode: ColorModeNever, Unit: "auto", Currency: "€"})
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range []string{"COST/MO", "IDLE/MO", "€120.50", "€100.25"} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := (&TableFormatter{colorizer: NewColorizer(ColorModeNever), unitFormatter: NewUnitFormatter("auto")}).Format(&buf, podUsages[1:]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "COST/MO") {
+		t.Errorf("expected no cost columns without pricing, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&JSONFormatter{}).Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if c := result.Items[0].Cost; c == nil || c.Requests != 120.5 || c.Idle != 100.25 {
+		t.Errorf("unexpected cost: %+v", c)
+	}
+	if result.Items[1].Cost != nil {
+		t.Errorf("expected no cost for an unpriced pod, got %+v", result.Items[1].Cost)
+	}
+}
+
+func TestFormattersThrottling(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{Namespace: "default", Name: "api", Throttling: &calculator.Throttling{Periods: 1000, ThrottledPeriods: 425, ThrottledPercent: floatPtr(42.5), PressurePercent: floatPtr(12.5)}},
+		{Namespace: "default", Name: "batch", Throttling: &calculator.Throttling{}},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"table", []string{"CPU_LIM%   THROTTLED%", "42%"}},
+		{"wide", []string{"CPU_L%   THROTTLED% PRESSURE%", "42%        12%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewFormatter(tt.format, FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"})
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := NewFormatter("table", FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"}).Format(&buf, testPodUsages()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "THROTTLED%") {
+		t.Errorf("expected no throttling column without --throttling, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&JSONFormatter{}).Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result StructuredOutput
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if th := result.Items[0].Throttling; th == nil || th.ThrottledPeriods != 425 || *th.ThrottledPercent != 42.5 || *th.PressurePercent != 12.5 {
+		t.Errorf("unexpected throttling: %+v", th)
+	}
+	if th := result.Items[1].Throttling; th == nil || th.ThrottledPercent != nil {
+		t.Errorf("expected throttling without a percentage, got %+v", th)
+	}
+}
+
+func TestValueSource(t *testing.T) {
+	q := resource.MustParse("1")
+	tests := []struct {
+		name   string
+		source calculator.ValueSource
+		q      *resource.Quantity
+		want   calculator.ValueSource
+	}{
+		{"set source is kept", calculator.SourceLimitRange, &q, calculator.SourceLimitRange},
+		{"unset source with value", "", &q, calculator.SourceExplicit},
+		{"unset source without value", "", nil, calculator.SourceNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := valueSource(tt.source, tt.q); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestNewFormatter(t *testing.T) {
 	opts := FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"}
 
@@ -352,6 +726,12 @@ func TestNewFormatter(t *testing.T) {
 		t.Error("expected YAMLFormatter for 'yaml' format")
 	}
 
+	// Test NDJSON formatter
+	ndjsonFormatter := NewFormatter("ndjson", opts)
+	if _, ok := ndjsonFormatter.(*NDJSONFormatter); !ok {
+		t.Error("expected NDJSONFormatter for 'ndjson' format")
+	}
+
 	// Test Wide formatter
 	wideFormatter := NewFormatter("wide", opts)
 	if _, ok := wideFormatter.(*WideFormatter); !ok {
@@ -416,26 +796,28 @@ func TestIsValidUnit(t *testing.T) {
 }
 
 func TestColorizer(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("COLORTERM", "")
 	tests := []struct {
 		name     string
 		mode     ColorMode
-		percent  *int
+		percent  *float64
 		width    int
 		wantRed  bool
 		wantGreen bool
 		wantYellow bool
 	}{
-		{"high usage with color", ColorModeAlways, intPtr(85), 10, true, false, false},
-		{"medium usage with color", ColorModeAlways, intPtr(60), 10, false, false, true},
-		{"low usage with color", ColorModeAlways, intPtr(30), 10, false, true, false},
+		{"high usage with color", ColorModeAlways, floatPtr(85), 10, true, false, false},
+		{"medium usage with color", ColorModeAlways, floatPtr(60), 10, false, false, true},
+		{"low usage with color", ColorModeAlways, floatPtr(30), 10, false, true, false},
 		{"nil with color", ColorModeAlways, nil, 10, false, false, false},
-		{"high usage no color", ColorModeNever, intPtr(85), 10, false, false, false},
+		{"high usage no color", ColorModeNever, floatPtr(85), 10, false, false, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewColorizer(tt.mode)
-			result := c.FormatPercent(tt.percent, tt.width)
+			result := c.FormatPercent(Field{}, tt.percent, tt.width)
 
 			hasRed := strings.Contains(result, "\033[31m")
 			hasGreen := strings.Contains(result, "\033[32m")
@@ -457,10 +839,637 @@ func TestColorizer(t *testing.T) {
 	}
 }
 
-func intPtr(i int) *int {
-	return &i
+func TestColorizerThresholds(t *testing.T) {
+	c := NewColorizerWithScheme(ColorModeAlways, ColorScheme{Thresholds: Thresholds{Warning: 70, Critical: 90, Over: 100}, Depth: ColorDepth16})
+	tests := []struct {
+		percent float64
+		want    string
+	}{
+		{95, colorRed},
+		{85, colorYellow},
+		{60, colorGreen},
+	}
+
+	for _, tt := range tests {
+		if got := c.FormatPercent(Field{}, floatPtr(tt.percent), 5); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("%v%%: expected color %q, got %q", tt.percent, tt.want, got)
+		}
+	}
+
+	f := NewFormatter("markdown", FormatterOptions{Unit: "auto", Markers: MarkerStyleText, Colors: ColorScheme{Thresholds: Thresholds{Warning: 10, Critical: 15, Over: 100}}})
+	var buf bytes.Buffer
+	if err := f.Format(&buf, testPodUsages()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "20% HIGH") {
+		t.Errorf("expected markdown markers at the configured thresholds, got:\n%s", buf.String())
+	}
+}
+
+func TestColorSchemeSeverity(t *testing.T) {
+	memoryLimit := Field{Resource: corev1.ResourceMemory, Metric: MetricLimitPercent}
+	cpuRequest := Field{Resource: corev1.ResourceCPU, Metric: MetricRequestPercent}
+	scheme := ColorScheme{
+		Thresholds: DefaultThresholds,
+		Fields: map[Field]Thresholds{
+			memoryLimit: {Warning: 70, Critical: 90, Over: 100},
+			cpuRequest:  {Warning: 50, Critical: 80, Waste: 10, Over: 100},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		field Field
+		p     *float64
+		want  Severity
+	}{
+		{"unavailable", memoryLimit, nil, SeverityNA},
+		{"memory limit below override", memoryLimit, floatPtr(85), SeverityMedium},
+		{"memory limit critical", memoryLimit, floatPtr(90), SeverityHigh},
+		{"memory limit just below critical", memoryLimit, floatPtr(89.9), SeverityMedium},
+		{"memory limit over", memoryLimit, floatPtr(101), SeverityOver},
+		{"memory limit just over", memoryLimit, floatPtr(100.1), SeverityOver},
+		{"cpu request waste", cpuRequest, floatPtr(5), SeverityWaste},
+		{"cpu request just below waste", cpuRequest, floatPtr(9.9), SeverityWaste},
+		{"cpu request low", cpuRequest, floatPtr(10), SeverityLow},
+		{"default thresholds", Field{Resource: corev1.ResourceCPU, Metric: MetricLimitPercent}, floatPtr(85), SeverityHigh},
+		{"default has no waste band", Field{Resource: corev1.ResourceCPU, Metric: MetricLimitPercent}, floatPtr(0), SeverityLow},
+		{"default over", Field{Resource: corev1.ResourceCPU, Metric: MetricLimitPercent}, floatPtr(150), SeverityOver},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheme.Severity(tt.field, tt.p); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestColorizerPalettes(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme ColorScheme
+		p      float64
+		want   string
+	}{
+		{"16 colors", ColorScheme{Depth: ColorDepth16}, 30, colorGreen},
+		{"16 colors colorblind", ColorScheme{Palette: PaletteColorblind, Depth: ColorDepth16}, 30, colorBlue},
+		{"16 colors over", ColorScheme{Depth: ColorDepth16}, 120, colorMagenta},
+		{"256 colors", ColorScheme{Depth: ColorDepth256}, 85, "\033[38;5;160m"},
+		{"truecolor", ColorScheme{Depth: ColorDepthTrueColor}, 85, "\033[38;2;215;0;0m"},
+		{"truecolor colorblind", ColorScheme{Palette: PaletteColorblind, Depth: ColorDepthTrueColor}, 85, "\033[38;2;213;94;0m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewColorizerWithScheme(ColorModeAlways, tt.scheme)
+			if got := c.FormatPercent(Field{}, floatPtr(tt.p), 4); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("expected prefix %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestColorizerEnvironment(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	if c := NewColorizer(ColorModeAlways); c.scheme.Depth != ColorDepthTrueColor {
+		t.Errorf("expected truecolor from COLORTERM, got %q", c.scheme.Depth)
+	}
+	t.Setenv("COLORTERM", "")
+	t.Setenv("TERM", "xterm-256color")
+	if c := NewColorizer(ColorModeAlways); c.scheme.Depth != ColorDepth256 {
+		t.Errorf("expected 256 colors from TERM, got %q", c.scheme.Depth)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if NewColorizer(ColorModeAuto).Enabled() {
+		t.Error("expected NO_COLOR to disable colors in auto mode")
+	}
+	if !NewColorizer(ColorModeAlways).Enabled() {
+		t.Error("expected --color always to override NO_COLOR")
+	}
+}
+
+func testPodUsages() []calculator.PodUsage {
+	return []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "test-pod",
+			Node:      "node-1",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU: {
+					Usage:          resource.MustParse("100m"),
+					Requests:       resourcePtr(resource.MustParse("200m")),
+					Limits:         resourcePtr(resource.MustParse("500m")),
+					RequestPercent: floatPtr(50),
+					LimitPercent:   floatPtr(20),
+				},
+				corev1.ResourceMemory: {
+					Usage:          resource.MustParse("128Mi"),
+					Requests:       resourcePtr(resource.MustParse("256Mi")),
+					Limits:         nil,
+					RequestPercent: floatPtr(50),
+					LimitPercent:   nil,
+				},
+			},
+		},
+	}
+}
+
+func TestIsTemplateFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{"jsonpath={.items[*].pod}", true},
+		{"jsonpath-as-json={.items}", true},
+		{"go-template={{.}}", true},
+		{"custom-columns=NAME:.pod", true},
+		{"custom-columns-file=cols.txt", true},
+		{"json", false},
+		{"table", false},
+		{"jsonpath", false},
+		{"unknown=foo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := IsTemplateFormat(tt.format); got != tt.want {
+				t.Errorf("IsTemplateFormat(%q) = %v, want %v", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"jsonpath", "jsonpath={.items[*].pod}", "test-pod"},
+		{"jsonpath nested field", "jsonpath={.items[0].cpu.requestPercent}", "50"},
+		{"go-template", `go-template={{range .items}}{{.namespace}}/{{.pod}}{{end}}`, "default/test-pod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewTemplateFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := formatter.Format(&buf, testPodUsages()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTemplateFormatterInvalid(t *testing.T) {
+	invalid := []string{
+		"jsonpath=",
+		"jsonpath={.items[",
+		"go-template={{.items",
+		"custom-columns=NAME",
+		"custom-columns=:.pod",
+	}
+
+	for _, format := range invalid {
+		t.Run(format, func(t *testing.T) {
+			if _, err := NewTemplateFormatter(format); err == nil {
+				t.Errorf("expected error for %q", format)
+			}
+		})
+	}
+}
+
+func TestCustomColumnsFormatter(t *testing.T) {
+	formatter, err := NewTemplateFormatter("custom-columns=NAME:.pod,CPU_REQ:cpu.requests,MEM:{.memory.limitPercent}")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, testPodUsages()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "NAME CPU_REQ MEM" {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "test-pod 200m <none>" {
+		t.Errorf("unexpected row: %q", lines[1])
+	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
+	podUsages := append(testPodUsages(), calculator.PodUsage{
+		Namespace: "kube-system",
+		Name:      "coredns",
+		Node:      "node-2",
+		Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU:    {Usage: resource.MustParse("10m")},
+			corev1.ResourceMemory: {Usage: resource.MustParse("32Mi")},
+		},
+	})
+	sampleTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buf bytes.Buffer
+	formatter := &NDJSONFormatter{now: func() time.Time { return sampleTime }}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		var record NDJSONRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", i, err)
+		}
+		if !record.Timestamp.Equal(sampleTime) {
+			t.Errorf("line %d: expected timestamp %v, got %v", i, sampleTime, record.Timestamp)
+		}
+		if record.Pod != podUsages[i].Name {
+			t.Errorf("line %d: expected pod %q, got %q", i, podUsages[i].Name, record.Pod)
+		}
+	}
+
+	if !strings.Contains(lines[0], `"timestamp":"2024-01-02T03:04:05Z"`) {
+		t.Errorf("expected RFC3339 timestamp in %q", lines[0])
+	}
+	if !strings.Contains(lines[0], `"requestPercent":50`) {
+		t.Errorf("expected flattened pod fields in %q", lines[0])
+	}
+}
+
+func TestHTMLFormatter(t *testing.T) {
+	podUsages := append(testPodUsages(), calculator.PodUsage{
+		Namespace: "kube-system",
+		Name:      "<script>alert(1)</script>",
+		Node:      "node-2",
+		Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU: {Usage: resource.MustParse("10m")},
+			corev1.ResourceMemory: {
+				Usage:        resource.MustParse("96Mi"),
+				Limits:       resourcePtr(resource.MustParse("100Mi")),
+				LimitPercent: floatPtr(96),
+			},
+		},
+	})
+
+	var buf bytes.Buffer
+	formatter := &HTMLFormatter{
+		unitFormatter: NewUnitFormatter("auto"),
+		now:           func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+	}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+
+	expected := []string{
+		"<!DOCTYPE html>",
+		"Generated 2024-01-02T03:04:05Z",
+		"2 pods",
+		"<h2>Namespaces</h2>",
+		"<h2>Nodes</h2>",
+		"<svg",
+		`<td class="high" data-value="96">96%</td>`,
+		`<td class="medium" data-value="50">50%</td>`,
+		`<td class="na" data-value="-1">N/A</td>`,
+		"128Mi",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected report to contain %q", want)
+		}
+	}
+
+	if strings.Contains(output, "<script>alert(1)</script>") {
+		t.Error("expected pod names to be HTML-escaped")
+	}
+	for _, external := range []string{"<link", "src=", "http://", "https://"} {
+		if strings.Contains(output, external) {
+			t.Errorf("expected no external assets, found %q", external)
+		}
+	}
+}
+
+func TestPercentBar(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent *float64
+		want    int
+	}{
+		{"nil", nil, 0},
+		{"zero", floatPtr(0), 0},
+		{"half", floatPtr(50), svgBarWidth / 2},
+		{"fraction", floatPtr(0.5), 0},
+		{"full", floatPtr(100), svgBarWidth},
+		{"over 100% is capped", floatPtr(250), svgBarWidth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentBar(tt.percent); got != tt.want {
+				t.Errorf("percentBar() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatPercentText(t *testing.T) {
+	tests := []struct {
+		name      string
+		percent   *float64
+		precision int
+		want      string
+	}{
+		{"nil", nil, 1, "N/A"},
+		{"whole", floatPtr(50), 0, "50%"},
+		{"fraction truncated", floatPtr(0.4), 0, "0%"},
+		{"one decimal", floatPtr(0.4), 1, "0.4%"},
+		{"padded decimals", floatPtr(50), 2, "50.00%"},
+		{"cut not rounded", floatPtr(79.99), 1, "79.9%"},
+		{"exact decimal", floatPtr(0.29), 2, "0.29%"},
+		{"repeating decimal", floatPtr(100.0 / 3), 3, "33.333%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPercentText(tt.percent, tt.precision); got != tt.want {
+				t.Errorf("FormatPercentText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownFormatter(t *testing.T) {
+	podUsages := append(testPodUsages(), calculator.PodUsage{
+		Namespace: "payment",
+		Name:      "checkout|v2",
+		Node:      "node-2",
+		Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU:    {Usage: resource.MustParse("900m"), LimitPercent: floatPtr(90)},
+			corev1.ResourceMemory: {Usage: resource.MustParse("64Mi"), LimitPercent: floatPtr(10)},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		markers  MarkerStyle
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "emoji markers",
+			markers:  MarkerStyleEmoji,
+			contains: []string{"🔴 90%", "🟡 50%", "🟢 20%", "🟢 10%"},
+		},
+		{
+			name:     "text markers",
+			markers:  MarkerStyleText,
+			contains: []string{"**90% HIGH**", "50% WARN", "| 20% |"},
+			excludes: []string{"🔴", "🟡", "🟢"},
+		},
+		{
+			name:     "no markers",
+			markers:  MarkerStyleNone,
+			contains: []string{"| 90% |", "| 50% |"},
+			excludes: []string{"🔴", "HIGH", "WARN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := &MarkdownFormatter{
+				unitFormatter: NewUnitFormatter("auto"),
+				markers:       tt.markers,
+				summary:       ReportSummary{Cluster: "prod-eu", Context: "prod-eu-admin"},
+				now:           func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) },
+			}
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			output := buf.String()
+			common := []string{
+				"- **Cluster:** prod-eu",
+				"- **Context:** prod-eu-admin",
+				"- **Namespace:** all namespaces",
+				"- **Timestamp:** 2024-01-02T03:04:05Z",
+				"- **Pods:** 2",
+				"| NAMESPACE | POD |",
+				"|---|---|",
+				`checkout\|v2`,
+				"| N/A |",
+			}
+			for _, want := range append(common, tt.contains...) {
+				if !strings.Contains(output, want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("expected output not to contain %q", unwanted)
+				}
+			}
+			if strings.Contains(output, "\033[") {
+				t.Error("expected no ANSI escape sequences in markdown")
+			}
+		})
+	}
+}
+
+func TestFormatPercentTrend(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		precision int
+		p         *float64
+		prev      *float64
+		want      string
+	}{
+		{"up", false, 0, floatPtr(60), floatPtr(50), "60%↑   "},
+		{"down", false, 0, floatPtr(40), floatPtr(50), "40%↓   "},
+		{"unchanged", false, 0, floatPtr(50), floatPtr(50), "50%    "},
+		{"was N/A", false, 0, floatPtr(50), nil, "50%    "},
+		{"now N/A", false, 0, nil, floatPtr(50), "N/A    "},
+		{"changed highlighted", true, 0, floatPtr(85), floatPtr(70), "\033[1m\033[31m85%↑   \033[0m\033[0m"},
+		{"unchanged not highlighted", true, 0, floatPtr(30), floatPtr(30), "\033[32m30%    \033[0m"},
+		{"change below precision", false, 0, floatPtr(50.6), floatPtr(50.2), "50%    "},
+		{"change at precision", false, 1, floatPtr(50.6), floatPtr(50.2), "50.6%↑ "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Colorizer{enabled: tt.enabled, precision: tt.precision}
+			got := c.FormatPercentTrend(Field{}, tt.p, tt.prev, 7)
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTableFormatterTrends(t *testing.T) {
+	f := NewFormatter("table", FormatterOptions{ColorMode: ColorModeNever, Unit: "auto", Trends: true})
+
+	var first bytes.Buffer
+	if err := f.Format(&first, testPodUsages()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.ContainsAny(first.String(), trendUp+trendDown) {
+		t.Errorf("expected no trend arrows on the first sample, got:\n%s", first.String())
+	}
+
+	pods := testPodUsages()
+	cpu, memory := pods[0].Resources[corev1.ResourceCPU], pods[0].Resources[corev1.ResourceMemory]
+	cpu.LimitPercent = floatPtr(40)
+	memory.RequestPercent = floatPtr(30)
+	pods[0].Resources[corev1.ResourceCPU], pods[0].Resources[corev1.ResourceMemory] = cpu, memory
+	var second bytes.Buffer
+	if err := f.Format(&second, pods); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"40%↑", "30%↓"} {
+		if !strings.Contains(second.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, second.String())
+		}
+	}
+}
+
+func TestWatchRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewWatchRenderer(&buf)
+
+	if err := r.Render([]string{"header", "a", "b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "\033[H\033[2Jheader\na\nb\n"; buf.String() != want {
+		t.Errorf("first frame: expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := r.Render([]string{"header", "c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "\033[2;1Hc\033[K\033[3;1H\033[J"; buf.String() != want {
+		t.Errorf("second frame: expected %q, got %q", want, buf.String())
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
 }
 
 func resourcePtr(r resource.Quantity) *resource.Quantity {
 	return &r
 }
+
+func TestWideFormatterUnassumedLimitRangeDefault(t *testing.T) {
+	podUsages := []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "legacy-pod",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceMemory: {
+					Usage:           resource.MustParse("128Mi"),
+					DefaultRequests: resourcePtr(resource.MustParse("256Mi")),
+					RequestSource:   calculator.SourceLimitRange,
+					LimitSource:     calculator.SourceNone,
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	formatter := &WideFormatter{colorizer: NewColorizer(ColorModeNever), unitFormatter: NewUnitFormatter("auto")}
+	if err := formatter.Format(&buf, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "256Mi*") {
+		t.Errorf("expected the applying default to be shown, got:\n%s", buf.String())
+	}
+
+	var structured bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&structured, podUsages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(structured.String(), `"defaultRequests": "256Mi"`) || !strings.Contains(structured.String(), `"requests": null`) {
+		t.Errorf("expected default requests next to unset requests, got:\n%s", structured.String())
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Field
+		wantErr string
+	}{
+		{input: "memory.limitPercent", want: Field{Resource: corev1.ResourceMemory, Metric: MetricLimitPercent}},
+		{input: "cpu.requestPercent", want: Field{Resource: corev1.ResourceCPU, Metric: MetricRequestPercent}},
+		{input: "disk.limitPercent", wantErr: "must be <resource>.<metric>"},
+		{input: "memory", wantErr: "must be <resource>.<metric>"},
+		{input: "cpu.usage", wantErr: "metric must be requestPercent or limitPercent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseField(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || got.String() != tt.input {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormattersWithoutStorage(t *testing.T) {
+	podUsages := []calculator.PodUsage{{
+		Namespace: "default",
+		Name:      "api",
+		Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU:              {Usage: resource.MustParse("100m")},
+			corev1.ResourceMemory:           {Usage: resource.MustParse("128Mi")},
+			corev1.ResourceEphemeralStorage: {UsageUnavailable: true, Limits: resourcePtr(resource.MustParse("1Gi"))},
+		},
+	}}
+
+	for _, format := range []string{"table", "wide", "markdown", "html", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			formatter := NewFormatter(format, FormatterOptions{ColorMode: ColorModeNever, Unit: "auto"})
+			if err := formatter.Format(&buf, podUsages); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output := buf.String(); strings.Contains(output, "EPH") || strings.Contains(output, "ephemeralStorage") {
+				t.Errorf("expected no ephemeral storage without storage usage, got:\n%s", output)
+			}
+		})
+	}
+}
@@ -3,52 +3,104 @@ package output
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // TableFormatter formats output as a table
 type TableFormatter struct {
 	colorizer     *Colorizer
 	unitFormatter *UnitFormatter
+	trends        *TrendTracker
+	currency      string
 }
 
-// Format writes pod usages as a table
+// Format writes pod usages as a table, with usage, Request% and Limit%
+// columns for each registered resource, CPU throttling if it was collected
+// and monthly costs if they were estimated
 func (f *TableFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	resources := calculator.ShownResources(podUsages)
+	clustered := hasClusters(podUsages)
+	priced := hasCosts(podUsages)
+	throttled := hasThrottling(podUsages)
+
 	// Print header
-	if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s\n",
-		tableColNamespace, "NAMESPACE",
-		tableColPod, "POD",
-		tableColCPUUsage, "CPU_USAGE",
-		tableColPercent, "CPU_REQ%",
-		tableColPercent, "CPU_LIM%",
-		tableColMemUsage, "MEM_USAGE",
-		tableColPercent, "MEM_REQ%",
-		tableColPercent, "MEM_LIM%",
-		tableColNode, "NODE"); err != nil {
+	var header []string
+	if clustered {
+		header = append(header, fmt.Sprintf("%-*s", tableColCluster, "CLUSTER"))
+	}
+	header = append(header,
+		fmt.Sprintf("%-*s", tableColNamespace, "NAMESPACE"),
+		fmt.Sprintf("%-*s", tableColPod, "POD"))
+	for _, def := range resources {
+		header = append(header,
+			fmt.Sprintf("%-*s", tableColUsage, def.Column+"_USAGE"),
+			fmt.Sprintf("%-*s", tableColPercent, def.Column+"_REQ%"),
+			fmt.Sprintf("%-*s", tableColPercent, def.Column+"_LIM%"))
+		if throttled && def.Name == corev1.ResourceCPU {
+			header = append(header, fmt.Sprintf("%-*s", tableColPercent, "THROTTLED%"))
+		}
+	}
+	if priced {
+		for _, column := range costColumns {
+			header = append(header, fmt.Sprintf("%-*s", tableColCost, column))
+		}
+	}
+	header = append(header, fmt.Sprintf("%-*s", tableColNode, "NODE"))
+	if _, err := fmt.Fprintln(w, strings.Join(header, " ")); err != nil {
 		return err
 	}
 
 	// Print rows
 	for _, pu := range podUsages {
-		if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %s %s %-*s %s %s %-*s\n",
-			tableColNamespace, truncate(pu.Namespace, tableColNamespace),
-			tableColPod, truncate(pu.Name, tableColPod),
-			tableColCPUUsage, f.unitFormatter.FormatCPU(pu.CPU.Usage.MilliValue()),
-			f.colorizer.FormatPercent(pu.CPU.RequestPercent, tableColPercent),
-			f.colorizer.FormatPercent(pu.CPU.LimitPercent, tableColPercent),
-			tableColMemUsage, f.unitFormatter.FormatMemory(pu.Memory.Usage.Value()),
-			f.colorizer.FormatPercent(pu.Memory.RequestPercent, tableColPercent),
-			f.colorizer.FormatPercent(pu.Memory.LimitPercent, tableColPercent),
-			tableColNode, truncate(pu.Node, tableColNode),
-		); err != nil {
+		prev, hasPrev := f.trends.Previous(pu)
+		var row []string
+		if clustered {
+			row = append(row, fmt.Sprintf("%-*s", tableColCluster, truncate(pu.Cluster, tableColCluster)))
+		}
+		row = append(row,
+			fmt.Sprintf("%-*s", tableColNamespace, truncate(pu.Namespace, tableColNamespace)),
+			fmt.Sprintf("%-*s", tableColPod, truncate(pu.Name, tableColPod)))
+		for _, def := range resources {
+			ru, prevRU := pu.Resources[def.Name], prev.Resources[def.Name]
+			row = append(row,
+				valueCell(f.colorizer, f.unitFormatter.FormatUsage(def.Unit, ru),
+					f.unitFormatter.FormatUsage(def.Unit, prevRU), hasPrev, tableColUsage),
+				percentCell(f.colorizer, Field{def.Name, MetricRequestPercent}, ru.RequestPercent, prevRU.RequestPercent, hasPrev, tableColPercent),
+				percentCell(f.colorizer, Field{def.Name, MetricLimitPercent}, ru.LimitPercent, prevRU.LimitPercent, hasPrev, tableColPercent))
+			if throttled && def.Name == corev1.ResourceCPU {
+				row = append(row, percentCell(f.colorizer, throttledField,
+					throttlingOf(pu).ThrottledPercent, throttlingOf(prev).ThrottledPercent, hasPrev, tableColPercent))
+			}
+		}
+		if priced {
+			prevCosts := formatCosts(f.currency, prev.Cost)
+			for i, value := range formatCosts(f.currency, pu.Cost) {
+				row = append(row, valueCell(f.colorizer, value, prevCosts[i], hasPrev, tableColCost))
+			}
+		}
+		row = append(row, fmt.Sprintf("%-*s", tableColNode, truncate(pu.Node, tableColNode)))
+		if _, err := fmt.Fprintln(w, strings.Join(row, " ")); err != nil {
 			return err
 		}
 	}
 
+	f.trends.Commit(podUsages)
 	return nil
 }
 
+// hasClusters reports whether any pod usage was collected from a named cluster
+func hasClusters(podUsages []calculator.PodUsage) bool {
+	for _, pu := range podUsages {
+		if pu.Cluster != "" {
+			return true
+		}
+	}
+	return false
+}
+
 // truncate truncates string to max length (rune-safe for UTF-8)
 func truncate(s string, maxLen int) string {
 	runes := []rune(s)
@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// templateFormats lists the kubectl-compatible template output formats
+var templateFormats = []string{
+	"custom-columns",
+	"custom-columns-file",
+	"go-template",
+	"go-template-file",
+	"jsonpath",
+	"jsonpath-as-json",
+	"jsonpath-file",
+}
+
+// TemplateFormats returns the list of supported template output formats
+func TemplateFormats() []string {
+	return append([]string(nil), templateFormats...)
+}
+
+// IsTemplateFormat checks if the output format is a template format
+// such as jsonpath=..., go-template=... or custom-columns=...
+func IsTemplateFormat(format string) bool {
+	name, _, found := strings.Cut(format, "=")
+	if !found {
+		return false
+	}
+	for _, valid := range templateFormats {
+		if name == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTemplateFormatter creates a formatter for a template output format.
+// jsonpath and go-template are evaluated against StructuredOutput, the same
+// document printed by -o json; custom-columns are evaluated against each
+// StructuredPodUsage item.
+func NewTemplateFormatter(format string) (Formatter, error) {
+	name, template, _ := strings.Cut(format, "=")
+	if template == "" {
+		return nil, fmt.Errorf("template format %s specified but no template given", name)
+	}
+
+	switch name {
+	case "custom-columns", "custom-columns-file":
+		return NewCustomColumnsFormatter(name, template)
+	case "go-template", "go-template-file":
+		printer, err := genericclioptions.NewGoTemplatePrintFlags().ToPrinter(format)
+		if err != nil {
+			return nil, err
+		}
+		return &TemplateFormatter{printer: printer}, nil
+	case "jsonpath", "jsonpath-as-json", "jsonpath-file":
+		printer, err := genericclioptions.NewJSONPathPrintFlags("", true).ToPrinter(format)
+		if err != nil {
+			return nil, err
+		}
+		return &TemplateFormatter{printer: printer}, nil
+	default:
+		return nil, fmt.Errorf("unsupported template format: %s", name)
+	}
+}
+
+// TemplateFormatter formats output using the jsonpath and go-template printers from cli-runtime
+type TemplateFormatter struct {
+	printer printers.ResourcePrinter
+}
+
+// Format writes pod usages rendered through the template printer
+func (f *TemplateFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	return f.printer.PrintObj(&printableOutput{toStructuredOutput(podUsages)}, w)
+}
+
+// printableOutput adapts StructuredOutput to runtime.Object so it can be
+// handed to cli-runtime printers, which marshal it to JSON before evaluation
+type printableOutput struct {
+	StructuredOutput
+}
+
+// GetObjectKind implements runtime.Object
+func (p *printableOutput) GetObjectKind() schema.ObjectKind {
+	return schema.EmptyObjectKind
+}
+
+// DeepCopyObject implements runtime.Object
+func (p *printableOutput) DeepCopyObject() runtime.Object {
+	items := make([]StructuredPodUsage, len(p.Items))
+	copy(items, p.Items)
+	return &printableOutput{StructuredOutput{Items: items}}
+}
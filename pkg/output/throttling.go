@@ -0,0 +1,57 @@
+package output
+
+import (
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Percentage metrics of a pod's CPU throttling, colored like the CPU percentages
+const (
+	MetricThrottledPercent = "throttledPercent"
+	MetricPressurePercent  = "pressurePercent"
+)
+
+// Fields of the throttling columns
+var (
+	throttledField = Field{corev1.ResourceCPU, MetricThrottledPercent}
+	pressureField  = Field{corev1.ResourceCPU, MetricPressurePercent}
+)
+
+// StructuredThrottling represents a pod's CPU throttling in structured format
+type StructuredThrottling struct {
+	Periods          int64    `json:"periods" yaml:"periods"`
+	ThrottledPeriods int64    `json:"throttledPeriods" yaml:"throttledPeriods"`
+	ThrottledPercent *float64 `json:"throttledPercent" yaml:"throttledPercent"`
+	PressurePercent  *float64 `json:"pressurePercent" yaml:"pressurePercent"` // nil without PSI
+}
+
+// toStructuredThrottling converts throttling to StructuredThrottling, nil if it wasn't collected
+func toStructuredThrottling(t *calculator.Throttling) *StructuredThrottling {
+	if t == nil {
+		return nil
+	}
+	return &StructuredThrottling{
+		Periods:          t.Periods,
+		ThrottledPeriods: t.ThrottledPeriods,
+		ThrottledPercent: t.ThrottledPercent,
+		PressurePercent:  t.PressurePercent,
+	}
+}
+
+// hasThrottling reports whether any pod usage has throttling collected
+func hasThrottling(podUsages []calculator.PodUsage) bool {
+	for _, pu := range podUsages {
+		if pu.Throttling != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// throttlingOf returns a pod's throttling, empty if it wasn't collected
+func throttlingOf(pu calculator.PodUsage) calculator.Throttling {
+	if pu.Throttling == nil {
+		return calculator.Throttling{}
+	}
+	return *pu.Throttling
+}
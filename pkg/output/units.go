@@ -2,6 +2,9 @@ package output
 
 import (
 	"fmt"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Unit represents the unit for resource display
@@ -91,3 +94,32 @@ func (f *UnitFormatter) FormatMemory(bytes int64) string {
 		}
 	}
 }
+
+// FormatQuantity formats a quantity in its unit family; counts are printed as is
+func (f *UnitFormatter) FormatQuantity(unit calculator.UnitFamily, q resource.Quantity) string {
+	switch unit {
+	case calculator.UnitFamilyCPU:
+		return f.FormatCPU(q.MilliValue())
+	case calculator.UnitFamilyBytes:
+		return f.FormatMemory(q.Value())
+	default:
+		return q.String()
+	}
+}
+
+// FormatQuantityOrNA formats an optional quantity in its unit family, or returns "N/A"
+func (f *UnitFormatter) FormatQuantityOrNA(unit calculator.UnitFamily, q *resource.Quantity) string {
+	if q == nil {
+		return "N/A"
+	}
+	return f.FormatQuantity(unit, *q)
+}
+
+// FormatUsage formats a resource usage in its unit family, or returns "N/A"
+// if no usage was reported
+func (f *UnitFormatter) FormatUsage(unit calculator.UnitFamily, ru calculator.ResourceUsage) string {
+	if ru.UsageUnavailable {
+		return "N/A"
+	}
+	return f.FormatQuantity(unit, ru.Usage)
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+)
+
+// ANSI escape sequences used to redraw watch frames in place
+const (
+	cursorHome  = "\033[H"
+	clearScreen = "\033[2J"
+	clearLine   = "\033[K"
+	clearBelow  = "\033[J"
+)
+
+// TrendTracker remembers the previous watch sample so table formatters can
+// show trend arrows and highlight cells that changed since the last tick
+type TrendTracker struct {
+	previous map[string]calculator.PodUsage
+}
+
+// NewTrendTracker creates a TrendTracker with no previous sample
+func NewTrendTracker() *TrendTracker {
+	return &TrendTracker{previous: map[string]calculator.PodUsage{}}
+}
+
+// Previous returns the pod's usage from the last committed sample
+func (t *TrendTracker) Previous(pu calculator.PodUsage) (calculator.PodUsage, bool) {
+	if t == nil {
+		return calculator.PodUsage{}, false
+	}
+	prev, ok := t.previous[pu.Key()]
+	return prev, ok
+}
+
+// Commit stores pods as the sample the next tick is compared against
+func (t *TrendTracker) Commit(podUsages []calculator.PodUsage) {
+	if t == nil {
+		return
+	}
+	t.previous = make(map[string]calculator.PodUsage, len(podUsages))
+	for _, pu := range podUsages {
+		t.previous[pu.Key()] = pu
+	}
+}
+
+// percentCell formats a percentage column, with a trend arrow if a previous sample exists
+func percentCell(c *Colorizer, field Field, p, prev *float64, hasPrev bool, width int) string {
+	if !hasPrev {
+		return c.FormatPercent(field, p, width)
+	}
+	return c.FormatPercentTrend(field, p, prev, width)
+}
+
+// valueCell pads a value column, highlighting it if it changed since the previous sample
+func valueCell(c *Colorizer, value, prev string, hasPrev bool, width int) string {
+	return c.HighlightIf(hasPrev && value != prev, fmt.Sprintf("%-*s", width, value))
+}
+
+// WatchRenderer draws watch frames in place, rewriting only the lines that
+// changed since the previous frame to avoid flicker
+type WatchRenderer struct {
+	w    io.Writer
+	prev []string
+}
+
+// NewWatchRenderer creates a WatchRenderer writing to w
+func NewWatchRenderer(w io.Writer) *WatchRenderer {
+	return &WatchRenderer{w: w}
+}
+
+// Render draws lines as the next frame. The first frame clears the screen;
+// later frames move the cursor to each changed line and rewrite it.
+func (r *WatchRenderer) Render(lines []string) error {
+	var b strings.Builder
+	if r.prev == nil {
+		b.WriteString(cursorHome + clearScreen)
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	} else {
+		for i, line := range lines {
+			if i < len(r.prev) && r.prev[i] == line {
+				continue
+			}
+			fmt.Fprintf(&b, "\033[%d;1H%s%s", i+1, line, clearLine)
+		}
+		fmt.Fprintf(&b, "\033[%d;1H", len(lines)+1)
+		if len(lines) < len(r.prev) {
+			b.WriteString(clearBelow)
+		}
+	}
+
+	r.prev = append([]string{}, lines...)
+	_, err := io.WriteString(r.w, b.String())
+	return err
+}
@@ -3,73 +3,190 @@ package output
 import (
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 // WideFormatter formats output as a wide table with requests/limits raw values
 type WideFormatter struct {
 	colorizer     *Colorizer
 	unitFormatter *UnitFormatter
+	trends        *TrendTracker
+	currency      string
 }
 
-// Format writes pod usages as a wide table
+// Format writes pod usages as a wide table, with usage, requests, limits
+// and percentages for each registered resource, CPU throttling and pressure
+// if they were collected and monthly costs if they were estimated
 func (f *WideFormatter) Format(w io.Writer, podUsages []calculator.PodUsage) error {
+	resources := calculator.ShownResources(podUsages)
+	clustered := hasClusters(podUsages)
+	priced := hasCosts(podUsages)
+	throttled := hasThrottling(podUsages)
+
 	// Print header
-	if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s %-*s\n",
-		wideColNamespace, "NAMESPACE",
-		wideColPod, "POD",
-		wideColUsage, "CPU_USAGE",
-		wideColReqLim, "CPU_REQ",
-		wideColReqLim, "CPU_LIM",
-		wideColPercent, "CPU_R%",
-		wideColPercent, "CPU_L%",
-		wideColUsage, "MEM_USAGE",
-		wideColReqLim, "MEM_REQ",
-		wideColReqLim, "MEM_LIM",
-		wideColPercent, "MEM_R%",
-		wideColPercent, "MEM_L%",
-		wideColNode, "NODE"); err != nil {
+	var header []string
+	if clustered {
+		header = append(header, fmt.Sprintf("%-*s", wideColCluster, "CLUSTER"))
+	}
+	header = append(header,
+		fmt.Sprintf("%-*s", wideColNamespace, "NAMESPACE"),
+		fmt.Sprintf("%-*s", wideColPod, "POD"))
+	for _, def := range resources {
+		header = append(header,
+			fmt.Sprintf("%-*s", wideColUsage, def.Column+"_USAGE"),
+			fmt.Sprintf("%-*s", wideColReqLim, def.Column+"_REQ"),
+			fmt.Sprintf("%-*s", wideColReqLim, def.Column+"_LIM"),
+			fmt.Sprintf("%-*s", wideColPercent, def.Column+"_R%"),
+			fmt.Sprintf("%-*s", wideColPercent, def.Column+"_L%"))
+		if throttled && def.Name == corev1.ResourceCPU {
+			header = append(header,
+				fmt.Sprintf("%-*s", wideColThrottling, "THROTTLED%"),
+				fmt.Sprintf("%-*s", wideColThrottling, "PRESSURE%"))
+		}
+	}
+	if priced {
+		for _, column := range costColumns {
+			header = append(header, fmt.Sprintf("%-*s", wideColCost, column))
+		}
+	}
+	header = append(header,
+		fmt.Sprintf("%-*s", wideColNode, "NODE"),
+		fmt.Sprintf("%-*s", wideColQOS, "QOS"),
+		fmt.Sprintf("%-*s", wideColPhase, "PHASE"),
+		fmt.Sprintf("%-*s", wideColRestarts, "RESTARTS"),
+		fmt.Sprintf("%-*s", wideColTermination, "LAST_TERMINATION"),
+		"EXTENDED")
+	if _, err := fmt.Fprintln(w, strings.Join(header, " ")); err != nil {
 		return err
 	}
 
 	// Print rows
+	now := time.Now()
 	for _, pu := range podUsages {
-		if _, err := fmt.Fprintf(w, "%-*s %-*s %-*s %-*s %-*s %s %s %-*s %-*s %-*s %s %s %-*s\n",
-			wideColNamespace, truncate(pu.Namespace, wideColNamespace),
-			wideColPod, truncate(pu.Name, wideColPod),
-			wideColUsage, f.unitFormatter.FormatCPU(pu.CPU.Usage.MilliValue()),
-			wideColReqLim, f.formatCPUQuantityOrNA(pu.CPU.Requests),
-			wideColReqLim, f.formatCPUQuantityOrNA(pu.CPU.Limits),
-			f.colorizer.FormatPercent(pu.CPU.RequestPercent, wideColPercent),
-			f.colorizer.FormatPercent(pu.CPU.LimitPercent, wideColPercent),
-			wideColUsage, f.unitFormatter.FormatMemory(pu.Memory.Usage.Value()),
-			wideColReqLim, f.formatMemoryQuantityOrNA(pu.Memory.Requests),
-			wideColReqLim, f.formatMemoryQuantityOrNA(pu.Memory.Limits),
-			f.colorizer.FormatPercent(pu.Memory.RequestPercent, wideColPercent),
-			f.colorizer.FormatPercent(pu.Memory.LimitPercent, wideColPercent),
-			wideColNode, truncate(pu.Node, wideColNode),
-		); err != nil {
+		prev, hasPrev := f.trends.Previous(pu)
+		var row []string
+		if clustered {
+			row = append(row, fmt.Sprintf("%-*s", wideColCluster, truncate(pu.Cluster, wideColCluster)))
+		}
+		row = append(row,
+			fmt.Sprintf("%-*s", wideColNamespace, truncate(pu.Namespace, wideColNamespace)),
+			fmt.Sprintf("%-*s", wideColPod, truncate(pu.Name, wideColPod)))
+		for _, def := range resources {
+			ru, prevRU := pu.Resources[def.Name], prev.Resources[def.Name]
+			row = append(row,
+				valueCell(f.colorizer, f.unitFormatter.FormatUsage(def.Unit, ru),
+					f.unitFormatter.FormatUsage(def.Unit, prevRU), hasPrev, wideColUsage),
+				valueCell(f.colorizer, f.formatSourced(def.Unit, ru.Requests, ru.DefaultRequests, ru.RequestSource),
+					f.formatSourced(def.Unit, prevRU.Requests, prevRU.DefaultRequests, prevRU.RequestSource), hasPrev, wideColReqLim),
+				valueCell(f.colorizer, f.formatSourced(def.Unit, ru.Limits, ru.DefaultLimits, ru.LimitSource),
+					f.formatSourced(def.Unit, prevRU.Limits, prevRU.DefaultLimits, prevRU.LimitSource), hasPrev, wideColReqLim),
+				percentCell(f.colorizer, Field{def.Name, MetricRequestPercent}, ru.RequestPercent, prevRU.RequestPercent, hasPrev, wideColPercent),
+				percentCell(f.colorizer, Field{def.Name, MetricLimitPercent}, ru.LimitPercent, prevRU.LimitPercent, hasPrev, wideColPercent))
+			if throttled && def.Name == corev1.ResourceCPU {
+				t, prevT := throttlingOf(pu), throttlingOf(prev)
+				row = append(row,
+					percentCell(f.colorizer, throttledField, t.ThrottledPercent, prevT.ThrottledPercent, hasPrev, wideColThrottling),
+					percentCell(f.colorizer, pressureField, t.PressurePercent, prevT.PressurePercent, hasPrev, wideColThrottling))
+			}
+		}
+		if priced {
+			prevCosts := formatCosts(f.currency, prev.Cost)
+			for i, value := range formatCosts(f.currency, pu.Cost) {
+				row = append(row, valueCell(f.colorizer, value, prevCosts[i], hasPrev, wideColCost))
+			}
+		}
+		row = append(row,
+			fmt.Sprintf("%-*s", wideColNode, truncate(pu.Node, wideColNode)),
+			fmt.Sprintf("%-*s", wideColQOS, valueOrDash(string(pu.Status.QOSClass))),
+			fmt.Sprintf("%-*s", wideColPhase, valueOrDash(string(pu.Status.Phase))),
+			fmt.Sprintf("%-*d", wideColRestarts, pu.Status.Restarts),
+			fmt.Sprintf("%-*s", wideColTermination, formatTermination(pu.Status.LastTermination, now)),
+			formatExtended(pu.Resources))
+		if _, err := fmt.Fprintln(w, strings.Join(row, " ")); err != nil {
 			return err
 		}
 	}
 
+	f.trends.Commit(podUsages)
+
+	if hasAssumedValues(podUsages) {
+		if _, err := fmt.Fprintf(w, "\n%s includes namespace LimitRange defaults (used for percentages with --assume-limitrange-defaults)\n", assumedMarker); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// formatCPUQuantityOrNA formats a CPU quantity or returns "N/A"
-func (f *WideFormatter) formatCPUQuantityOrNA(q *resource.Quantity) string {
-	if q == nil {
-		return "N/A"
+// formatSourced formats a request or limit. When a LimitRange default
+// applies, the value with the default filled in is shown, marked with assumedMarker.
+func (f *WideFormatter) formatSourced(unit calculator.UnitFamily, value, def *resource.Quantity, source calculator.ValueSource) string {
+	if source != calculator.SourceLimitRange {
+		return f.unitFormatter.FormatQuantityOrNA(unit, value)
+	}
+	if def != nil {
+		value = def
+	}
+	return f.unitFormatter.FormatQuantityOrNA(unit, value) + assumedMarker
+}
+
+// hasAssumedValues reports whether a LimitRange default applies to any request or limit
+func hasAssumedValues(podUsages []calculator.PodUsage) bool {
+	for _, pu := range podUsages {
+		for _, ru := range pu.Resources {
+			if ru.RequestSource == calculator.SourceLimitRange || ru.LimitSource == calculator.SourceLimitRange {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatTermination formats a termination as its reason and age, e.g. "OOMKilled (5m ago)"
+func formatTermination(t *calculator.Termination, now time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	reason := valueOrDash(t.Reason)
+	if t.FinishedAt.IsZero() {
+		return reason
+	}
+	return fmt.Sprintf("%s (%s ago)", reason, duration.HumanDuration(now.Sub(t.FinishedAt)))
+}
+
+// formatExtended lists the requested amount of each resource without its own
+// columns, e.g. "hugepages-2Mi=1Gi,nvidia.com/gpu=2", falling back to the limit
+func formatExtended(resources calculator.ResourceUsages) string {
+	parts := make([]string, 0, len(resources))
+	for _, name := range calculator.SortedResourceNames(resources) {
+		if calculator.IsRegistered(name) {
+			continue
+		}
+		ru := resources[name]
+		q := ru.Requests
+		if q == nil {
+			q = ru.Limits
+		}
+		if q == nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	if len(parts) == 0 {
+		return "-"
 	}
-	return f.unitFormatter.FormatCPU(q.MilliValue())
+	return strings.Join(parts, ",")
 }
 
-// formatMemoryQuantityOrNA formats a memory quantity or returns "N/A"
-func (f *WideFormatter) formatMemoryQuantityOrNA(q *resource.Quantity) string {
-	if q == nil {
-		return "N/A"
+// valueOrDash returns s, or "-" if it is empty
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
 	}
-	return f.unitFormatter.FormatMemory(q.Value())
+	return s
 }
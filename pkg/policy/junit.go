@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the test cases of one rule
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is one container checked against one rule
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure describes a violation
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the result as JUnit XML with one test suite per rule
+// and one test case per container, so CI systems list every violation
+func WriteJUnit(w io.Writer, result Result) error {
+	violations := make(map[string]Violation, len(result.Violations))
+	for _, v := range result.Violations {
+		violations[v.Rule+"/"+targetName(v.Target)] = v
+	}
+
+	report := junitTestSuites{Name: "kubectl-resource-usage check"}
+	for _, rule := range result.Rules {
+		suite := junitTestSuite{Name: rule}
+		for _, target := range result.Targets {
+			tc := junitTestCase{Name: targetName(target), Classname: rule}
+			if v, ok := violations[rule+"/"+targetName(target)]; ok {
+				tc.Failure = &junitFailure{Message: v.Message, Type: "PolicyViolation", Text: v.Message + " (node " + v.Node + ")"}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Tests = len(suite.Cases)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// targetName identifies a container as namespace/pod/container
+func targetName(t Target) string {
+	return t.Namespace + "/" + t.Pod + "/" + t.Container
+}
@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/alert"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rule describes what counts as a violation for a single container. It is
+// either a threshold such as "memory.limitPercent > 90" or a presence check
+// such as "memory.limits missing".
+type Rule struct {
+	threshold *alert.Rule
+	resource  string // presence rules: a registered resource, e.g. "cpu"
+	missing   string // presence rules: "requests" or "limits"
+}
+
+// ParseRule parses a rule of the form "<resource>.<metric> <op> <threshold>"
+// or "<resource>.<requests|limits> missing"
+func ParseRule(s string) (Rule, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 2 && fields[1] == "missing" {
+		dot := strings.LastIndex(fields[0], ".")
+		_, ok := calculator.LookupResource(fields[0][:max(dot, 0)])
+		resource, field := fields[0][:max(dot, 0)], fields[0][dot+1:]
+		if dot < 0 || !ok || (field != "requests" && field != "limits") {
+			return Rule{}, fmt.Errorf("invalid rule field: %s (must be <resource>.requests or <resource>.limits with resource one of: %s)",
+				fields[0], strings.Join(calculator.ResourceNames(), ", "))
+		}
+		return Rule{resource: resource, missing: field}, nil
+	}
+
+	threshold, err := alert.ParseRule(s)
+	if err != nil {
+		return Rule{}, err
+	}
+	if threshold.For > 0 {
+		return Rule{}, fmt.Errorf("invalid rule: %q ('for <duration>' is only supported by watch alerts)", s)
+	}
+	return Rule{threshold: &threshold}, nil
+}
+
+// String returns the rule in the syntax accepted by ParseRule
+func (r Rule) String() string {
+	if r.threshold != nil {
+		return r.threshold.String()
+	}
+	return r.resource + "." + r.missing + " missing"
+}
+
+// violation checks a container against the rule and describes the violation,
+// if any. Presence rules look at the container's spec; threshold rules at its
+// usage, and pass when the container has no metrics yet.
+func (r Rule) violation(spec corev1.Container, usage *calculator.ContainerUsage) (*float64, string, bool) {
+	if r.threshold != nil {
+		if usage == nil {
+			return nil, "", false
+		}
+		sample := calculator.PodUsage{Resources: usage.Resources}
+		if !r.threshold.Matches(sample) {
+			return nil, "", false
+		}
+		value := r.threshold.Value(sample)
+		return value, fmt.Sprintf("%s.%s is %s", r.threshold.Resource, r.threshold.Metric, alert.FormatPercent(*value)), true
+	}
+
+	values := spec.Resources.Requests
+	if r.missing == "limits" {
+		values = spec.Resources.Limits
+	}
+	if _, set := values[corev1.ResourceName(r.resource)]; set {
+		return nil, "", false
+	}
+	return nil, fmt.Sprintf("%s %s not set", r.resource, r.missing), true
+}
+
+// NeedsUsage reports whether a threshold rule compares the usage of the resource
+func NeedsUsage(rules []Rule, name corev1.ResourceName) bool {
+	for _, r := range rules {
+		if r.threshold != nil && r.threshold.Resource == string(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Target identifies a checked container
+type Target struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Node      string `json:"node"`
+}
+
+// Violation is a container that broke a rule
+type Violation struct {
+	Target
+	Rule    string   `json:"rule"`
+	Value   *float64 `json:"value"`
+	Message string   `json:"message"`
+}
+
+// Result is the outcome of checking pods against rules
+type Result struct {
+	Rules      []string    `json:"rules"`
+	Targets    []Target    `json:"-"`
+	Checked    int         `json:"checked"`
+	Violations []Violation `json:"violations"`
+}
+
+// Passed reports whether no rule was violated
+func (r Result) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+// Check evaluates every rule against every container, init containers
+// included, of the given pods. Containers are taken from the pod specs, so
+// pods without metrics yet, such as pending or just rolled out ones, are
+// still checked by presence rules; threshold rules use the pods' usages.
+func Check(pods []corev1.Pod, podUsages []calculator.PodUsage, rules []Rule) Result {
+	result := Result{Violations: []Violation{}}
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, rule.String())
+	}
+
+	usages := make(map[string]*calculator.ContainerUsage)
+	for i := range podUsages {
+		pu := &podUsages[i]
+		for j := range pu.Containers {
+			usages[pu.Namespace+"/"+pu.Name+"/"+pu.Containers[j].Name] = &pu.Containers[j]
+		}
+	}
+
+	for _, pod := range pods {
+		containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			target := Target{Namespace: pod.Namespace, Pod: pod.Name, Container: c.Name, Node: pod.Spec.NodeName}
+			result.Targets = append(result.Targets, target)
+
+			usage := usages[pod.Namespace+"/"+pod.Name+"/"+c.Name]
+			for _, rule := range rules {
+				if value, msg, violated := rule.violation(c, usage); violated {
+					result.Violations = append(result.Violations, Violation{
+						Target:  target,
+						Rule:    rule.String(),
+						Value:   value,
+						Message: msg,
+					})
+				}
+			}
+		}
+	}
+
+	result.Checked = len(result.Targets)
+	return result
+}
@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "memory.limitPercent > 90", want: "memory.limitPercent > 90"},
+		{input: "cpu.requestPercent <  5", want: "cpu.requestPercent < 5"},
+		{input: "memory.limits missing", want: "memory.limits missing"},
+		{input: "cpu.requests missing", want: "cpu.requests missing"},
+		{input: "ephemeral-storage.limits missing", want: "ephemeral-storage.limits missing"},
+		{input: "disk.limits missing", wantErr: "invalid rule field"},
+		{input: "memory.usage missing", wantErr: "invalid rule field"},
+		{input: "memory.limitPercent > 90 for 2m", wantErr: "only supported by watch alerts"},
+		{input: "memory.limitPercent ~ 90", wantErr: "invalid rule operator"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			rule, err := ParseRule(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rule.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, rule.String())
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules := mustParseRules(t, "memory.limitPercent > 90", "memory.limits missing", "cpu.requestPercent < 5")
+
+	result := Check(testSpecs(), testPods(), rules)
+
+	if result.Checked != 3 {
+		t.Errorf("expected 3 checked containers, got %d", result.Checked)
+	}
+	if result.Passed() {
+		t.Fatal("expected violations")
+	}
+
+	got := map[string]bool{}
+	for _, v := range result.Violations {
+		got[v.Rule+" "+targetName(v.Target)] = true
+	}
+	want := []string{
+		"memory.limitPercent > 90 default/api/app",
+		"memory.limits missing default/api/sidecar",
+		"cpu.requestPercent < 5 default/api/sidecar",
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d violations, got %v", len(want), result.Violations)
+	}
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("expected violation %q, got %v", w, got)
+		}
+	}
+}
+
+func TestCheckPassed(t *testing.T) {
+	result := Check(testSpecs(), testPods(), mustParseRules(t, "memory.limitPercent > 99"))
+	if !result.Passed() {
+		t.Errorf("expected no violations, got %v", result.Violations)
+	}
+}
+
+func TestCheckPodsWithoutMetrics(t *testing.T) {
+	// A pod metrics-server has not scraped yet, with an init container
+	pending := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api-new"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{testContainer("migrate", "")},
+			Containers:     []corev1.Container{testContainer("app", "")},
+		},
+	}
+	specs := append(testSpecs(), pending)
+
+	result := Check(specs, testPods(), mustParseRules(t, "memory.limits missing", "memory.limitPercent > 90"))
+
+	if result.Checked != 5 {
+		t.Errorf("expected 5 checked containers, got %d", result.Checked)
+	}
+	got := map[string]bool{}
+	for _, v := range result.Violations {
+		got[v.Rule+" "+targetName(v.Target)] = true
+	}
+	for _, w := range []string{"memory.limits missing default/api-new/migrate", "memory.limits missing default/api-new/app"} {
+		if !got[w] {
+			t.Errorf("expected violation %q, got %v", w, got)
+		}
+	}
+	if len(result.Violations) != 4 {
+		t.Errorf("expected 4 violations, got %v", result.Violations)
+	}
+}
+
+func TestNeedsUsage(t *testing.T) {
+	rules := mustParseRules(t, "memory.limitPercent > 90", "ephemeral-storage.limits missing")
+	if !NeedsUsage(rules, corev1.ResourceMemory) {
+		t.Error("expected the memory threshold to need memory usage")
+	}
+	if NeedsUsage(rules, corev1.ResourceEphemeralStorage) {
+		t.Error("expected a presence rule not to need storage usage")
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	result := Check(testSpecs(), testPods(), mustParseRules(t, "memory.limitPercent > 90", "memory.limits missing"))
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if report.Tests != 6 || report.Failures != 2 {
+		t.Errorf("expected 6 tests and 2 failures, got %d and %d", report.Tests, report.Failures)
+	}
+	if len(report.Suites) != 2 || report.Suites[0].Name != "memory.limitPercent > 90" {
+		t.Errorf("expected one suite per rule, got %+v", report.Suites)
+	}
+	if !strings.Contains(buf.String(), `message="memory.limitPercent is 95%"`) {
+		t.Errorf("expected failure message in report:\n%s", buf.String())
+	}
+}
+
+func mustParseRules(t *testing.T, exprs ...string) []Rule {
+	t.Helper()
+	rules := make([]Rule, 0, len(exprs))
+	for _, e := range exprs {
+		rule, err := ParseRule(e)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", e, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func testPods() []calculator.PodUsage {
+	return []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "api",
+			Node:      "node-1",
+			Containers: []calculator.ContainerUsage{
+				{
+					Name: "app",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU:    {Requests: resourcePtr("100m"), RequestPercent: floatPtr(50)},
+						corev1.ResourceMemory: {Limits: resourcePtr("256Mi"), LimitPercent: floatPtr(95)},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU:    {Requests: resourcePtr("100m"), RequestPercent: floatPtr(2)},
+						corev1.ResourceMemory: {},
+					},
+				},
+			},
+		},
+		{
+			Namespace: "default",
+			Name:      "worker",
+			Node:      "node-2",
+			Containers: []calculator.ContainerUsage{
+				{
+					Name: "worker",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU:    {Requests: resourcePtr("100m"), RequestPercent: floatPtr(40)},
+						corev1.ResourceMemory: {Limits: resourcePtr("1Gi"), LimitPercent: floatPtr(60)},
+					},
+				},
+			},
+		},
+	}
+}
+
+// testSpecs returns the pods of testPods as listed by the API
+func testSpecs() []corev1.Pod {
+	return []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{
+				testContainer("app", "256Mi"),
+				testContainer("sidecar", ""),
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "worker"},
+			Spec:       corev1.PodSpec{NodeName: "node-2", Containers: []corev1.Container{testContainer("worker", "1Gi")}},
+		},
+	}
+}
+
+// testContainer returns a container requesting 100m cpu, with a memory limit unless it is empty
+func testContainer(name, memoryLimit string) corev1.Container {
+	c := corev1.Container{Name: name}
+	c.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+	if memoryLimit != "" {
+		c.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memoryLimit)}
+	}
+	return c
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func resourcePtr(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
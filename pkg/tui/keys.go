@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"bytes"
+	"time"
+	"unicode/utf8"
+)
+
+// KeyType identifies a key press read from the terminal
+type KeyType int
+
+const (
+	KeyRune KeyType = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyCtrlC
+	KeyHome
+	KeyEnd
+)
+
+// escapeTimeout is how long readKeys waits for the rest of an escape sequence
+// split across reads before taking the bytes received so far as typed keys
+const escapeTimeout = 50 * time.Millisecond
+
+// Key is a single key press; Rune is set for KeyRune
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// ParseKeys decodes raw terminal input into key presses. Invalid UTF-8
+// decodes to utf8.RuneError one byte at a time, and escape sequences of
+// keys without a KeyType, such as function keys, are dropped.
+func ParseKeys(b []byte) []Key {
+	keys, _ := parseKeys(b, true)
+	return keys
+}
+
+// parseKeys decodes b into key presses. Unless final, an escape sequence or
+// UTF-8 character cut off by the end of b is returned as rest, to be decoded
+// once the next read completes it; a lone ESC is the Escape key only when
+// final, since it also starts the sequences of arrows and other keys.
+func parseKeys(b []byte, final bool) (keys []Key, rest []byte) {
+	for len(b) > 0 {
+		key, size := Key{Type: KeyRune}, 1
+		switch {
+		case b[0] == 0x1b:
+			var ok bool
+			key, size, ok = parseEscape(b)
+			if size == 0 {
+				if !final {
+					return keys, b
+				}
+				key, size, ok = Key{Type: KeyEscape}, 1, true
+			}
+			if !ok {
+				b = b[size:]
+				continue
+			}
+		case b[0] == '\r' || b[0] == '\n':
+			key.Type = KeyEnter
+		case b[0] == 0x7f || b[0] == 0x08:
+			key.Type = KeyBackspace
+		case b[0] == 0x03:
+			key.Type = KeyCtrlC
+		default:
+			if !final && !utf8.FullRune(b) {
+				return keys, b
+			}
+			key.Rune, size = utf8.DecodeRune(b)
+		}
+		keys = append(keys, key)
+		b = b[size:]
+	}
+	return keys, nil
+}
+
+// parseEscape decodes the escape sequence b starts with. size is 0 if the
+// sequence is incomplete, and ok is false for sequences of unsupported keys.
+// ESC followed by anything else is the Escape key on its own.
+func parseEscape(b []byte) (key Key, size int, ok bool) {
+	if len(b) < 2 {
+		return Key{}, 0, false
+	}
+	switch b[1] {
+	case '[':
+		// CSI: parameter and intermediate bytes up to a final byte, as in
+		// ESC [ A, ESC [ 1 ; 5 A (Ctrl+Up) or ESC [ 4 ~ (End)
+		for i := 2; i < len(b); i++ {
+			switch c := b[i]; {
+			case c >= 0x40 && c <= 0x7e:
+				key, ok := csiKey(b[2:i], c)
+				return key, i + 1, ok
+			case c < 0x20 || c > 0x3f:
+				return Key{Type: KeyEscape}, 1, true
+			}
+		}
+		return Key{}, 0, false
+	case 'O':
+		// SS3: a single final byte, as in ESC O A sent in application cursor mode
+		if len(b) < 3 {
+			return Key{}, 0, false
+		}
+		key, ok := csiKey(nil, b[2])
+		return key, 3, ok
+	}
+	return Key{Type: KeyEscape}, 1, true
+}
+
+// csiKey maps the parameters and final byte of a CSI or SS3 sequence to a
+// key. Modifiers, the parameters after the first, are ignored.
+func csiKey(params []byte, final byte) (Key, bool) {
+	switch final {
+	case 'A':
+		return Key{Type: KeyUp}, true
+	case 'B':
+		return Key{Type: KeyDown}, true
+	case 'C':
+		return Key{Type: KeyRight}, true
+	case 'D':
+		return Key{Type: KeyLeft}, true
+	case 'H':
+		return Key{Type: KeyHome}, true
+	case 'F':
+		return Key{Type: KeyEnd}, true
+	case '~':
+		code, _, _ := bytes.Cut(params, []byte{';'})
+		switch string(code) {
+		case "1", "7":
+			return Key{Type: KeyHome}, true
+		case "4", "8":
+			return Key{Type: KeyEnd}, true
+		}
+	}
+	return Key{}, false
+}
@@ -0,0 +1,620 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// View selects what the rows of the table represent
+type View int
+
+const (
+	ViewPods View = iota
+	ViewContainers
+	ViewWorkloads
+	ViewNodes
+)
+
+var viewNames = [...]string{"pods", "containers", "workloads", "nodes"}
+
+// metricUsage is the usage column of a resource, next to its
+// output.MetricRequestPercent and output.MetricLimitPercent columns
+const metricUsage = "usage"
+
+// metrics are the columns shown for each resource, in display order
+var metrics = []string{metricUsage, output.MetricRequestPercent, output.MetricLimitPercent}
+
+// metricSuffixes complete the column prefix of a resource into a header, e.g. MEM_LIM%
+var metricSuffixes = map[string]string{
+	metricUsage:                 "_USAGE",
+	output.MetricRequestPercent: "_REQ%",
+	output.MetricLimitPercent:   "_LIM%",
+}
+
+// SortColumn selects the column rows are sorted by: the name if Resource is
+// empty, otherwise the usage, Request% or Limit% of the resource
+type SortColumn struct {
+	Resource corev1.ResourceName
+	Metric   string // metricUsage, output.MetricRequestPercent or output.MetricLimitPercent
+}
+
+// String returns the header of the column
+func (c SortColumn) String() string {
+	if c.Resource == "" {
+		return "NAME"
+	}
+	return calculator.DefinitionFor(c.Resource).Column + metricSuffixes[c.Metric]
+}
+
+// historySize is the number of samples kept per container for sparklines
+const historySize = 60
+
+// Column widths of the metric columns shared by all views
+const (
+	colUsage   = 10
+	colPercent = 9
+)
+
+// labelColumn is a leading text column of a view
+type labelColumn struct {
+	header string
+	width  int
+}
+
+// clusterColumn leads every view when the pods come from several clusters
+var clusterColumn = labelColumn{"CLUSTER", 14}
+
+// viewColumns are the label columns of each view
+var viewColumns = map[View][]labelColumn{
+	ViewPods:       {{"NAMESPACE", 14}, {"POD", 36}, {"NODE", 14}},
+	ViewContainers: {{"NAMESPACE", 14}, {"POD", 30}, {"CONTAINER", 18}},
+	ViewWorkloads:  {{"NAMESPACE", 14}, {"WORKLOAD", 36}, {"PODS", 5}},
+	ViewNodes:      {{"NODE", 30}, {"PODS", 5}},
+}
+
+// row is a single line of the table in any view
+type row struct {
+	podKey    string
+	labels    []string
+	resources calculator.ResourceUsages
+}
+
+// containerHistory holds recent usage samples of one container per resource
+type containerHistory map[corev1.ResourceName][]float64
+
+// Model holds the state of the interactive view. It has no terminal
+// dependencies so navigation and rendering can be tested directly.
+type Model struct {
+	units     *output.UnitFormatter
+	colorizer *output.Colorizer
+
+	view       View
+	sortColumn SortColumn
+	ascending  bool
+	cursor     int
+	filter     string
+	filtering  bool
+	paused     bool
+	detail     string
+
+	pods       []calculator.PodUsage
+	history    map[string]map[string]containerHistory
+	lastUpdate time.Time
+	lastErr    error
+}
+
+// NewModel creates a Model sorted by memory Limit%, descending
+func NewModel(units *output.UnitFormatter, colorizer *output.Colorizer) *Model {
+	return &Model{
+		units:      units,
+		colorizer:  colorizer,
+		sortColumn: SortColumn{Resource: corev1.ResourceMemory, Metric: output.MetricLimitPercent},
+		history:    make(map[string]map[string]containerHistory),
+	}
+}
+
+// Update replaces the pods shown and appends their usage to the history
+func (m *Model) Update(pods []calculator.PodUsage, now time.Time) {
+	m.pods = pods
+	m.lastUpdate = now
+	m.lastErr = nil
+
+	seen := make(map[string]bool, len(pods))
+	for _, pu := range pods {
+		key := podKey(pu)
+		seen[key] = true
+		containers, ok := m.history[key]
+		if !ok {
+			containers = make(map[string]containerHistory)
+			m.history[key] = containers
+		}
+		for _, cu := range pu.Containers {
+			h, ok := containers[cu.Name]
+			if !ok {
+				h = make(containerHistory)
+				containers[cu.Name] = h
+			}
+			for name, ru := range cu.Resources {
+				if !ru.UsageUnavailable {
+					h[name] = appendSample(h[name], ru.Usage.AsApproximateFloat64())
+				}
+			}
+		}
+	}
+	for key := range m.history {
+		if !seen[key] {
+			delete(m.history, key)
+		}
+	}
+}
+
+// SetError records a failed refresh; the previous data stays on screen
+func (m *Model) SetError(err error) {
+	m.lastErr = err
+}
+
+// Paused reports whether refreshing is paused
+func (m *Model) Paused() bool {
+	return m.paused
+}
+
+// HandleKey applies a key press and reports whether the program should quit
+func (m *Model) HandleKey(k Key) bool {
+	if k.Type == KeyCtrlC {
+		return true
+	}
+	if m.filtering {
+		m.handleFilterKey(k)
+		return false
+	}
+	if m.detail != "" {
+		return m.handleDetailKey(k)
+	}
+
+	switch {
+	case k.Type == KeyUp || isRune(k, 'k'):
+		m.cursor--
+	case k.Type == KeyDown || isRune(k, 'j'):
+		m.cursor++
+	case k.Type == KeyHome:
+		m.cursor = 0
+	case k.Type == KeyEnd:
+		m.cursor = len(m.rows()) - 1
+	case k.Type == KeyLeft:
+		m.moveSortColumn(-1)
+	case k.Type == KeyRight || isRune(k, 's'):
+		m.moveSortColumn(1)
+	case isRune(k, 'r'):
+		m.ascending = !m.ascending
+	case isRune(k, 'v') || isRune(k, '\t'):
+		m.setView((m.view + 1) % View(len(viewNames)))
+	case k.Type == KeyRune && k.Rune >= '1' && k.Rune <= '4':
+		m.setView(View(k.Rune - '1'))
+	case isRune(k, '/'):
+		m.filtering = true
+	case k.Type == KeyEscape:
+		m.filter = ""
+	case k.Type == KeyEnter:
+		rows := m.rows()
+		m.clampCursor(len(rows))
+		if len(rows) > 0 && rows[m.cursor].podKey != "" {
+			m.detail = rows[m.cursor].podKey
+		}
+	case isRune(k, 'p') || isRune(k, ' '):
+		m.paused = !m.paused
+	case isRune(k, 'q'):
+		return true
+	}
+
+	m.clampCursor(len(m.rows()))
+	return false
+}
+
+// handleFilterKey edits the filter while '/' input is active
+func (m *Model) handleFilterKey(k Key) {
+	switch k.Type {
+	case KeyEnter:
+		m.filtering = false
+	case KeyEscape:
+		m.filter = ""
+		m.filtering = false
+	case KeyBackspace:
+		if runes := []rune(m.filter); len(runes) > 0 {
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case KeyRune:
+		m.filter += string(k.Rune)
+	}
+	m.cursor = 0
+}
+
+// handleDetailKey handles keys while a pod is drilled into
+func (m *Model) handleDetailKey(k Key) bool {
+	switch {
+	case k.Type == KeyEscape || k.Type == KeyBackspace || k.Type == KeyEnter || k.Type == KeyLeft:
+		m.detail = ""
+	case isRune(k, 'p') || isRune(k, ' '):
+		m.paused = !m.paused
+	case isRune(k, 'q'):
+		return true
+	}
+	return false
+}
+
+// moveSortColumn selects the previous or next sort column, wrapping around.
+// A column that is no longer shown moves from NAME.
+func (m *Model) moveSortColumn(delta int) {
+	columns := m.sortColumns()
+	i := slices.Index(columns, m.sortColumn)
+	if i < 0 {
+		i = 0
+	}
+	m.sortColumn = columns[(i+delta+len(columns))%len(columns)]
+}
+
+// sortColumns returns NAME and the metric columns of the shown resources
+func (m *Model) sortColumns() []SortColumn {
+	columns := []SortColumn{{}}
+	for _, def := range m.resources() {
+		for _, metric := range metrics {
+			columns = append(columns, SortColumn{Resource: def.Name, Metric: metric})
+		}
+	}
+	return columns
+}
+
+// resources returns the resources shown for the pods, like the table output's:
+// optional ones such as ephemeral storage only once a pod reports them
+func (m *Model) resources() []calculator.ResourceDefinition {
+	return calculator.ShownResources(m.pods)
+}
+
+// multiCluster reports whether the pods come from named clusters, which adds a CLUSTER column
+func (m *Model) multiCluster() bool {
+	for _, pu := range m.pods {
+		if pu.Cluster != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// columns returns the label columns of the current view
+func (m *Model) columns() []labelColumn {
+	if m.multiCluster() {
+		return append([]labelColumn{clusterColumn}, viewColumns[m.view]...)
+	}
+	return viewColumns[m.view]
+}
+
+// setView switches the view and resets the cursor
+func (m *Model) setView(v View) {
+	m.view = v
+	m.cursor = 0
+}
+
+// clampCursor keeps the cursor within the rows
+func (m *Model) clampCursor(n int) {
+	if m.cursor >= n {
+		m.cursor = n - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// rows builds the filtered and sorted rows of the current view
+func (m *Model) rows() []row {
+	multiCluster := m.multiCluster()
+	labels := func(cluster string, values ...string) []string {
+		if multiCluster {
+			return append([]string{cluster}, values...)
+		}
+		return values
+	}
+
+	var rows []row
+	switch m.view {
+	case ViewContainers:
+		for _, pu := range m.pods {
+			for _, cu := range pu.Containers {
+				rows = append(rows, row{
+					podKey:    podKey(pu),
+					labels:    labels(pu.Cluster, pu.Namespace, pu.Name, cu.Name),
+					resources: cu.Resources,
+				})
+			}
+		}
+	case ViewWorkloads:
+		// Workloads of different clusters are separate rows, like the table
+		// output's; NUL separates the key since context names may contain slashes
+		groups := calculator.Rollup(m.pods, func(pu calculator.PodUsage) string {
+			return pu.Cluster + "\x00" + pu.Namespace + "\x00" + pu.Workload
+		})
+		for _, g := range groups {
+			parts := strings.SplitN(g.Name, "\x00", 3)
+			rows = append(rows, row{
+				labels:    labels(parts[0], parts[1], parts[2], fmt.Sprint(g.Pods)),
+				resources: g.Resources,
+			})
+		}
+	case ViewNodes:
+		groups := calculator.Rollup(m.pods, func(pu calculator.PodUsage) string {
+			return pu.Cluster + "\x00" + pu.Node
+		})
+		for _, g := range groups {
+			parts := strings.SplitN(g.Name, "\x00", 2)
+			rows = append(rows, row{
+				labels:    labels(parts[0], parts[1], fmt.Sprint(g.Pods)),
+				resources: g.Resources,
+			})
+		}
+	default:
+		for _, pu := range m.pods {
+			rows = append(rows, row{
+				podKey:    podKey(pu),
+				labels:    labels(pu.Cluster, pu.Namespace, pu.Name, pu.Node),
+				resources: pu.Resources,
+			})
+		}
+	}
+
+	if m.filter != "" {
+		needle := strings.ToLower(m.filter)
+		filtered := rows[:0]
+		for _, r := range rows {
+			if strings.Contains(strings.ToLower(strings.Join(r.labels, " ")), needle) {
+				filtered = append(filtered, r)
+			}
+		}
+		rows = filtered
+	}
+
+	m.sortRows(rows)
+	return rows
+}
+
+// sortRows sorts rows by the current sort column; N/A values go to the end
+func (m *Model) sortRows(rows []row) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if m.sortColumn.Resource == "" {
+			ni, nj := strings.Join(rows[i].labels, "/"), strings.Join(rows[j].labels, "/")
+			if m.ascending {
+				return ni < nj
+			}
+			return ni > nj
+		}
+
+		vi, vj := m.sortValue(rows[i]), m.sortValue(rows[j])
+		if vi == nil || vj == nil {
+			return vi != nil
+		}
+		if m.ascending {
+			return *vi < *vj
+		}
+		return *vi > *vj
+	})
+}
+
+// sortValue returns the value of the sort column for a row, or nil for N/A
+func (m *Model) sortValue(r row) *float64 {
+	ru, ok := r.resources[m.sortColumn.Resource]
+	switch m.sortColumn.Metric {
+	case output.MetricRequestPercent:
+		return ru.RequestPercent
+	case output.MetricLimitPercent:
+		return ru.LimitPercent
+	}
+	if !ok || ru.UsageUnavailable {
+		return nil
+	}
+	v := ru.Usage.AsApproximateFloat64()
+	return &v
+}
+
+// Render draws the current state as lines fitting the given height
+func (m *Model) Render(width, height int) []string {
+	lines := []string{m.statusLine(), m.infoLine(), ""}
+	footer := m.helpLine()
+
+	if m.detail != "" {
+		lines = append(lines, m.renderDetail(width)...)
+	} else {
+		lines = append(lines, m.renderTable(height-len(lines)-2)...)
+	}
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	if len(lines) > height-1 && height > 1 {
+		lines = lines[:height-1]
+	}
+	return append(lines, footer)
+}
+
+// statusLine describes the view and sort order
+func (m *Model) statusLine() string {
+	direction := "desc"
+	if m.ascending {
+		direction = "asc"
+	}
+	return fmt.Sprintf("kubectl resource-usage  view: %s  sort: %s %s", viewNames[m.view], m.sortColumn, direction)
+}
+
+// infoLine shows the refresh state, the filter and the last error
+func (m *Model) infoLine() string {
+	var parts []string
+	if m.lastUpdate.IsZero() {
+		parts = append(parts, "loading...")
+	} else {
+		parts = append(parts, "updated "+m.lastUpdate.Format("15:04:05"))
+	}
+	if m.paused {
+		parts = append(parts, "[PAUSED]")
+	}
+	if m.filtering {
+		parts = append(parts, "filter: /"+m.filter+"_")
+	} else if m.filter != "" {
+		parts = append(parts, "filter: "+m.filter)
+	}
+	if m.lastErr != nil {
+		parts = append(parts, "error: "+m.lastErr.Error())
+	}
+	return strings.Join(parts, "  ")
+}
+
+// helpLine lists the key bindings
+func (m *Model) helpLine() string {
+	if m.detail != "" {
+		return "esc back  p pause  q quit"
+	}
+	return "↑/↓ move  ←/→ sort  r reverse  v/1-4 view  / filter  enter details  p pause  q quit"
+}
+
+// renderTable draws the header and the rows visible around the cursor
+func (m *Model) renderTable(maxRows int) []string {
+	columns, resources := m.columns(), m.resources()
+	rows := m.rows()
+	m.clampCursor(len(rows))
+
+	var header strings.Builder
+	header.WriteString("  ")
+	for _, c := range columns {
+		fmt.Fprintf(&header, "%-*s ", c.width, c.header)
+	}
+	var headers []string
+	for _, def := range resources {
+		for _, metric := range metrics {
+			width := colPercent
+			if metric == metricUsage {
+				width = colUsage
+			}
+			headers = append(headers, fmt.Sprintf("%-*s", width, def.Column+metricSuffixes[metric]))
+		}
+	}
+	header.WriteString(strings.Join(headers, " "))
+	lines := []string{header.String()}
+
+	if len(rows) == 0 {
+		return append(lines, "  No pods found matching the criteria")
+	}
+
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	start := 0
+	if m.cursor >= maxRows {
+		start = m.cursor - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	for i := start; i < end; i++ {
+		r := rows[i]
+		var b strings.Builder
+		if i == m.cursor {
+			b.WriteString("> ")
+		} else {
+			b.WriteString("  ")
+		}
+		for j, c := range columns {
+			fmt.Fprintf(&b, "%-*s ", c.width, fit(r.labels[j], c.width))
+		}
+		cells := make([]string, 0, len(resources)*len(metrics))
+		for _, def := range resources {
+			ru := r.resources[def.Name]
+			cells = append(cells,
+				fmt.Sprintf("%-*s", colUsage, m.units.FormatUsage(def.Unit, ru)),
+				m.colorizer.FormatPercent(output.Field{Resource: def.Name, Metric: output.MetricRequestPercent}, ru.RequestPercent, colPercent),
+				m.colorizer.FormatPercent(output.Field{Resource: def.Name, Metric: output.MetricLimitPercent}, ru.LimitPercent, colPercent))
+		}
+		b.WriteString(strings.Join(cells, " "))
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// renderDetail draws per-container usage and history sparklines of the selected pod
+func (m *Model) renderDetail(width int) []string {
+	var pod *calculator.PodUsage
+	for i := range m.pods {
+		if podKey(m.pods[i]) == m.detail {
+			pod = &m.pods[i]
+			break
+		}
+	}
+	if pod == nil {
+		return []string{"Pod " + m.detail + " is no longer reported"}
+	}
+
+	sparkWidth := width - 70
+	if sparkWidth > historySize {
+		sparkWidth = historySize
+	}
+	if sparkWidth < 10 {
+		sparkWidth = 10
+	}
+
+	lines := []string{
+		fmt.Sprintf("Pod %s  node: %s  workload: %s", m.detail, pod.Node, pod.Workload),
+		"",
+	}
+	for _, cu := range pod.Containers {
+		h := m.history[m.detail][cu.Name]
+		lines = append(lines, "  "+cu.Name)
+		for _, def := range m.resources() {
+			ru := cu.Resources[def.Name]
+			var limit float64
+			if ru.Limits != nil {
+				limit = ru.Limits.AsApproximateFloat64()
+			}
+			lines = append(lines, fmt.Sprintf("    %-4s %-*s req %-*s lim %-*s %s %s %s", def.Column,
+				colUsage, m.units.FormatUsage(def.Unit, ru),
+				colUsage, m.units.FormatQuantityOrNA(def.Unit, ru.Requests), colUsage, m.units.FormatQuantityOrNA(def.Unit, ru.Limits),
+				m.colorizer.FormatPercent(output.Field{Resource: def.Name, Metric: output.MetricRequestPercent}, ru.RequestPercent, colPercent),
+				m.colorizer.FormatPercent(output.Field{Resource: def.Name, Metric: output.MetricLimitPercent}, ru.LimitPercent, colPercent),
+				sparkline(h[def.Name], sparkWidth, limit)))
+		}
+	}
+	return lines
+}
+
+// appendSample appends v and keeps the last historySize samples
+func appendSample(samples []float64, v float64) []float64 {
+	samples = append(samples, v)
+	if len(samples) > historySize {
+		samples = samples[len(samples)-historySize:]
+	}
+	return samples
+}
+
+// podKey identifies a pod across refreshes
+func podKey(pu calculator.PodUsage) string {
+	return pu.Key()
+}
+
+// isRune checks if k is the given printable key
+func isRune(k Key, r rune) bool {
+	return k.Type == KeyRune && k.Rune == r
+}
+
+// fit truncates s to width runes (rune-safe for UTF-8)
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
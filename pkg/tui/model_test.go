@@ -0,0 +1,478 @@
+package tui
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/output"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func testPods() []calculator.PodUsage {
+	return []calculator.PodUsage{
+		{
+			Namespace: "default",
+			Name:      "api-1",
+			Node:      "node-1",
+			Workload:  "Deployment/api",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("200m"), LimitPercent: floatPtr(20)},
+				corev1.ResourceMemory: {Usage: resource.MustParse("400Mi"), LimitPercent: floatPtr(80)},
+			},
+			Containers: []calculator.ContainerUsage{
+				{
+					Name: "app",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU:    {Usage: resource.MustParse("190m")},
+						corev1.ResourceMemory: {Usage: resource.MustParse("390Mi"), Limits: quantityPtr("500Mi")},
+					},
+				},
+				{
+					Name: "proxy",
+					Resources: calculator.ResourceUsages{
+						corev1.ResourceCPU:    {Usage: resource.MustParse("10m")},
+						corev1.ResourceMemory: {Usage: resource.MustParse("10Mi")},
+					},
+				},
+			},
+		},
+		{
+			Namespace: "default",
+			Name:      "api-2",
+			Node:      "node-2",
+			Workload:  "Deployment/api",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("900m"), LimitPercent: floatPtr(90)},
+				corev1.ResourceMemory: {Usage: resource.MustParse("100Mi"), LimitPercent: floatPtr(20)},
+			},
+			Containers: []calculator.ContainerUsage{{Name: "app"}},
+		},
+		{
+			Namespace: "kube-system",
+			Name:      "coredns",
+			Node:      "node-1",
+			Workload:  "Deployment/coredns",
+			Resources: calculator.ResourceUsages{
+				corev1.ResourceCPU:    {Usage: resource.MustParse("5m")},
+				corev1.ResourceMemory: {Usage: resource.MustParse("30Mi")},
+			},
+			Containers: []calculator.ContainerUsage{{Name: "coredns"}},
+		},
+	}
+}
+
+func newTestModel() *Model {
+	m := NewModel(output.NewUnitFormatter("auto"), output.NewColorizer(output.ColorModeNever))
+	m.Update(testPods(), time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local))
+	return m
+}
+
+func rowNames(rows []row) []string {
+	names := make([]string, 0, len(rows))
+	for _, r := range rows {
+		names = append(names, strings.Join(r.labels, "/"))
+	}
+	return names
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys([]byte("\x1b[A\x1b[Bj/\r\x1b\x7f\x03é"))
+	want := []Key{
+		{Type: KeyUp},
+		{Type: KeyDown},
+		{Type: KeyRune, Rune: 'j'},
+		{Type: KeyRune, Rune: '/'},
+		{Type: KeyEnter},
+		{Type: KeyEscape},
+		{Type: KeyBackspace},
+		{Type: KeyCtrlC},
+		{Type: KeyRune, Rune: 'é'},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %d keys, got %d: %v", len(want), len(keys), keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d: expected %v, got %v", i, want[i], keys[i])
+		}
+	}
+}
+
+func TestParseKeysInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  []rune
+	}{
+		{name: "invalid byte", input: []byte{0xff}, want: []rune{utf8.RuneError}},
+		{name: "truncated sequence", input: []byte{0xc3}, want: []rune{utf8.RuneError}},
+		{name: "invalid between runes", input: []byte{'a', 0xff, 'b'}, want: []rune{'a', utf8.RuneError, 'b'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := ParseKeys(tt.input)
+			if len(keys) != len(tt.want) {
+				t.Fatalf("expected %d keys, got %d: %v", len(tt.want), len(keys), keys)
+			}
+			for i, r := range tt.want {
+				if keys[i] != (Key{Type: KeyRune, Rune: r}) {
+					t.Errorf("key %d: expected %q, got %v", i, r, keys[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseKeysEscapeSequences(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Key
+	}{
+		{name: "ss3 arrow", input: "\x1bOB", want: []Key{{Type: KeyDown}}},
+		{name: "modified arrow", input: "\x1b[1;5Aj", want: []Key{{Type: KeyUp}, {Type: KeyRune, Rune: 'j'}}},
+		{name: "home and end", input: "\x1b[H\x1b[F\x1bOH\x1bOF", want: []Key{{Type: KeyHome}, {Type: KeyEnd}, {Type: KeyHome}, {Type: KeyEnd}}},
+		{name: "tilde home and end", input: "\x1b[1~\x1b[4~\x1b[7~\x1b[8;2~", want: []Key{{Type: KeyHome}, {Type: KeyEnd}, {Type: KeyHome}, {Type: KeyEnd}}},
+		{name: "unsupported keys", input: "\x1b[5~\x1b[15~\x1bOPq", want: []Key{{Type: KeyRune, Rune: 'q'}}},
+		{name: "escape then key", input: "\x1bq", want: []Key{{Type: KeyEscape}, {Type: KeyRune, Rune: 'q'}}},
+		{name: "truncated sequence", input: "\x1b[1;", want: []Key{{Type: KeyEscape}, {Type: KeyRune, Rune: '['}, {Type: KeyRune, Rune: '1'}, {Type: KeyRune, Rune: ';'}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := ParseKeys([]byte(tt.input))
+			if !reflect.DeepEqual(keys, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, keys)
+			}
+		})
+	}
+}
+
+func TestReadKeysSplitEscape(t *testing.T) {
+	r, w := io.Pipe()
+	keys := make(chan []Key)
+	go readKeys(r, keys, func() {})
+
+	// A Ctrl+Up sequence split over three reads is a single key
+	for _, chunk := range []string{"\x1b", "[1;5", "A"} {
+		_, _ = w.Write([]byte(chunk))
+	}
+	if ks := <-keys; !reflect.DeepEqual(ks, []Key{{Type: KeyUp}}) {
+		t.Errorf("expected a single up key, got %v", ks)
+	}
+
+	// A lone ESC is the Escape key once no sequence follows it in time
+	_, _ = w.Write([]byte("\x1b"))
+	if ks := <-keys; !reflect.DeepEqual(ks, []Key{{Type: KeyEscape}}) {
+		t.Errorf("expected the escape key, got %v", ks)
+	}
+
+	_ = w.Close()
+	if ks, ok := <-keys; ok {
+		t.Errorf("expected keys to be closed, got %v", ks)
+	}
+}
+
+func TestReadKeysSplitRune(t *testing.T) {
+	r, w := io.Pipe()
+	keys := make(chan []Key)
+	go readKeys(r, keys, func() {})
+
+	go func() {
+		// é is 0xc3 0xa9; send it in two reads
+		_, _ = w.Write([]byte{'a', 0xc3})
+		_, _ = w.Write([]byte{0xa9})
+		_ = w.Close()
+	}()
+
+	var got []Key
+	for ks := range keys {
+		got = append(got, ks...)
+	}
+	want := []Key{{Type: KeyRune, Rune: 'a'}, {Type: KeyRune, Rune: 'é'}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestModelDefaultSortsByMemoryLimit(t *testing.T) {
+	m := newTestModel()
+	got := rowNames(m.rows())
+	want := []string{"default/api-1/node-1", "default/api-2/node-2", "kube-system/coredns/node-1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestModelChangeSortColumn(t *testing.T) {
+	m := newTestModel()
+
+	// MEM_LIM% -> NAME (wraps around)
+	m.HandleKey(Key{Type: KeyRight})
+	if m.sortColumn != (SortColumn{}) {
+		t.Fatalf("expected sort by name, got %s", m.sortColumn)
+	}
+	// NAME -> CPU_USAGE
+	m.HandleKey(Key{Type: KeyRune, Rune: 's'})
+	got := rowNames(m.rows())
+	if got[0] != "default/api-2/node-2" {
+		t.Errorf("expected api-2 first when sorted by CPU usage, got %v", got)
+	}
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'r'})
+	got = rowNames(m.rows())
+	if got[0] != "kube-system/coredns/node-1" {
+		t.Errorf("expected coredns first when sorted ascending, got %v", got)
+	}
+
+	m.HandleKey(Key{Type: KeyLeft})
+	if m.sortColumn != (SortColumn{}) {
+		t.Errorf("expected left to go back to name, got %s", m.sortColumn)
+	}
+}
+
+func TestModelViews(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'v'})
+	if m.view != ViewContainers || len(m.rows()) != 4 {
+		t.Errorf("expected 4 container rows, got %d in view %s", len(m.rows()), viewNames[m.view])
+	}
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'v'})
+	rows := m.rows()
+	if m.view != ViewWorkloads || len(rows) != 2 {
+		t.Fatalf("expected 2 workload rows, got %d in view %s", len(rows), viewNames[m.view])
+	}
+	names := strings.Join(rowNames(rows), ",")
+	if !strings.Contains(names, "default/Deployment/api/2") {
+		t.Errorf("expected api workload with 2 pods, got %s", names)
+	}
+
+	m.HandleKey(Key{Type: KeyRune, Rune: '4'})
+	if m.view != ViewNodes || len(m.rows()) != 2 {
+		t.Errorf("expected 2 node rows, got %d in view %s", len(m.rows()), viewNames[m.view])
+	}
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'v'})
+	if m.view != ViewPods {
+		t.Errorf("expected views to cycle back to pods, got %s", viewNames[m.view])
+	}
+}
+
+func TestModelWorkloadsKeepClustersApart(t *testing.T) {
+	var pods []calculator.PodUsage
+	for _, cluster := range []string{"prod", "arn:aws:eks:eu-west-1:1234:cluster/staging"} {
+		for _, pu := range testPods() {
+			pu.Cluster = cluster
+			pods = append(pods, pu)
+		}
+	}
+	m := NewModel(output.NewUnitFormatter("auto"), output.NewColorizer(output.ColorModeNever))
+	m.Update(pods, time.Now())
+	m.view = ViewWorkloads
+
+	names := strings.Join(rowNames(m.rows()), ",")
+	for _, want := range []string{"prod/default/Deployment/api/2", "arn:aws:eks:eu-west-1:1234:cluster/staging/default/Deployment/api/2"} {
+		if !strings.Contains(names, want) {
+			t.Errorf("expected row %s, got %s", want, names)
+		}
+	}
+}
+
+func TestModelClusterColumn(t *testing.T) {
+	m := newTestModel()
+	if header := m.Render(200, 30)[3]; strings.Contains(header, "CLUSTER") {
+		t.Errorf("expected no CLUSTER column for a single cluster, got %q", header)
+	}
+
+	pods := testPods()
+	for i := range pods {
+		pods[i].Cluster = "prod"
+	}
+	m.Update(pods, time.Now())
+	for _, view := range []View{ViewPods, ViewContainers} {
+		m.setView(view)
+		lines := m.Render(200, 30)
+		if !strings.HasPrefix(lines[3], "  CLUSTER") {
+			t.Errorf("%s: expected a leading CLUSTER column, got %q", viewNames[view], lines[3])
+		}
+		if !strings.HasPrefix(rowNames(m.rows())[0], "prod/default/api-1/") {
+			t.Errorf("%s: expected the cluster in the rows, got %v", viewNames[view], rowNames(m.rows()))
+		}
+	}
+}
+
+func TestModelStorageColumns(t *testing.T) {
+	m := newTestModel()
+	if header := m.Render(200, 30)[3]; strings.Contains(header, "EPH_USAGE") {
+		t.Errorf("expected no storage columns without storage usage, got %q", header)
+	}
+
+	pods := testPods()
+	pods[2].Resources[corev1.ResourceEphemeralStorage] = calculator.ResourceUsage{Usage: resource.MustParse("2Gi"), LimitPercent: floatPtr(95)}
+	m.Update(pods, time.Now())
+	if header := m.Render(200, 30)[3]; !strings.Contains(header, "EPH_USAGE  EPH_REQ%  EPH_LIM%") {
+		t.Errorf("expected storage columns, got %q", header)
+	}
+
+	// MEM_LIM% -> EPH_USAGE -> EPH_REQ% -> EPH_LIM%
+	for i := 0; i < 3; i++ {
+		m.HandleKey(Key{Type: KeyRight})
+	}
+	if got := m.sortColumn.String(); got != "EPH_LIM%" {
+		t.Fatalf("expected sort by EPH_LIM%%, got %s", got)
+	}
+	if got := rowNames(m.rows()); got[0] != "kube-system/coredns/node-1" {
+		t.Errorf("expected coredns first when sorted by storage Limit%%, got %v", got)
+	}
+}
+
+func TestModelFilter(t *testing.T) {
+	m := newTestModel()
+
+	for _, k := range ParseKeys([]byte("/dnsx")) {
+		m.HandleKey(k)
+	}
+	if !m.filtering {
+		t.Fatal("expected filter input to be active")
+	}
+	m.HandleKey(Key{Type: KeyBackspace})
+	m.HandleKey(Key{Type: KeyEnter})
+
+	if m.filtering || m.filter != "dns" {
+		t.Fatalf("expected filter 'dns' applied, got %q (filtering=%v)", m.filter, m.filtering)
+	}
+	if got := rowNames(m.rows()); len(got) != 1 || got[0] != "kube-system/coredns/node-1" {
+		t.Errorf("expected only coredns, got %v", got)
+	}
+
+	// 'q' while typing a filter is text, not quit
+	m.HandleKey(Key{Type: KeyRune, Rune: '/'})
+	if m.HandleKey(Key{Type: KeyRune, Rune: 'q'}) {
+		t.Error("expected 'q' in filter input not to quit")
+	}
+	m.HandleKey(Key{Type: KeyEscape})
+	if m.filter != "" || len(m.rows()) != 3 {
+		t.Errorf("expected escape to clear the filter")
+	}
+}
+
+func TestModelNavigationAndDetail(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(Key{Type: KeyUp})
+	if m.cursor != 0 {
+		t.Errorf("expected cursor clamped at 0, got %d", m.cursor)
+	}
+	for i := 0; i < 5; i++ {
+		m.HandleKey(Key{Type: KeyDown})
+	}
+	if m.cursor != 2 {
+		t.Errorf("expected cursor clamped at 2, got %d", m.cursor)
+	}
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'k'})
+	m.HandleKey(Key{Type: KeyRune, Rune: 'k'})
+	m.HandleKey(Key{Type: KeyEnter})
+	if m.detail != "default/api-1" {
+		t.Fatalf("expected detail of default/api-1, got %q", m.detail)
+	}
+
+	m.Update(testPods(), time.Now())
+	lines := strings.Join(m.Render(120, 30), "\n")
+	for _, want := range []string{"Pod default/api-1", "workload: Deployment/api", "app", "proxy", "MEM  390Mi", "lim 500Mi"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("expected detail to contain %q, got:\n%s", want, lines)
+		}
+	}
+	if h := m.history["default/api-1"]["app"]; h == nil || len(h[corev1.ResourceMemory]) != 2 {
+		t.Errorf("expected 2 memory samples for app, got %v", h)
+	}
+
+	m.HandleKey(Key{Type: KeyEscape})
+	if m.detail != "" {
+		t.Error("expected escape to leave the detail view")
+	}
+}
+
+func TestModelPauseAndQuit(t *testing.T) {
+	m := newTestModel()
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'p'})
+	if !m.Paused() {
+		t.Error("expected 'p' to pause")
+	}
+	if !strings.Contains(strings.Join(m.Render(120, 30), "\n"), "[PAUSED]") {
+		t.Error("expected paused marker in the status line")
+	}
+	m.HandleKey(Key{Type: KeyRune, Rune: ' '})
+	if m.Paused() {
+		t.Error("expected space to resume")
+	}
+
+	if !m.HandleKey(Key{Type: KeyRune, Rune: 'q'}) {
+		t.Error("expected 'q' to quit")
+	}
+	if !m.HandleKey(Key{Type: KeyCtrlC}) {
+		t.Error("expected ctrl-c to quit")
+	}
+}
+
+func TestModelRenderFitsHeight(t *testing.T) {
+	m := newTestModel()
+
+	lines := m.Render(120, 6)
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[len(lines)-1], "↑/↓ move") {
+		t.Errorf("expected help on the last line, got %q", lines[len(lines)-1])
+	}
+	if !strings.Contains(lines[3], "NAMESPACE") {
+		t.Errorf("expected table header, got %q", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], "> default") {
+		t.Errorf("expected selected first row, got %q", lines[4])
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		max    float64
+		want   string
+	}{
+		{"scaled to largest value", []float64{0, 50, 100}, 10, 0, "▁▄█"},
+		{"scaled to limit", []float64{0, 50, 100}, 10, 200, "▁▂▄"},
+		{"keeps last values", []float64{100, 0, 100}, 2, 0, "▁█"},
+		{"over limit is capped", []float64{300}, 10, 100, "█"},
+		{"empty", nil, 10, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values, tt.width, tt.max); got != tt.want {
+				t.Errorf("sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func quantityPtr(s string) *resource.Quantity {
+	q := resource.MustParse(s)
+	return &q
+}
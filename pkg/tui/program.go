@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences
+const (
+	enterAltScreen = "\033[?1049h"
+	exitAltScreen  = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	cursorHome     = "\033[H"
+	clearLine      = "\033[K"
+	clearBelow     = "\033[J"
+)
+
+// FetchFunc collects the pod usages shown on each refresh. It may return
+// pods together with an error when only part of the collection failed.
+type FetchFunc func(ctx context.Context) ([]calculator.PodUsage, error)
+
+// fetchResult is the outcome of a background refresh
+type fetchResult struct {
+	pods []calculator.PodUsage
+	err  error
+	at   time.Time
+}
+
+// Run runs the interactive view in the alternate screen buffer until the
+// user quits or ctx is cancelled. in and out must be terminals.
+func Run(ctx context.Context, in, out *os.File, interval time.Duration, fetch FetchFunc, model *Model) error {
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return fmt.Errorf("interactive mode requires a terminal")
+	}
+
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return fmt.Errorf("failed to set terminal to raw mode: %w", err)
+	}
+	_, _ = fmt.Fprint(out, enterAltScreen+hideCursor)
+	var restoreOnce sync.Once
+	restore := func() {
+		restoreOnce.Do(func() {
+			_, _ = fmt.Fprint(out, showCursor+exitAltScreen)
+			_ = term.Restore(inFd, state)
+		})
+	}
+	defer restore()
+
+	// The reader goroutine stays blocked on stdin after Run returns; the
+	// process exits right after, so it is not worth interrupting.
+	keys := make(chan []Key)
+	go readKeys(in, keys, restore)
+
+	results := make(chan fetchResult, 1)
+	inflight := false
+	refresh := func() {
+		inflight = true
+		go func() {
+			fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			pods, err := fetch(fetchCtx)
+			results <- fetchResult{pods: pods, err: err, at: time.Now()}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	refresh()
+	for {
+		width, height, err := term.GetSize(outFd)
+		if err != nil {
+			width, height = 120, 40
+		}
+		draw(out, model.Render(width, height))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !model.Paused() && !inflight {
+				refresh()
+			}
+		case r := <-results:
+			inflight = false
+			// A failed refresh may still carry the pods of the clusters that answered
+			if r.err == nil || r.pods != nil {
+				model.Update(r.pods, r.at)
+			}
+			if r.err != nil {
+				model.SetError(r.err)
+			}
+		case ks, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range ks {
+				if model.HandleKey(k) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// draw redraws the screen in place without clearing it first, which avoids flicker
+func draw(w io.Writer, lines []string) {
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+	_, _ = io.WriteString(w, b.String())
+}
+
+// readKeys reads raw input and sends parsed key presses until reading fails.
+// A character or escape sequence split across reads is held back until the
+// rest of it arrives, or escapeTimeout passes without it. A panic restores
+// the terminal before crashing, since Run's deferred calls don't run for it.
+func readKeys(r io.Reader, keys chan<- []Key, restore func()) {
+	defer close(keys)
+	defer func() {
+		if p := recover(); p != nil {
+			restore()
+			panic(p)
+		}
+	}()
+
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, 64)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var pending []byte
+	var timeout <-chan time.Time
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				if len(pending) > 0 {
+					keys <- ParseKeys(pending)
+				}
+				return
+			}
+			parsed, rest := parseKeys(append(pending, chunk...), false)
+			if len(parsed) > 0 {
+				keys <- parsed
+			}
+			pending, timeout = append([]byte(nil), rest...), nil
+			if len(pending) > 0 {
+				timeout = time.After(escapeTimeout)
+			}
+		case <-timeout:
+			keys <- ParseKeys(pending)
+			pending, timeout = nil, nil
+		}
+	}
+}
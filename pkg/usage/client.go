@@ -0,0 +1,318 @@
+// Package usage collects the resource usage of pods the way kubectl
+// resource-usage does, for programs embedding it.
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/cost"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Options enables the optional data sources of a Client
+type Options struct {
+	// AssumeLimitRangeDefaults computes percentages against the namespace
+	// LimitRange defaults of missing requests and limits. The defaults are
+	// reported as the values' source either way.
+	AssumeLimitRangeDefaults bool
+
+	// Throttling reads CFS throttling and CPU pressure from the kubelet cadvisor
+	// endpoint. The first collection of a namespace and selector covers the
+	// time since the containers started, later ones the time since the
+	// previous collection of the same namespace and selector.
+	Throttling bool
+
+	// Pricing estimates monthly pod costs at its rates, nil to skip costs
+	Pricing *cost.Pricing
+
+	// Storage reads ephemeral storage usage from the kubelet stats summary of
+	// every node, which needs the nodes/proxy permission
+	Storage bool
+
+	// Cluster names the cluster in every pod usage, e.g. its kubeconfig
+	// context; empty for a single cluster
+	Cluster string
+
+	// Warn is called once per collection with the nodes whose kubelet stats or
+	// cadvisor metrics could not be read; their pods keep that data
+	// unavailable. Nil ignores them.
+	Warn func(err error)
+}
+
+// Client collects pod usages from the cluster of a REST config. Use one
+// Client per cluster; it is safe for concurrent use.
+type Client struct {
+	cluster     string
+	metrics     *collector.MetricsCollector
+	pods        *collector.PodCollector
+	stats       *collector.StatsCollector // nil unless storage is collected
+	limitRanges *collector.LimitRangeCollector
+	assume      bool                         // LimitRange defaults fill missing requests and limits
+	cadvisor    *collector.CadvisorCollector // nil unless throttling is collected
+	nodes       *collector.NodeCollector     // nil unless pricing depends on node labels
+	pricing     *cost.Pricing                // nil unless costs are estimated
+	warn        func(err error)              // nil to ignore unreadable nodes
+
+	// throttling holds the previous CPU counters per namespace and selector,
+	// so collections of different pods don't reset each other's samples
+	mu         sync.Mutex
+	throttling map[string]*calculator.ThrottlingTracker
+}
+
+// NewClient creates a Client with the collectors the options need
+func NewClient(config *rest.Config, opts Options) (*Client, error) {
+	metricsCollector, err := collector.NewMetricsCollector(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics collector: %w", err)
+	}
+
+	podCollector, err := collector.NewPodCollector(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pod collector: %w", err)
+	}
+
+	limitRangeCollector, err := collector.NewLimitRangeCollector(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create limitrange collector: %w", err)
+	}
+
+	c := &Client{
+		metrics:     metricsCollector,
+		pods:        podCollector,
+		limitRanges: limitRangeCollector,
+		cluster:     opts.Cluster,
+		assume:      opts.AssumeLimitRangeDefaults,
+		pricing:     opts.Pricing,
+		warn:        opts.Warn,
+	}
+
+	if opts.Storage {
+		c.stats, err = collector.NewStatsCollector(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stats collector: %w", err)
+		}
+	}
+	if opts.Throttling {
+		c.cadvisor, err = collector.NewCadvisorCollector(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cadvisor collector: %w", err)
+		}
+		c.throttling = make(map[string]*calculator.ThrottlingTracker)
+	}
+	if opts.Pricing != nil && opts.Pricing.NeedsNodeLabels() {
+		c.nodes, err = collector.NewNodeCollector(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create node collector: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Collect fetches the pods matching the query and returns their usage,
+// filtered and sorted like the kubectl plugin's output
+func (c *Client) Collect(ctx context.Context, q Query) ([]PodUsage, error) {
+	if err := c.validate(q); err != nil {
+		return nil, err
+	}
+	podUsages, err := c.CollectPodUsages(ctx, q.Namespace, q.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return toPodUsages(q.Apply(podUsages)), nil
+}
+
+// validate checks the query, including the sort fields that need an optional data source
+func (c *Client) validate(q Query) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	if contains(calculator.CostSortFields(), q.SortBy) && c.pricing == nil {
+		return fmt.Errorf("sort %s requires pricing", q.SortBy)
+	}
+	if q.SortBy == calculator.SortByThrottled && c.cadvisor == nil {
+		return fmt.Errorf("sort %s requires throttling", q.SortBy)
+	}
+	if q.SortBy == string(corev1.ResourceEphemeralStorage) && c.stats == nil {
+		return fmt.Errorf("sort %s requires storage", q.SortBy)
+	}
+	return nil
+}
+
+// CollectPodUsages fetches pod metrics and specs of the namespace, or of
+// every namespace if it is empty, and joins them into unfiltered pod usages.
+// It is the building block of Collect for callers working with the calculator package.
+func (c *Client) CollectPodUsages(ctx context.Context, namespace, selector string) ([]calculator.PodUsage, error) {
+	// Fetch pod metrics
+	podMetrics, err := c.metrics.GetPodMetrics(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+
+	// Fetch pods with label selector
+	pods, err := c.pods.GetPods(ctx, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+
+	// Fetch LimitRange defaults for containers without their own values. They
+	// only annotate the values unless assumed, so without the permission to
+	// list LimitRanges the values are left unannotated instead of failing.
+	var defaults map[string]*calculator.LimitRangeDefaults
+	limitRanges, err := c.limitRanges.GetLimitRanges(ctx, namespace)
+	switch {
+	case err == nil:
+		defaults = calculator.LimitRangeDefaultsByNamespace(limitRanges.Items, c.assume)
+	case c.assume:
+		return nil, fmt.Errorf("failed to get limitranges: %w", err)
+	}
+
+	// Build pod map for quick lookup
+	podMap := make(map[string]int)
+	for i, pod := range pods.Items {
+		key := pod.Namespace + "/" + pod.Name
+		podMap[key] = i
+	}
+
+	// Calculate usage for each pod
+	podUsages := make([]calculator.PodUsage, 0, len(podMetrics.Items))
+	for _, pm := range podMetrics.Items {
+		key := pm.Namespace + "/" + pm.Name
+		podIndex, exists := podMap[key]
+		if !exists {
+			continue
+		}
+		podUsage := calculator.CalculatePodUsageWithDefaults(pm, pods.Items[podIndex], defaults[pm.Namespace])
+		podUsage.Cluster = c.cluster
+		podUsages = append(podUsages, podUsage)
+	}
+
+	// Nodes that cannot be read only leave their pods' storage or throttling unavailable
+	nodeErr := errors.Join(c.addStorageUsage(ctx, podUsages), c.addThrottling(ctx, podUsages, namespace, selector))
+	if nodeErr != nil && c.warn != nil {
+		c.warn(nodeErr)
+	}
+	if err := c.addCosts(ctx, podUsages); err != nil {
+		return nil, err
+	}
+	return podUsages, nil
+}
+
+// addCosts estimates the pods' costs at the rates of their nodes
+func (c *Client) addCosts(ctx context.Context, podUsages []calculator.PodUsage) error {
+	if c.pricing == nil {
+		return nil
+	}
+
+	var nodeLabels map[string]map[string]string
+	if c.nodes != nil {
+		nodes, err := c.nodes.GetNodes(ctx, "")
+		if err != nil {
+			return fmt.Errorf("failed to get nodes for pricing: %w", err)
+		}
+		nodeLabels = make(map[string]map[string]string, len(nodes.Items))
+		for _, node := range nodes.Items {
+			nodeLabels[node.Name] = node.Labels
+		}
+	}
+
+	cost.Apply(podUsages, c.pricing, nodeLabels)
+	return nil
+}
+
+// addStorageUsage fills in ephemeral storage usage from the kubelet stats of
+// the pods' nodes. Reading node stats needs the nodes/proxy permission, so
+// pods whose stats cannot be fetched keep their storage usage unavailable;
+// the nodes that failed are returned in the error.
+func (c *Client) addStorageUsage(ctx context.Context, podUsages []calculator.PodUsage) error {
+	if c.stats == nil {
+		return nil
+	}
+
+	stats, err := c.stats.GetPodStorageStats(ctx, podNodes(podUsages))
+	for i := range podUsages {
+		s, ok := stats[podUsages[i].Namespace+"/"+podUsages[i].Name]
+		if !ok {
+			continue
+		}
+		calculator.SetEphemeralStorageUsage(&podUsages[i], s.UsedBytes, s.Containers)
+	}
+	if err != nil {
+		return fmt.Errorf("ephemeral storage usage unavailable: %w", err)
+	}
+	return nil
+}
+
+// addThrottling fills in CPU throttling and pressure from the cadvisor
+// metrics of the pods' nodes, since each container's previous collection
+// with the same namespace and selector. Like kubelet stats, cadvisor is read through the nodes/proxy
+// permission; pods whose node cannot be read keep no throttling, and the
+// nodes that failed are returned in the error.
+func (c *Client) addThrottling(ctx context.Context, podUsages []calculator.PodUsage, namespace, selector string) error {
+	if c.cadvisor == nil {
+		return nil
+	}
+	tracker := c.throttlingTracker(namespace, selector)
+
+	stats, err := c.cadvisor.GetPodCPUStats(ctx, podNodes(podUsages))
+	pods := make(map[string]bool, len(podUsages))
+	for i := range podUsages {
+		podKey := podUsages[i].Namespace + "/" + podUsages[i].Name
+		pods[podKey] = true
+		containers, ok := stats[podKey]
+		if !ok {
+			continue
+		}
+		var podStats calculator.CPUStats
+		for name, s := range containers {
+			podStats = podStats.Add(tracker.Since(podKey+"/"+name, calculator.CPUStats{
+				Periods:          s.Periods,
+				ThrottledPeriods: s.ThrottledPeriods,
+				WaitingSeconds:   s.WaitingSeconds,
+				RunningSeconds:   s.RunningSeconds,
+			}))
+		}
+		calculator.SetThrottling(&podUsages[i], podStats)
+	}
+	// Samples of pods on unreadable nodes are kept for the next collection
+	tracker.Prune(func(key string) bool {
+		return pods[key[:strings.LastIndex(key, "/")]]
+	})
+	if err != nil {
+		return fmt.Errorf("CPU throttling unavailable: %w", err)
+	}
+	return nil
+}
+
+// throttlingTracker returns the CPU counter samples of a namespace and selector
+func (c *Client) throttlingTracker(namespace, selector string) *calculator.ThrottlingTracker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := namespace + "\x00" + selector
+	tracker, ok := c.throttling[key]
+	if !ok {
+		tracker = calculator.NewThrottlingTracker()
+		c.throttling[key] = tracker
+	}
+	return tracker
+}
+
+// podNodes returns the distinct nodes the pods run on
+func podNodes(podUsages []calculator.PodUsage) []string {
+	seen := make(map[string]bool)
+	var nodes []string
+	for _, pu := range podUsages {
+		if pu.Node != "" && !seen[pu.Node] {
+			seen[pu.Node] = true
+			nodes = append(nodes, pu.Node)
+		}
+	}
+	return nodes
+}
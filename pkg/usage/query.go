@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Query selects, filters and orders the pods Collect returns. The zero value
+// returns every pod in every namespace, in the order the API listed them.
+type Query struct {
+	Namespace string // empty means all namespaces
+	Selector  string // label selector, e.g. app=api
+
+	// SortBy orders pods by one of calculator.SortFields, empty to keep the API order.
+	// Cost fields need Options.Pricing and calculator.SortByThrottled needs Options.Throttling.
+	SortBy    string
+	Ascending bool
+
+	// Above and Below keep pods whose Limit% of the SortBy field (memory if
+	// unset) is at least or at most the percentage; nil means not set
+	Above *int
+	Below *int
+
+	NoLimits          bool     // keep pods missing a limit; cannot be used with Above or Below
+	QOSClass          string   // keep pods in this QoS class, one of QOSClasses
+	Phase             string   // keep pods in this phase, one of Phases
+	MinRestarts       int32    // keep pods restarted at least this many times
+	OOMKilled         bool     // keep pods with a container whose last termination was an OOM kill
+	ExcludeNamespaces []string // drop pods in these namespaces
+}
+
+// QOSClasses returns the values Query.QOSClass accepts, matched ignoring case
+func QOSClasses() []string {
+	return []string{"Guaranteed", "Burstable", "BestEffort"}
+}
+
+// Phases returns the values Query.Phase accepts, matched ignoring case
+func Phases() []string {
+	return []string{"Pending", "Running", "Succeeded", "Failed", "Unknown"}
+}
+
+// Validate checks the query's values
+func (q Query) Validate() error {
+	if q.Selector != "" {
+		if _, err := labels.Parse(q.Selector); err != nil {
+			return fmt.Errorf("invalid label selector: %w", err)
+		}
+	}
+	if q.SortBy != "" && !contains(calculator.SortFields(), q.SortBy) {
+		return fmt.Errorf("invalid sort field: %s (must be one of: %v)", q.SortBy, calculator.SortFields())
+	}
+	if q.Above != nil && (*q.Above < 0 || *q.Above > 100) {
+		return fmt.Errorf("invalid above value: %d (must be between 0 and 100)", *q.Above)
+	}
+	if q.Below != nil && (*q.Below < 0 || *q.Below > 100) {
+		return fmt.Errorf("invalid below value: %d (must be between 0 and 100)", *q.Below)
+	}
+	if q.Above != nil && q.Below != nil && *q.Above > *q.Below {
+		return fmt.Errorf("above (%d) cannot be greater than below (%d)", *q.Above, *q.Below)
+	}
+	if q.NoLimits && (q.Above != nil || q.Below != nil) {
+		return fmt.Errorf("no limits cannot be used with above or below")
+	}
+	if q.QOSClass != "" && !containsFold(QOSClasses(), q.QOSClass) {
+		return fmt.Errorf("invalid QoS class: %s (must be one of: %v)", q.QOSClass, QOSClasses())
+	}
+	if q.Phase != "" && !containsFold(Phases(), q.Phase) {
+		return fmt.Errorf("invalid phase: %s (must be one of: %v)", q.Phase, Phases())
+	}
+	if q.MinRestarts < 0 {
+		return fmt.Errorf("invalid min restarts: %d (must be at least 0)", q.MinRestarts)
+	}
+	return nil
+}
+
+// Apply filters pod usages by the query and sorts them by SortBy. The
+// namespace and selector are applied when collecting, not here.
+func (q Query) Apply(podUsages []calculator.PodUsage) []calculator.PodUsage {
+	podUsages = calculator.FilterPodUsages(podUsages, q.filterOptions())
+	if q.SortBy != "" {
+		calculator.SortPodUsages(podUsages, q.SortBy, q.Ascending)
+	}
+	return podUsages
+}
+
+// filterOptions converts the query's filters to calculator filter options
+func (q Query) filterOptions() calculator.FilterOptions {
+	opts := calculator.NewFilterOptions()
+	if q.SortBy != "" {
+		opts.Field = q.SortBy
+	}
+	if q.Above != nil {
+		opts.Above = *q.Above
+	}
+	if q.Below != nil {
+		opts.Below = *q.Below
+	}
+	opts.NoLimits = q.NoLimits
+	opts.QOSClass = q.QOSClass
+	opts.Phase = q.Phase
+	opts.MinRestarts = q.MinRestarts
+	opts.OOMKilled = q.OOMKilled
+	opts.ExcludeNamespaces = q.ExcludeNamespaces
+	return opts
+}
+
+// contains reports whether values contains s
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
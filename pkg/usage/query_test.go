@@ -0,0 +1,147 @@
+package usage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/collector"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQueryValidate(t *testing.T) {
+	percent := func(p int) *int { return &p }
+	tests := []struct {
+		name   string
+		query  Query
+		errMsg string
+	}{
+		{name: "zero value", query: Query{}},
+		{name: "all filters", query: Query{Selector: "app=api", SortBy: "cpu", Above: percent(50), Below: percent(90), QOSClass: "burstable", Phase: "Running"}},
+		{name: "invalid selector", query: Query{Selector: "app in ("}, errMsg: "invalid label selector"},
+		{name: "invalid sort", query: Query{SortBy: "disk"}, errMsg: "invalid sort field"},
+		{name: "above out of range", query: Query{Above: percent(101)}, errMsg: "invalid above value"},
+		{name: "above greater than below", query: Query{Above: percent(80), Below: percent(20)}, errMsg: "cannot be greater"},
+		{name: "no limits with above", query: Query{NoLimits: true, Above: percent(0)}, errMsg: "no limits cannot be used"},
+		{name: "invalid qos", query: Query{QOSClass: "Premium"}, errMsg: "invalid QoS class"},
+		{name: "invalid phase", query: Query{Phase: "Sleeping"}, errMsg: "invalid phase"},
+		{name: "negative restarts", query: Query{MinRestarts: -1}, errMsg: "invalid min restarts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestClientValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *Client
+		sortBy string
+		errMsg string
+	}{
+		{name: "plain sort", client: &Client{}, sortBy: "memory"},
+		{name: "cost without pricing", client: &Client{}, sortBy: calculator.SortByIdleCost, errMsg: "requires pricing"},
+		{name: "throttled without throttling", client: &Client{}, sortBy: calculator.SortByThrottled, errMsg: "requires throttling"},
+		{name: "throttled with throttling", client: &Client{cadvisor: &collector.CadvisorCollector{}}, sortBy: calculator.SortByThrottled},
+		{name: "storage without storage", client: &Client{}, sortBy: "ephemeral-storage", errMsg: "requires storage"},
+		{name: "storage with storage", client: &Client{stats: &collector.StatsCollector{}}, sortBy: "ephemeral-storage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.client.validate(Query{SortBy: tt.sortBy})
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+func TestQueryApply(t *testing.T) {
+	pod := func(namespace, name, cpuUsage, memoryUsage string) calculator.PodUsage {
+		limit := func(s string) *resource.Quantity {
+			q := resource.MustParse(s)
+			return &q
+		}
+		cpu := calculator.ResourceUsage{Usage: resource.MustParse(cpuUsage), Limits: limit("1")}
+		cpu.LimitPercent = calculator.CalculatePercent(&cpu.Usage, cpu.Limits)
+		memory := calculator.ResourceUsage{Usage: resource.MustParse(memoryUsage), Limits: limit("1Gi")}
+		memory.LimitPercent = calculator.CalculatePercent(&memory.Usage, memory.Limits)
+		return calculator.PodUsage{
+			Namespace: namespace,
+			Name:      name,
+			Resources: calculator.ResourceUsages{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory},
+		}
+	}
+	pods := func() []calculator.PodUsage {
+		return []calculator.PodUsage{
+			pod("default", "idle", "100m", "900Mi"),
+			pod("default", "busy", "900m", "100Mi"),
+			pod("kube-system", "dns", "500m", "600Mi"),
+			pod("default", "mid", "600m", "200Mi"),
+		}
+	}
+	names := func(pods []calculator.PodUsage) string {
+		result := make([]string, 0, len(pods))
+		for _, pu := range pods {
+			result = append(result, pu.Name)
+		}
+		return strings.Join(result, ",")
+	}
+	above := 50
+
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{name: "zero value keeps the API order", query: Query{}, want: "idle,busy,dns,mid"},
+		{name: "sort ascending", query: Query{SortBy: "cpu", Ascending: true}, want: "idle,dns,mid,busy"},
+		{name: "above applies to memory without a sort field", query: Query{Above: &above}, want: "idle,dns"},
+		{name: "above applies to the sort field", query: Query{SortBy: "cpu", Above: &above}, want: "busy,mid,dns"},
+		{name: "exclude namespaces", query: Query{SortBy: "memory", ExcludeNamespaces: []string{"kube-system"}}, want: "idle,mid,busy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(tt.query.Apply(pods())); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientThrottlingTracker(t *testing.T) {
+	client := &Client{throttling: make(map[string]*calculator.ThrottlingTracker)}
+	payment := client.throttlingTracker("payment", "")
+	payment.Since("payment/api/app", calculator.CPUStats{Periods: 100, ThrottledPeriods: 50})
+
+	// Another query's pods don't prune or reset the samples of payment
+	other := client.throttlingTracker("", "app=web")
+	other.Prune(func(string) bool { return false })
+	if other == payment || client.throttlingTracker("payment", "") != payment {
+		t.Fatalf("expected one tracker per namespace and selector")
+	}
+	if delta := payment.Since("payment/api/app", calculator.CPUStats{Periods: 150, ThrottledPeriods: 60}); delta.Periods != 50 || delta.ThrottledPeriods != 10 {
+		t.Errorf("expected the counters since payment's previous collection, got %+v", delta)
+	}
+}
@@ -0,0 +1,165 @@
+package usage
+
+import (
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Version is the version of the result types. Within a version fields are
+// only added, never renamed, retyped or removed, so callers can depend on
+// them while the calculator package changes underneath.
+const Version = "v1"
+
+// PodUsage is a pod's resource usage against its requests and limits
+type PodUsage struct {
+	Cluster   string `json:"cluster,omitempty"` // set with Options.Cluster
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Node      string `json:"node"`
+	Workload  string `json:"workload"` // owning workload as Kind/name, e.g. Deployment/api
+
+	// Resources holds cpu, memory and ephemeral-storage (its usage only with
+	// Options.Storage), plus the extended
+	// resources such as GPUs and hugepages the pod's containers set
+	Resources  map[corev1.ResourceName]ResourceUsage `json:"resources"`
+	Containers []ContainerUsage                      `json:"containers"`
+
+	Phase           corev1.PodPhase    `json:"phase"`
+	QOSClass        corev1.PodQOSClass `json:"qosClass"`
+	Restarts        int32              `json:"restarts"`                  // sum of the container restart counts
+	LastTermination *Termination       `json:"lastTermination,omitempty"` // most recent termination of any container
+	LastOOMKill     *Termination       `json:"lastOOMKill,omitempty"`     // most recent OOM kill of any container
+
+	Cost       *Cost       `json:"cost,omitempty"`       // set with Options.Pricing
+	Throttling *Throttling `json:"throttling,omitempty"` // set with Options.Throttling
+}
+
+// ContainerUsage is one container's resource usage
+type ContainerUsage struct {
+	Name      string                                `json:"name"`
+	Resources map[corev1.ResourceName]ResourceUsage `json:"resources"`
+	Restarts  int32                                 `json:"restarts"`
+}
+
+// ResourceUsage is the usage of one resource against its requests and limits
+type ResourceUsage struct {
+	Usage          *resource.Quantity `json:"usage,omitempty"` // nil if no source reported it
+	Requests       *resource.Quantity `json:"requests,omitempty"`
+	Limits         *resource.Quantity `json:"limits,omitempty"`
+	RequestPercent *float64           `json:"requestPercent,omitempty"` // Usage relative to Requests
+	LimitPercent   *float64           `json:"limitPercent,omitempty"`   // Usage relative to Limits
+
+	// RequestSource and LimitSource are explicit, limitrange (a LimitRange
+	// default applies) or none
+	RequestSource string `json:"requestSource"`
+	LimitSource   string `json:"limitSource"`
+
+	// DefaultRequests and DefaultLimits are the LimitRange defaults that apply;
+	// Requests and Limits hold them only with Options.AssumeLimitRangeDefaults
+	DefaultRequests *resource.Quantity `json:"defaultRequests,omitempty"`
+	DefaultLimits   *resource.Quantity `json:"defaultLimits,omitempty"`
+}
+
+// Termination is a container's last termination
+type Termination struct {
+	Container  string    `json:"container"`
+	Reason     string    `json:"reason"`
+	ExitCode   int32     `json:"exitCode"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// Cost is an estimated monthly cost in the pricing's currency
+type Cost struct {
+	Requests float64 `json:"requests"` // cost of the requested cpu and memory
+	Usage    float64 `json:"usage"`    // cost of the cpu and memory actually used
+	Idle     float64 `json:"idle"`     // cost of the requested cpu and memory left unused
+}
+
+// Throttling is a pod's CPU throttling and pressure since the Client's previous
+// collection, or since its containers started on the first one
+type Throttling struct {
+	Periods          int64    `json:"periods"`
+	ThrottledPeriods int64    `json:"throttledPeriods"`
+	ThrottledPercent *float64 `json:"throttledPercent,omitempty"` // nil without a CPU limit
+	PressurePercent  *float64 `json:"pressurePercent,omitempty"`  // nil without PSI
+}
+
+// toPodUsages converts calculator pod usages to the versioned result types
+func toPodUsages(podUsages []calculator.PodUsage) []PodUsage {
+	result := make([]PodUsage, 0, len(podUsages))
+	for _, pu := range podUsages {
+		result = append(result, toPodUsage(pu))
+	}
+	return result
+}
+
+// toPodUsage converts a calculator pod usage to the versioned result type
+func toPodUsage(pu calculator.PodUsage) PodUsage {
+	p := PodUsage{
+		Cluster:         pu.Cluster,
+		Namespace:       pu.Namespace,
+		Name:            pu.Name,
+		Node:            pu.Node,
+		Workload:        pu.Workload,
+		Resources:       toResourceUsages(pu.Resources),
+		Containers:      make([]ContainerUsage, 0, len(pu.Containers)),
+		Phase:           pu.Status.Phase,
+		QOSClass:        pu.Status.QOSClass,
+		Restarts:        pu.Status.Restarts,
+		LastTermination: toTermination(pu.Status.LastTermination),
+		LastOOMKill:     toTermination(pu.Status.LastOOMKill),
+	}
+	for _, c := range pu.Containers {
+		p.Containers = append(p.Containers, ContainerUsage{
+			Name:      c.Name,
+			Resources: toResourceUsages(c.Resources),
+			Restarts:  c.Restarts,
+		})
+	}
+	if pu.Cost != nil {
+		p.Cost = &Cost{Requests: pu.Cost.Requests, Usage: pu.Cost.Usage, Idle: pu.Cost.Idle}
+	}
+	if t := pu.Throttling; t != nil {
+		p.Throttling = &Throttling{
+			Periods:          t.Periods,
+			ThrottledPeriods: t.ThrottledPeriods,
+			ThrottledPercent: t.ThrottledPercent,
+			PressurePercent:  t.PressurePercent,
+		}
+	}
+	return p
+}
+
+// toResourceUsages converts calculator resource usages, leaving out the usage that wasn't reported
+func toResourceUsages(resources calculator.ResourceUsages) map[corev1.ResourceName]ResourceUsage {
+	result := make(map[corev1.ResourceName]ResourceUsage, len(resources))
+	for name, ru := range resources {
+		r := ResourceUsage{
+			Requests:        ru.Requests,
+			Limits:          ru.Limits,
+			RequestPercent:  ru.RequestPercent,
+			LimitPercent:    ru.LimitPercent,
+			RequestSource:   string(ru.RequestSource),
+			LimitSource:     string(ru.LimitSource),
+			DefaultRequests: ru.DefaultRequests,
+			DefaultLimits:   ru.DefaultLimits,
+		}
+		if !ru.UsageUnavailable {
+			usage := ru.Usage
+			r.Usage = &usage
+		}
+		result[name] = r
+	}
+	return result
+}
+
+// toTermination converts a calculator termination, nil if there is none
+func toTermination(t *calculator.Termination) *Termination {
+	if t == nil {
+		return nil
+	}
+	return &Termination{Container: t.Container, Reason: t.Reason, ExitCode: t.ExitCode, FinishedAt: t.FinishedAt}
+}
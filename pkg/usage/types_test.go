@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r1ckyIn/kubectl-resource-usage/pkg/calculator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestToPodUsage(t *testing.T) {
+	requests := resource.MustParse("200m")
+	percent := 50.0
+	oomKill := &calculator.Termination{Container: "app", Reason: calculator.ReasonOOMKilled, ExitCode: 137, FinishedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	pu := calculator.PodUsage{
+		Cluster:   "prod-eu",
+		Namespace: "payment",
+		Name:      "api-7d9f",
+		Node:      "node-1",
+		Workload:  "Deployment/api",
+		Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU: {Usage: resource.MustParse("100m"), Requests: &requests, RequestPercent: &percent,
+				RequestSource: calculator.SourceExplicit, LimitSource: calculator.SourceNone},
+			corev1.ResourceEphemeralStorage: {UsageUnavailable: true, RequestSource: calculator.SourceNone, LimitSource: calculator.SourceNone},
+		},
+		Containers: []calculator.ContainerUsage{{Name: "app", Restarts: 2, Resources: calculator.ResourceUsages{
+			corev1.ResourceCPU: {Usage: resource.MustParse("100m")},
+		}}},
+		Status: calculator.PodStatus{
+			Phase:           corev1.PodRunning,
+			QOSClass:        corev1.PodQOSBurstable,
+			Restarts:        2,
+			LastTermination: oomKill,
+			LastOOMKill:     oomKill,
+		},
+		Cost:       &calculator.Cost{Requests: 10, Usage: 5, Idle: 5},
+		Throttling: &calculator.Throttling{Periods: 100, ThrottledPeriods: 25, ThrottledPercent: floatPtr(25)},
+	}
+
+	p := toPodUsage(pu)
+	cpu := p.Resources[corev1.ResourceCPU]
+	if p.Cluster != "prod-eu" || p.Workload != "Deployment/api" || p.Phase != corev1.PodRunning || p.Restarts != 2 {
+		t.Errorf("unexpected pod fields: %+v", p)
+	}
+	if cpu.Usage == nil || cpu.Usage.MilliValue() != 100 || *cpu.RequestPercent != 50 || cpu.RequestSource != "explicit" || cpu.Limits != nil {
+		t.Errorf("unexpected cpu usage: %+v", cpu)
+	}
+	if storage := p.Resources[corev1.ResourceEphemeralStorage]; storage.Usage != nil {
+		t.Errorf("expected unavailable storage usage to be nil, got %v", storage.Usage)
+	}
+	if len(p.Containers) != 1 || p.Containers[0].Restarts != 2 || p.Containers[0].Resources[corev1.ResourceCPU].Usage == nil {
+		t.Errorf("unexpected containers: %+v", p.Containers)
+	}
+	if p.LastOOMKill == nil || p.LastOOMKill.ExitCode != 137 || p.Cost.Idle != 5 || *p.Throttling.ThrottledPercent != 25 {
+		t.Errorf("unexpected status, cost or throttling: %+v", p)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	for _, want := range []string{`"cluster":"prod-eu"`, `"workload":"Deployment/api"`, `"usage":"100m"`, `"requestPercent":50`, `"finishedAt":"2026-10-01T12:00:00Z"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected JSON to contain %s, got %s", want, data)
+		}
+	}
+
+	if empty := toPodUsage(calculator.PodUsage{}); empty.Cost != nil || empty.Throttling != nil || empty.LastTermination != nil || empty.Containers == nil {
+		t.Errorf("expected optional fields to stay nil and containers empty, got %+v", empty)
+	}
+}
+
+// floatPtr returns a pointer to a float64
+func floatPtr(f float64) *float64 {
+	return &f
+}